@@ -39,6 +39,11 @@ func (*Daemon) GetDependencies() []string {
 	return []string{}
 }
 
+// RunOnMainThread returns true, the cursor listener calls into gtk.
+func (*Daemon) RunOnMainThread() bool {
+	return true
+}
+
 func (*Daemon) Start() error {
 	if _m != nil {
 		return nil
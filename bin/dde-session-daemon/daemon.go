@@ -29,18 +29,30 @@ const (
 	ProfTypeMem = "memory"
 )
 
-func runMainLoop() {
+func runMainLoop(app *SessionDaemon) {
 	session.Register()
 	dbus.DealWithUnhandledMessage()
-	listenDaemonSettings()
+	listenDaemonSettings(app)
+	app.startWatchdog()
 	go glib.StartLoop()
 
-	if err := dbus.Wait(); err != nil {
-		logger.Errorf("Lost dbus: %v", err)
-		os.Exit(-1)
+	dbusLost := make(chan error, 1)
+	go func() {
+		dbusLost <- dbus.Wait()
+	}()
+
+	select {
+	case err := <-dbusLost:
+		app.stopModules()
+		if err != nil {
+			logger.Errorf("Lost dbus: %v", err)
+			os.Exit(-1)
+		}
+		logger.Info("dbus connection is closed by user")
+	case <-app.quit:
+		app.stopModules()
+		logger.Info("shutdown by request")
 	}
-
-	logger.Info("dbus connection is closed by user")
 	os.Exit(0)
 }
 
@@ -62,11 +74,20 @@ type SessionDaemon struct {
 	flags           *Flags
 	log             *log.Logger
 	settings        *gio.Settings
+	profile         *Profile
 	enabledModules  map[string]loader.Module
 	disabledModules map[string]loader.Module
 
 	cpuLocker sync.Mutex
 	cpuWriter *os.File
+
+	quit     chan struct{}
+	quitOnce sync.Once
+
+	// Signals
+	ModuleStateChanged func(name string, enabled bool)
+	ModuleFailed       func(name string, reason string)
+	ModuleRestarted    func(name string, reason string, success bool)
 }
 
 func (*SessionDaemon) GetDBusInfo() dbus.DBusInfo {
@@ -77,18 +98,61 @@ func (*SessionDaemon) GetDBusInfo() dbus.DBusInfo {
 	}
 }
 
-func NewSessionDaemon(flags *Flags, settings *gio.Settings, logger *log.Logger) *SessionDaemon {
+func NewSessionDaemon(flags *Flags, settings *gio.Settings, logger *log.Logger) (*SessionDaemon, error) {
 	session := &SessionDaemon{
 		flags:           flags,
 		settings:        settings,
 		log:             logger,
 		enabledModules:  map[string]loader.Module{},
 		disabledModules: map[string]loader.Module{},
+		quit:            make(chan struct{}),
+	}
+
+	if name := getProfileName(flags); name != "" {
+		profile, err := loadProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Use profile:", name)
+		session.profile = profile
 	}
 
 	session.initModules()
+	loader.SetRetryPolicy(loader.RetryPolicy{
+		MaxRetries:  *flags.RetryTimes,
+		Interval:    *flags.RetryInterval,
+		MaxInterval: loader.DefaultRetryPolicy.MaxInterval,
+	})
+	loader.ConnectModuleFailed(func(name string, failure loader.ModuleFailure) {
+		dbus.Emit(session, "ModuleFailed", name, failure.Reason)
+	})
+
+	return session, nil
+}
+
+func (s *SessionDaemon) startWatchdog() {
+	interval := *s.flags.WatchdogInterval
+	if interval <= 0 {
+		return
+	}
 
-	return session
+	loader.ConnectModuleRestarted(func(name string, reason string, err error) {
+		dbus.Emit(s, "ModuleRestarted", name, reason, err == nil)
+	})
+	config := loader.DefaultWatchdogConfig
+	config.Interval = interval
+	loader.StartWatchdog(config)
+}
+
+// stopModules stops the modules in reverse dependency order before exiting.
+func (s *SessionDaemon) stopModules() {
+	timedOut := loader.Shutdown(*s.flags.StopTimeout)
+	if len(timedOut) > 0 {
+		s.log.Warningf("modules %v did not stop in %s", timedOut, *s.flags.StopTimeout)
+	}
+	if s.profile != nil {
+		s.profile.restoreSettings()
+	}
 }
 
 func (s *SessionDaemon) exitIfNotSingleton() error {
@@ -111,6 +175,15 @@ func (s *SessionDaemon) register() error {
 }
 
 func (s *SessionDaemon) defaultAction() {
+	if s.profile != nil {
+		err := s.applyProfile()
+		if err != nil {
+			fmt.Println(err)
+			s.profile.restoreSettings()
+			os.Exit(3)
+		}
+	}
+
 	err := loader.EnableModules(s.getEnabledModules(), s.getDisabledModules(), getEnableFlag(s.flags))
 	if err != nil {
 		fmt.Println(err)
@@ -123,11 +196,48 @@ func (s *SessionDaemon) execDefaultAction() {
 	s.defaultAction()
 }
 
+// applyProfile validates the modules of the profile against the dependencies,
+// then applies its settings.
+func (s *SessionDaemon) applyProfile() error {
+	for _, name := range s.profile.EnabledModules {
+		if loader.GetModule(name) == nil {
+			return fmt.Errorf("profile %s: no such a module named %s", s.profile.Name, name)
+		}
+	}
+
+	err := loader.CheckModules(s.getEnabledModules(), s.getDisabledModules(), getEnableFlag(s.flags))
+	if err != nil {
+		return fmt.Errorf("profile %s: %v", s.profile.Name, err)
+	}
+
+	return s.profile.applySettings()
+}
+
+// modulesEnabledByDefault are the modules without a settings key in the
+// schema, they are enabled unless a profile disables them.
+var modulesEnabledByDefault = []string{"dock"}
+
+// isModuleEnabled checks the profile or the settings, see
+// modulesEnabledByDefault for the modules without a settings key.
+func (s *SessionDaemon) isModuleEnabled(name string) bool {
+	if s.profile != nil {
+		return s.profile.isModuleEnabled(name)
+	}
+	if !isStrInList(name, s.settings.ListKeys()) {
+		if isStrInList(name, modulesEnabledByDefault) {
+			return true
+		}
+		s.log.Warningf("module %s has no settings key, disable it", name)
+		return false
+	}
+	return s.settings.GetBoolean(name)
+}
+
 func (s *SessionDaemon) initModules() {
 	allModules := loader.List()
 	for _, module := range allModules {
 		name := module.Name()
-		if s.settings.GetBoolean(name) {
+		if s.isModuleEnabled(name) {
 			s.enabledModules[name] = module
 		} else {
 			s.disabledModules[name] = module
@@ -183,6 +293,22 @@ func (s *SessionDaemon) ListModule(name string) error {
 	return nil
 }
 
+// PrintGraph prints the dependency graph, the enabled state of the modules
+// comes from the settings or the profile.
+func (s *SessionDaemon) PrintGraph(format string) error {
+	g := loader.GetGraph()
+	for _, node := range g.Nodes {
+		_, node.Enabled = s.enabledModules[node.Name]
+	}
+
+	content, err := formatGraph(g, format)
+	if err != nil {
+		return err
+	}
+	fmt.Println(content)
+	return nil
+}
+
 func filterList(origin, condition []string) []string {
 	if len(condition) == 0 {
 		return origin
@@ -0,0 +1,62 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"pkg.deepin.io/lib/dbus"
+)
+
+const (
+	dockDBusDest    = "com.deepin.dde.daemon.Dock"
+	dockDBusObjPath = "/com/deepin/dde/daemon/Dock"
+)
+
+// exportDockLayout writes the layout of the running dock to the file, or
+// stdout if file is empty.
+func exportDockLayout(file string) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+	var layout string
+	err = conn.Object(dockDBusDest, dockDBusObjPath).Call(dockDBusDest+".ExportLayout", 0).Store(&layout)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		fmt.Println(layout)
+		return nil
+	}
+	return ioutil.WriteFile(file, []byte(layout+"\n"), 0644)
+}
+
+func importDockLayout(file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+	return conn.Object(dockDBusDest, dockDBusObjPath).Call(dockDBusDest+".ImportLayout", 0, string(content)).Store()
+}
+
+func runDockLayoutCommand(fn func(file string) error, file string) {
+	err := fn(file)
+	if err != nil {
+		logger.Warning(err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -9,7 +9,18 @@
 
 package main
 
+import (
+	"time"
+)
+
 type Flags struct {
 	IgnoreMissingModules *bool
 	ForceStart           *bool
+	RetryTimes           *int
+	RetryInterval        *time.Duration
+	WatchdogInterval     *time.Duration
+	TraceFile            *string
+	TraceFormat          *string
+	Profile              *string
+	StopTimeout          *time.Duration
 }
@@ -0,0 +1,160 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"pkg.deepin.io/dde/daemon/loader"
+	"pkg.deepin.io/lib/dbus"
+)
+
+const (
+	graphFormatDOT  = "dot"
+	graphFormatJSON = "json"
+)
+
+type moduleInfo struct {
+	Name          string
+	Enabled       bool
+	Dependencies  []string
+	LogLevel      int32
+	StartDuration int64 // in milliseconds
+	Failed        bool
+	FailedReason  string
+}
+
+func newModuleInfo(module loader.Module) *moduleInfo {
+	deps := module.GetDependencies()
+	if deps == nil {
+		deps = []string{}
+	}
+	failure, failed := loader.GetFailure(module.Name())
+	return &moduleInfo{
+		Name:          module.Name(),
+		Enabled:       module.IsEnable(),
+		Dependencies:  deps,
+		LogLevel:      int32(module.LogLevel()),
+		StartDuration: int64(loader.GetStartDuration(module.Name()) / time.Millisecond),
+		Failed:        failed,
+		FailedReason:  failure.Reason,
+	}
+}
+
+func marshalJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (s *SessionDaemon) emitModuleStateChanged(name string, enabled bool) {
+	dbus.Emit(s, "ModuleStateChanged", name, enabled)
+}
+
+// ListModules returns the JSON encoded information of all registered modules.
+func (s *SessionDaemon) ListModules() (string, error) {
+	var infos []*moduleInfo
+	for _, module := range loader.List() {
+		infos = append(infos, newModuleInfo(module))
+	}
+	return marshalJSON(infos)
+}
+
+// GetModuleInfo returns the JSON encoded information of the module.
+func (s *SessionDaemon) GetModuleInfo(name string) (string, error) {
+	module := loader.GetModule(name)
+	if module == nil {
+		return "", fmt.Errorf("no such a module named %s", name)
+	}
+	return marshalJSON(newModuleInfo(module))
+}
+
+// SetModuleEnabled starts or stops the module at runtime without touching settings.
+// Starting a module starts its dependencies first, stopping a module stops
+// the modules depending on it first.
+func (s *SessionDaemon) SetModuleEnabled(name string, enabled bool) error {
+	moduleLocker.Lock()
+	defer moduleLocker.Unlock()
+
+	if loader.GetModule(name) == nil {
+		return fmt.Errorf("no such a module named %s", name)
+	}
+
+	if !enabled {
+		stopped, err := loader.DisableModules([]string{name})
+		for _, n := range stopped {
+			s.emitModuleStateChanged(n, false)
+		}
+		return err
+	}
+
+	var before = map[string]bool{}
+	for _, module := range loader.List() {
+		before[module.Name()] = module.IsEnable()
+	}
+
+	disabledModules := filterList(s.getDisabledModules(), []string{name})
+	err := loader.EnableModules([]string{name}, disabledModules, getEnableFlag(s.flags))
+	for _, module := range loader.List() {
+		if module.IsEnable() && !before[module.Name()] {
+			s.emitModuleStateChanged(module.Name(), true)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if failure, failed := loader.GetFailure(name); failed {
+		return fmt.Errorf("enable module %s failed: %s", name, failure.Reason)
+	}
+	return nil
+}
+
+// GetStartupTrace returns the module start and stop trace, format is json or
+// chrome, the latter can be loaded by chrome://tracing.
+func (s *SessionDaemon) GetStartupTrace(format string) (string, error) {
+	data, err := loader.ExportTrace(format)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func formatGraph(g *loader.ModuleGraph, format string) (string, error) {
+	switch format {
+	case graphFormatDOT:
+		return g.DOT(), nil
+	case graphFormatJSON:
+		data, err := g.JSON()
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q", format)
+}
+
+// GetModuleGraph returns the dependency graph of all the modules with their
+// running state, format is dot or json.
+func (s *SessionDaemon) GetModuleGraph(format string) (string, error) {
+	return formatGraph(loader.GetGraph(), format)
+}
+
+// Shutdown stops all the modules in reverse dependency order and exits the
+// daemon, it returns before the modules are stopped.
+func (s *SessionDaemon) Shutdown() {
+	s.quitOnce.Do(func() {
+		close(s.quit)
+	})
+}
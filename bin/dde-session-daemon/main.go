@@ -43,19 +43,42 @@ func main() {
 	flags := new(Flags)
 	flags.IgnoreMissingModules = cmd.Flag("Ignore", "ignore missing modules, --no-ignore to revert it.").Short('i').Default("true").Bool()
 	flags.ForceStart = cmd.Flag("force", "Force start disabled module.").Short('f').Bool()
+	flags.RetryTimes = cmd.Flag("retry", "Retry times of the module failed to start.").Default("3").Int()
+	flags.RetryInterval = cmd.Flag("retry-interval", "Interval before the first retry, doubled after each retry.").Default("2s").Duration()
+	flags.WatchdogInterval = cmd.Flag("watchdog-interval", "Interval of module health checks, 0 to disable the watchdog.").Default("30s").Duration()
+	flags.StopTimeout = cmd.Flag("stop-timeout", "Time each module has to stop on exit.").Default("3s").Duration()
+	flags.Profile = cmd.Flag("profile", "Use the named profile instead of settings to choose modules, overrides $"+envProfile+".").Short('p').String()
+	flags.TraceFile = cmd.Flag("trace", "Write the module startup trace to the file.").String()
+	flags.TraceFormat = cmd.Flag("trace-format", "Format of the startup trace, json or chrome.").Default(loader.TraceFormatJSON).Enum(loader.TraceFormatJSON, loader.TraceFormatChrome)
 
 	cmd.Command("auto", "Automatically get enabled and disabled modules from settings.").Default()
 	enablingModules := cmd.Command("enable", "Enable modules and their dependencies, ignore settings.").Arg("module", "module names.").Required().Strings()
 	disableModules := cmd.Command("disable", "Disable modules, ignore settings.").Arg("module", "module names.").Required().Strings()
 	listModule := cmd.Command("list", "List all the modules or the dependencies of one module.").Arg("module", "module name.").String()
+	graphFormat := cmd.Command("graph", "Print the dependency graph of all the modules.").Arg("format", "dot or json.").Default(graphFormatDOT).Enum(graphFormatDOT, graphFormatJSON)
+	exportDockLayoutFile := cmd.Command("export-dock-layout", "Export the layout of the running dock.").Arg("file", "output file, stdout if omitted.").String()
+	importDockLayoutFile := cmd.Command("import-dock-layout", "Import the layout file to the running dock.").Arg("file", "layout file.").Required().String()
 
 	subCmd := cmd.ParseCommandLine(os.Args[1:])
+
+	// these commands talk to the running daemon
+	switch subCmd {
+	case "export-dock-layout":
+		runDockLayoutCommand(exportDockLayout, *exportDockLayoutFile)
+	case "import-dock-layout":
+		runDockLayoutCommand(importDockLayout, *importDockLayoutFile)
+	}
+
 	cmd.StartProfile()
 
 	C.init()
 	proxy.SetupProxy()
 
-	app := NewSessionDaemon(flags, daemonSettings, logger)
+	app, err := NewSessionDaemon(flags, daemonSettings, logger)
+	if err != nil {
+		logger.Warning(err)
+		os.Exit(1)
+	}
 	if err = app.register(); err != nil {
 		logger.Info(err)
 		os.Exit(0)
@@ -88,6 +111,9 @@ func main() {
 	case "list":
 		err = app.ListModule(*listModule)
 		needRunMainLoop = false
+	case "graph":
+		err = app.PrintGraph(*graphFormat)
+		needRunMainLoop = false
 	}
 
 	if err != nil {
@@ -95,7 +121,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *flags.TraceFile != "" && needRunMainLoop {
+		err = loader.WriteTrace(*flags.TraceFile, *flags.TraceFormat)
+		if err != nil {
+			logger.Warning("Write startup trace failed:", err)
+		}
+	}
+
 	if needRunMainLoop {
-		runMainLoop()
+		runMainLoop(app)
 	}
 }
@@ -17,6 +17,7 @@ import (
 	_ "pkg.deepin.io/dde/daemon/bluetooth"
 	_ "pkg.deepin.io/dde/daemon/clipboard"
 	_ "pkg.deepin.io/dde/daemon/debug"
+	_ "pkg.deepin.io/dde/daemon/dock"
 	_ "pkg.deepin.io/dde/daemon/gesture"
 	_ "pkg.deepin.io/dde/daemon/inputdevices"
 	_ "pkg.deepin.io/dde/daemon/keybinding"
@@ -38,7 +39,7 @@ var (
 	daemonSettings = gio.NewSettings("com.deepin.dde.daemon")
 )
 
-func listenDaemonSettings() {
+func listenDaemonSettings(app *SessionDaemon) {
 	daemonSettings.Connect("changed", func(s *gio.Settings, name string) {
 		// gsettings key names must keep consistent with module names
 		moduleLocker.Lock()
@@ -61,6 +62,7 @@ func listenDaemonSettings() {
 			logger.Warningf("Enable '%s' failed: %v", name, err)
 			return
 		}
+		app.emitModuleStateChanged(name, enable)
 	})
 	daemonSettings.GetBoolean("mounts")
 }
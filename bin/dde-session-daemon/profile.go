@@ -0,0 +1,234 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+
+	"gir/gio-2.0"
+	"gir/glib-2.0"
+	dutils "pkg.deepin.io/lib/utils"
+	"pkg.deepin.io/lib/xdg/basedir"
+)
+
+const envProfile = "DDE_DAEMON_PROFILE"
+
+// Profile decides the enabled modules and overrides some settings, the
+// modules not listed are disabled. The overridden settings are restored
+// when the daemon exits, so the profile doesn't change the user settings.
+//
+// A profile named foo is searched as
+// $XDG_CONFIG_HOME/deepin/dde-daemon/profiles/foo.json and then
+// /usr/share/dde-daemon/profiles/foo.json.
+type Profile struct {
+	Name           string `json:"-"`
+	EnabledModules []string
+	// Settings maps gsettings schema id to key/value pairs, the value
+	// could be boolean, number, string or string array. Enum keys take
+	// the nick as a string.
+	Settings map[string]map[string]interface{}
+
+	gsettings []*gio.Settings
+	backups   []settingsBackup
+}
+
+// settingsBackup is the user value of a key before the profile overrides
+// it, value is nil if the user didn't set the key.
+type settingsBackup struct {
+	settings *gio.Settings
+	key      string
+	value    *glib.Variant
+}
+
+func getProfileDirs() []string {
+	return []string{
+		filepath.Join(basedir.GetUserConfigDir(), "deepin/dde-daemon/profiles"),
+		"/usr/share/dde-daemon/profiles",
+	}
+}
+
+// getProfileName returns the profile chosen by command line, or by the
+// environment variable.
+func getProfileName(flags *Flags) string {
+	if *flags.Profile != "" {
+		return *flags.Profile
+	}
+	return os.Getenv(envProfile)
+}
+
+func loadProfile(name string) (*Profile, error) {
+	for _, dir := range getProfileDirs() {
+		filename := filepath.Join(dir, name+".json")
+		if !dutils.IsFileExist(filename) {
+			continue
+		}
+
+		profile, err := loadProfileFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		profile.Name = name
+		return profile, nil
+	}
+	return nil, fmt.Errorf("no such a profile named %s", name)
+}
+
+func loadProfileFile(filename string) (*Profile, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile Profile
+	err = json.Unmarshal(data, &profile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %v", filename, err)
+	}
+	return &profile, nil
+}
+
+func (p *Profile) isModuleEnabled(name string) bool {
+	return isStrInList(name, p.EnabledModules)
+}
+
+// applySettings overrides the settings, the previous values are kept to be
+// restored by restoreSettings.
+func (p *Profile) applySettings() error {
+	for schema, values := range p.Settings {
+		s, err := dutils.CheckAndNewGSettings(schema)
+		if err != nil {
+			return err
+		}
+		p.gsettings = append(p.gsettings, s)
+
+		for key, value := range values {
+			backup := settingsBackup{
+				settings: s,
+				key:      key,
+				value:    s.GetUserValue(key),
+			}
+			err = setSettingsValue(s, key, value)
+			if err != nil {
+				if backup.value != nil {
+					backup.value.Unref()
+				}
+				return fmt.Errorf("set %s.%s failed: %v", schema, key, err)
+			}
+			p.backups = append(p.backups, backup)
+		}
+	}
+	return nil
+}
+
+// restoreSettings restores the settings overridden by applySettings, the
+// keys not set by the user before are reset to the default values.
+func (p *Profile) restoreSettings() {
+	for i := len(p.backups) - 1; i >= 0; i-- {
+		backup := p.backups[i]
+		if backup.value == nil {
+			backup.settings.Reset(backup.key)
+			continue
+		}
+		backup.settings.SetValue(backup.key, backup.value)
+		backup.value.Unref()
+	}
+	p.backups = nil
+
+	// the daemon exits soon, make sure the values are written
+	gio.SettingsSync()
+	for _, s := range p.gsettings {
+		s.Unref()
+	}
+	p.gsettings = nil
+}
+
+func setSettingsValue(s *gio.Settings, key string, value interface{}) error {
+	current := s.GetValue(key)
+	typ := current.GetTypeString()
+	current.Unref()
+
+	converted, err := convertSettingsValue(typ, value)
+	if err != nil {
+		return err
+	}
+
+	var ok bool
+	switch v := converted.(type) {
+	case bool:
+		ok = s.SetBoolean(key, v)
+	case int32:
+		ok = s.SetInt(key, v)
+	case uint32:
+		ok = s.SetUint(key, v)
+	case float64:
+		ok = s.SetDouble(key, v)
+	case string:
+		ok = s.SetString(key, v)
+	case []string:
+		ok = s.SetStrv(key, v)
+	}
+
+	if !ok {
+		return fmt.Errorf("settings refused value %v", value)
+	}
+	return nil
+}
+
+// convertSettingsValue converts the JSON value to the Go type of the
+// setter matching the GVariant type of the key, gsettings aborts if the
+// type mismatches.
+func convertSettingsValue(typ string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case bool:
+		if typ == "b" {
+			return v, nil
+		}
+	case float64:
+		switch typ {
+		case "i":
+			if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+				return int32(v), nil
+			}
+			return nil, fmt.Errorf("value %v is not a valid int32", value)
+		case "u":
+			if v == math.Trunc(v) && v >= 0 && v <= math.MaxUint32 {
+				return uint32(v), nil
+			}
+			return nil, fmt.Errorf("value %v is not a valid uint32", value)
+		case "d":
+			return v, nil
+		}
+	case string:
+		// enum keys are strings too
+		if typ == "s" {
+			return v, nil
+		}
+	case []interface{}:
+		if typ == "as" {
+			strv := []string{}
+			for _, item := range v {
+				str, isStr := item.(string)
+				if !isStr {
+					return nil, fmt.Errorf("unsupported value %v", value)
+				}
+				strv = append(strv, str)
+			}
+			return strv, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported value %v", value)
+	}
+	return nil, fmt.Errorf("value %v mismatches the type %q of the key", value, typ)
+}
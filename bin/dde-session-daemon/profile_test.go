@@ -0,0 +1,62 @@
+package main
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestLoadProfileFile(t *testing.T) {
+	Convey("Test loadProfileFile", t, func() {
+		profile, err := loadProfileFile("testdata/profile.json")
+		So(err, ShouldBeNil)
+		So(profile.EnabledModules, ShouldResemble, []string{"audio", "inputdevices", "keybinding"})
+		So(profile.isModuleEnabled("keybinding"), ShouldBeTrue)
+		So(profile.isModuleEnabled("network"), ShouldBeFalse)
+		So(profile.Settings["com.deepin.dde.power"]["lid-closed-suspend"], ShouldEqual, true)
+
+		_, err = loadProfileFile("testdata/profile_invalid.json")
+		So(err, ShouldNotBeNil)
+		_, err = loadProfileFile("testdata/no_such_profile.json")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestConvertSettingsValue(t *testing.T) {
+	var infos = []struct {
+		typ       string
+		value     interface{}
+		converted interface{}
+		ok        bool
+	}{
+		{"b", true, true, true},
+		{"i", float64(-3), int32(-3), true},
+		{"i", float64(1.5), nil, false},
+		{"i", float64(1 << 40), nil, false},
+		{"u", float64(30), uint32(30), true},
+		{"u", float64(-1), nil, false},
+		{"d", float64(0.5), float64(0.5), true},
+		{"s", "smart-hide", "smart-hide", true},
+		{"as", []interface{}{"a", "b"}, []string{"a", "b"}, true},
+		{"as", []interface{}{}, []string{}, true},
+		{"as", []interface{}{"a", float64(1)}, nil, false},
+		// type mismatches
+		{"i", true, nil, false},
+		{"b", float64(1), nil, false},
+		{"s", float64(1), nil, false},
+		{"u", "1", nil, false},
+		{"s", []interface{}{"a"}, nil, false},
+		{"a{ss}", map[string]interface{}{}, nil, false},
+	}
+
+	Convey("Test convertSettingsValue", t, func() {
+		for _, info := range infos {
+			converted, err := convertSettingsValue(info.typ, info.value)
+			if info.ok {
+				So(err, ShouldBeNil)
+				So(converted, ShouldResemble, info.converted)
+			} else {
+				So(err, ShouldNotBeNil)
+			}
+		}
+	})
+}
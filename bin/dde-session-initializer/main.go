@@ -17,7 +17,6 @@ import "C"
 import (
 	"gir/glib-2.0"
 	"pkg.deepin.io/dde/api/session"
-	_ "pkg.deepin.io/dde/daemon/dock"
 	_ "pkg.deepin.io/dde/daemon/launcher"
 	"pkg.deepin.io/dde/daemon/loader"
 	_ "pkg.deepin.io/dde/daemon/trayicon"
@@ -104,7 +103,7 @@ func main() {
 		loader.SetLogLevel(appLogLevel)
 	}
 
-	loader.EnableModules([]string{"dock", "launcher", "trayicon"}, nil, loader.EnableFlagIgnoreMissingModule)
+	loader.EnableModules([]string{"launcher", "trayicon"}, nil, loader.EnableFlagIgnoreMissingModule)
 
 	runMainLoop()
 }
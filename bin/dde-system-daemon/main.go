@@ -14,6 +14,7 @@ import "pkg.deepin.io/lib/log"
 import "pkg.deepin.io/lib"
 import "pkg.deepin.io/lib/dbus"
 import "os"
+import "flag"
 import _ "pkg.deepin.io/dde/daemon/accounts"
 import _ "pkg.deepin.io/dde/daemon/system/power"
 import _ "pkg.deepin.io/dde/daemon/system/gesture"
@@ -24,7 +25,13 @@ import "gir/glib-2.0"
 
 var logger = log.NewLogger("daemon/dde-system-daemon")
 
+var (
+	traceFile   = flag.String("trace", "", "write the module startup trace to the file")
+	traceFormat = flag.String("trace-format", loader.TraceFormatJSON, "format of the startup trace, json or chrome")
+)
+
 func main() {
+	flag.Parse()
 	logger.BeginTracing()
 	defer logger.EndTracing()
 
@@ -39,13 +46,21 @@ func main() {
 	logger.SetRestartCommand("/usr/lib/deepin-daemon/dde-system-daemon")
 
 	loader.StartAll()
-	defer loader.StopAll()
+
+	if *traceFile != "" {
+		if err := loader.WriteTrace(*traceFile, *traceFormat); err != nil {
+			logger.Warning("Write startup trace failed:", err)
+		}
+	}
 
 	dbus.DealWithUnhandledMessage()
 	// NOTE: system/power module requires glib loop
 	go glib.StartLoop()
 
-	if err := dbus.Wait(); err != nil {
+	err := dbus.Wait()
+	// os.Exit skips the deferred functions, stop modules here.
+	loader.StopAll()
+	if err != nil {
 		logger.Errorf("Lost dbus: %v", err)
 		os.Exit(-1)
 	} else {
@@ -17,13 +17,23 @@ import (
 
 type AppEntries []*AppEntry
 
+// GetFirstByInnerId returns the leader of the entries with the inner id,
+// they are split by the group policy. The docked one leads the group,
+// otherwise the first one.
 func (entries AppEntries) GetFirstByInnerId(id string) *AppEntry {
+	var first *AppEntry
 	for _, entry := range entries {
-		if entry.innerId == id {
+		if entry.innerId != id {
+			continue
+		}
+		if entry.IsDocked {
 			return entry
 		}
+		if first == nil {
+			first = entry
+		}
 	}
-	return nil
+	return first
 }
 
 func (entries AppEntries) Insert(entry *AppEntry, index int) AppEntries {
@@ -83,6 +93,25 @@ func (entries AppEntries) FilterDocked() AppEntries {
 	return dockedEntries
 }
 
+// sortDockedByInnerIds puts the docked entries first in the order of
+// innerIds, the other entries keep their order.
+func (entries AppEntries) sortDockedByInnerIds(innerIds []string) AppEntries {
+	result := make(AppEntries, 0, len(entries))
+	for _, innerId := range innerIds {
+		for _, entry := range entries {
+			if entry.IsDocked && entry.innerId == innerId {
+				result = append(result, entry)
+			}
+		}
+	}
+	for _, entry := range entries {
+		if !entry.IsDocked || !strSliceContains(innerIds, entry.innerId) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
 func (entries AppEntries) GetByWindowPid(pid uint) *AppEntry {
 	for _, entry := range entries {
 		for _, winInfo := range entry.windows {
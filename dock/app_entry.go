@@ -54,11 +54,22 @@ type AppEntry struct {
 	CurrentWindow xproto.Window
 	windowMutex   sync.Mutex
 
+	// cycleWindows is the order used by Activate to cycle through the
+	// windows, it is kept while cycleLeader is still the current window.
+	cycleWindows windowSlice
+	cycleLeader  xproto.Window
+
 	coreMenu         *Menu
 	appInfo          *AppInfo
 	IsDocked         bool
 	dockMutex        sync.Mutex
 	winIconPreferred bool
+
+	// from the Unity LauncherEntry protocol, Progress is -1 when the
+	// progress is not shown.
+	Count    int64
+	Progress float64
+	Urgent   bool
 }
 
 func newAppEntry(dockManager *DockManager, id string, appInfo *AppInfo) *AppEntry {
@@ -68,6 +79,7 @@ func newAppEntry(dockManager *DockManager, id string, appInfo *AppInfo) *AppEntr
 		innerId:      id,
 		WindowTitles: newWindowTitles(),
 		windows:      make(map[xproto.Window]*WindowInfo),
+		Progress:     progressInvisible,
 	}
 	entry.setAppInfo(appInfo)
 	return entry
@@ -153,40 +165,103 @@ func (entry *AppEntry) setCurrentWindowInfo(winInfo *WindowInfo) {
 	}
 }
 
+// getWindowsMRU returns the windows of the entry, the most recently used
+// first.
+func (entry *AppEntry) getWindowsMRU() []*WindowInfo {
+	windows := make([]*WindowInfo, 0, len(entry.windows))
+	for _, winInfo := range entry.windows {
+		windows = append(windows, winInfo)
+	}
+	sort.Sort(windowInfosMRU(windows))
+	return windows
+}
+
+func (entry *AppEntry) isWindowVisible(winInfo *WindowInfo) bool {
+	return entry.dockManager == nil || entry.dockManager.isWindowVisibleByFilter(winInfo)
+}
+
+// getVisibleWindowsMRU returns the windows passing the window filter of the
+// dock manager, the most recently used first.
+func (entry *AppEntry) getVisibleWindowsMRU() []*WindowInfo {
+	var windows []*WindowInfo
+	for _, winInfo := range entry.getWindowsMRU() {
+		if entry.isWindowVisible(winInfo) {
+			windows = append(windows, winInfo)
+		}
+	}
+	return windows
+}
+
+// updateCurrentWindowByFilter selects the most recently used visible window
+// when the current one is filtered out. The current window is kept if no
+// window is visible.
+func (entry *AppEntry) updateCurrentWindowByFilter() {
+	if entry.current != nil && entry.isWindowVisible(entry.current) {
+		return
+	}
+	if windows := entry.getVisibleWindowsMRU(); len(windows) > 0 {
+		entry.setCurrentWindowInfo(windows[0])
+		entry.updateIcon()
+	}
+}
+
+// updateWindowsByFilter is called when the window filter or the workspace or
+// geometry of a window changed.
+func (entry *AppEntry) updateWindowsByFilter() {
+	entry.updateWindowTitles()
+	entry.updateCurrentWindowByFilter()
+}
+
 func (entry *AppEntry) findNextLeader() xproto.Window {
-	winSlice := make(windowSlice, 0, len(entry.windows))
-	for win, _ := range entry.windows {
-		winSlice = append(winSlice, win)
+	if entry.current == nil {
+		logger.Warning("findNextLeader unexpect, return 0")
+		return 0
 	}
-	sort.Sort(winSlice)
 	currentWin := entry.current.window
-	logger.Debug("sorted window slice:", winSlice)
+
+	if entry.cycleLeader != currentWin || !entry.cycleWindows.Contains(currentWin) {
+		windows := entry.getVisibleWindowsMRU()
+		entry.cycleWindows = make(windowSlice, len(windows))
+		for i, winInfo := range windows {
+			entry.cycleWindows[i] = winInfo.window
+		}
+	}
+
+	// drop the windows detached or filtered out since the cycle started
+	cycle := make(windowSlice, 0, len(entry.cycleWindows))
+	for _, win := range entry.cycleWindows {
+		if winInfo, ok := entry.windows[win]; ok && entry.isWindowVisible(winInfo) {
+			cycle = append(cycle, win)
+		}
+	}
+	entry.cycleWindows = cycle
+	logger.Debug("cycle windows:", cycle)
 	logger.Debug("current window:", currentWin)
+
 	currentIndex := -1
-	for i, win := range winSlice {
+	for i, win := range cycle {
 		if win == currentWin {
 			currentIndex = i
 		}
 	}
-	if currentIndex == -1 {
+	if len(cycle) == 0 {
 		logger.Warning("findNextLeader unexpect, return 0")
 		return 0
 	}
-	// if current window is max, return min: winSlice[0]
-	// else return winSlice[currentIndex+1]
-	nextIndex := 0
-	if currentIndex < len(winSlice)-1 {
-		nextIndex = currentIndex + 1
-	}
-	logger.Debug("next window:", winSlice[nextIndex])
-	return winSlice[nextIndex]
+	// the current window is filtered out, start from the first one
+	nextWin := cycle[(currentIndex+1)%len(cycle)]
+	entry.cycleLeader = nextWin
+	logger.Debug("next window:", nextWin)
+	return nextWin
 }
 
 func (entry *AppEntry) attachWindow(winInfo *WindowInfo) {
 	win := winInfo.window
 	logger.Debugf("attach win %v to entry", win)
 
+	winInfo.mutex.Lock()
 	winInfo.entry = entry
+	winInfo.mutex.Unlock()
 	if _, ok := entry.windows[win]; ok {
 		logger.Debugf("win %v is already attach to entry", win)
 		return
@@ -197,7 +272,8 @@ func (entry *AppEntry) attachWindow(winInfo *WindowInfo) {
 	entry.updateIsActive()
 
 	if (entry.dockManager != nil && win == entry.dockManager.activeWindow) ||
-		entry.current == nil {
+		entry.current == nil ||
+		(!entry.isWindowVisible(entry.current) && entry.isWindowVisible(winInfo)) {
 		entry.setCurrentWindowInfo(winInfo)
 		entry.updateIcon()
 		winInfo.updateWmName()
@@ -211,12 +287,16 @@ func (entry *AppEntry) detachWindow(winInfo *WindowInfo) bool {
 	if _, ok := entry.windows[win]; ok {
 		delete(entry.windows, win)
 		if len(entry.windows) == 0 {
+			entry.setCurrentWindowInfo(nil)
 			return true
 		}
-		for _, winInfo := range entry.windows {
-			// select first
-			entry.setCurrentWindowInfo(winInfo)
-			break
+		if entry.current == winInfo {
+			// select the most recently used, visible ones first
+			windows := entry.getVisibleWindowsMRU()
+			if len(windows) == 0 {
+				windows = entry.getWindowsMRU()
+			}
+			entry.setCurrentWindowInfo(windows[0])
 		}
 		return true
 	}
@@ -304,9 +384,14 @@ func (entry *AppEntry) getIcon() string {
 	return ""
 }
 
+// updateWindowTitles exposes the windows passing the window filter of the
+// dock manager.
 func (e *AppEntry) updateWindowTitles() {
 	windowTitles := newWindowTitles()
 	for win, winInfo := range e.windows {
+		if e.dockManager != nil && !e.dockManager.isWindowVisibleByFilter(winInfo) {
+			continue
+		}
 		windowTitles[win] = winInfo.Title
 	}
 	if !e.WindowTitles.Equal(windowTitles) {
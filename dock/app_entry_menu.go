@@ -34,6 +34,8 @@ func (entry *AppEntry) updateMenu() {
 	desktopActionMenuItems := entry.getMenuItemDesktopActions()
 	menu.AppendItem(desktopActionMenuItems...)
 
+	menu.AppendItem(entry.getMenuItemRecentFiles()...)
+
 	if entry.hasWindow() {
 		menu.AppendItem(entry.getMenuItemCloseAll())
 		menu.AppendItem(entry.getMenuItemAllWindows())
@@ -75,6 +77,53 @@ func (entry *AppEntry) getMenuItemDesktopActions() []*MenuItem {
 	return items
 }
 
+// getMenuItemRecentFiles returns the pinned files followed by the recent
+// files the app can open.
+func (entry *AppEntry) getMenuItemRecentFiles() []*MenuItem {
+	ai := entry.appInfo
+	m := entry.dockManager
+	if ai == nil || m == nil {
+		return nil
+	}
+
+	openFile := func(uri string) func(timestamp uint32) {
+		return func(timestamp uint32) {
+			logger.Debugf("open file %q with %q", uri, ai.GetId())
+			err := ai.Launch(timestamp, []string{uri})
+			if err != nil {
+				logger.Warning(err)
+				return
+			}
+			m.markAppLaunched(ai)
+		}
+	}
+
+	var items []*MenuItem
+	pinned := m.getPinnedFiles(ai.GetId())
+	for _, uri := range pinned {
+		file := &recentFile{Uri: uri}
+		items = append(items, NewMenuItem(file.getDisplayName(), openFile(uri), true))
+	}
+
+	mimeTypes := getAppMimeTypes(ai)
+	if len(mimeTypes) == 0 {
+		return items
+	}
+	recent := m.getRecentFiles().filter(mimeTypes, recentFilesLimit+len(pinned))
+	count := 0
+	for _, file := range recent {
+		if count >= recentFilesLimit {
+			break
+		}
+		if strSliceContains(pinned, file.Uri) {
+			continue
+		}
+		items = append(items, NewMenuItem(file.getDisplayName(), openFile(file.Uri), true))
+		count++
+	}
+	return items
+}
+
 func (entry *AppEntry) launchApp(timestamp uint32) {
 	logger.Debug("launchApp timestamp:", timestamp)
 
@@ -31,12 +31,23 @@ func (entry *AppEntry) Activate(timestamp uint32) error {
 		return nil
 	}
 
+	// all the windows are filtered out, open a new one in the scope of
+	// the dock
+	visibleWindows := entry.getVisibleWindowsMRU()
+	if len(visibleWindows) == 0 && entry.appInfo != nil {
+		entry.launchApp(timestamp)
+		return nil
+	}
+
 	if entry.current == nil {
 		err := errors.New("entry.current is nil")
 		logger.Warning(err)
 		return err
 	}
 	win := entry.current.window
+	if len(visibleWindows) > 0 && !entry.isWindowVisible(entry.current) {
+		win = visibleWindows[0].window
+	}
 	state, err := ewmh.WmStateGet(XU, win)
 	if err != nil {
 		logger.Warning("Get ewmh wmState failed win:", win)
@@ -53,7 +64,8 @@ func (entry *AppEntry) Activate(timestamp uint32) error {
 		case icccm.StateIconic:
 			activateWindow(win)
 		case icccm.StateNormal:
-			if len(entry.windows) == 1 {
+			if len(visibleWindows) == 1 ||
+				(len(visibleWindows) == 0 && len(entry.windows) == 1) {
 				iconifyWindow(win)
 			} else if entry.dockManager.activeWindow == win {
 				nextWin := entry.findNextLeader()
@@ -102,6 +114,21 @@ func (entry *AppEntry) RequestUndock() {
 	}
 }
 
+// PinFile keeps the file at the top of the recent files of the entry menu.
+func (entry *AppEntry) PinFile(uri string) error {
+	if entry.dockManager == nil {
+		return errors.New("entry.dockManager is nil")
+	}
+	return entry.dockManager.setFilePinned(entry, uri, true)
+}
+
+func (entry *AppEntry) UnpinFile(uri string) error {
+	if entry.dockManager == nil {
+		return errors.New("entry.dockManager is nil")
+	}
+	return entry.dockManager.setFilePinned(entry, uri, false)
+}
+
 func (entry *AppEntry) PresentWindows() {
 	if entry.dockManager != nil {
 		windowIds := entry.getWindowIds()
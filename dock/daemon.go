@@ -90,6 +90,11 @@ func (d *Daemon) GetDependencies() []string {
 	return []string{}
 }
 
+// RunOnMainThread returns true, Start initializes gtk.
+func (d *Daemon) RunOnMainThread() bool {
+	return true
+}
+
 func (d *Daemon) Name() string {
 	return "dock"
 }
@@ -0,0 +1,381 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+// The dock layout file ~/.config/deepin/dde-daemon/dock/layout.json keeps
+// everything the user customized on the dock:
+//
+// {
+//   "version": 1,
+//   "dockedApps": [
+//     {"id": "deepin-terminal"},
+//     {"id": "docked:w:2d9f...", "scratch": {
+//       "name": "My Tool", "icon": "utilities-terminal",
+//       "exec": "", "script": "#!/bin/sh\ncd '/opt/tool'\nexec '/opt/tool/run' \"$@\"\n"}}
+//   ],
+//   "groupPolicyOverrides": {"google-chrome": "never", "thunderbird": "3"},
+//   "pinnedFiles": {"gedit": ["file:///home/user/todo.txt"]}
+// }
+//
+// dockedApps are in the order shown on the dock. Installed apps are saved
+// by desktop id so the file can be shared between users; scratch apps,
+// docked from windows without desktop file, are saved with their content
+// and recreated in the scratch dir by ImportLayout.
+//
+// The settings stay the source of the docked apps and the group policy
+// overrides, the file follows them and is only applied to them by
+// ImportLayout. The pinned files are only kept in the file.
+//
+// When the version of the file is older than dockLayoutVersion, the
+// migrations in dockLayoutMigrations are applied in turn. When the file does
+// not exist, it is created from the settings, and the pinned files are
+// migrated from pinned_files.json. A corrupt file is moved to layout.json.bak
+// and created again.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"pkg.deepin.io/lib/appinfo/desktopappinfo"
+	"pkg.deepin.io/lib/xdg/basedir"
+	"strings"
+)
+
+const dockLayoutVersion = 1
+
+// dockLayoutMigrations[v] migrates layout of version v to version v+1.
+var dockLayoutMigrations = map[int]func(*DockLayout) error{}
+
+type DockLayout struct {
+	Version              int                 `json:"version"`
+	DockedApps           []*DockLayoutApp    `json:"dockedApps"`
+	GroupPolicyOverrides map[string]string   `json:"groupPolicyOverrides,omitempty"`
+	PinnedFiles          map[string][]string `json:"pinnedFiles,omitempty"`
+}
+
+type DockLayoutApp struct {
+	Id      string                `json:"id"`
+	Scratch *DockLayoutScratchApp `json:"scratch,omitempty"`
+}
+
+type DockLayoutScratchApp struct {
+	Name string `json:"name"`
+	Icon string `json:"icon"`
+	// Exec is empty when Script is used.
+	Exec   string `json:"exec,omitempty"`
+	Script string `json:"script,omitempty"`
+}
+
+func getDockLayoutFile() string {
+	return filepath.Join(basedir.GetUserConfigDir(), "deepin/dde-daemon/dock/layout.json")
+}
+
+func parseDockLayout(content []byte) (*DockLayout, error) {
+	var layout DockLayout
+	err := json.Unmarshal(content, &layout)
+	if err != nil {
+		return nil, err
+	}
+	err = layout.migrate()
+	if err != nil {
+		return nil, err
+	}
+	err = layout.check()
+	if err != nil {
+		return nil, err
+	}
+	return &layout, nil
+}
+
+func loadDockLayout(file string) (*DockLayout, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := parseDockLayout(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return layout, nil
+}
+
+func (layout *DockLayout) migrate() error {
+	if layout.Version <= 0 {
+		return errors.New("missing version")
+	}
+	if layout.Version > dockLayoutVersion {
+		return fmt.Errorf("unsupported version %d", layout.Version)
+	}
+	for layout.Version < dockLayoutVersion {
+		migrate, ok := dockLayoutMigrations[layout.Version]
+		if !ok {
+			return fmt.Errorf("no migration from version %d", layout.Version)
+		}
+		err := migrate(layout)
+		if err != nil {
+			return fmt.Errorf("migrate from version %d: %v", layout.Version, err)
+		}
+		layout.Version++
+	}
+	return nil
+}
+
+func (layout *DockLayout) check() error {
+	for _, app := range layout.DockedApps {
+		if app == nil || app.Id == "" {
+			return errors.New("docked app without id")
+		}
+		if strings.ContainsAny(app.Id, "/") {
+			return fmt.Errorf("invalid docked app id %q", app.Id)
+		}
+	}
+	for appId, policy := range layout.GroupPolicyOverrides {
+		if _, err := parseGroupPolicy(policy); err != nil {
+			return fmt.Errorf("app %q: %v", appId, err)
+		}
+	}
+	return nil
+}
+
+func (layout *DockLayout) save(file string) error {
+	content, err := json.MarshalIndent(layout, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+	tmpFile := file + ".tmp"
+	err = ioutil.WriteFile(tmpFile, content, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, file)
+}
+
+func newDockLayoutApp(ai *AppInfo) *DockLayoutApp {
+	app := &DockLayoutApp{Id: ai.GetId()}
+	if !isFileInDir(ai.GetFileName(), scratchDir) {
+		return app
+	}
+
+	scratch := &DockLayoutScratchApp{Icon: ai.GetIcon()}
+	scratch.Name, _ = ai.GetString(desktopappinfo.MainSection, desktopappinfo.KeyName)
+	exec, _ := ai.GetString(desktopappinfo.MainSection, "Exec")
+	script, err := ioutil.ReadFile(filepath.Join(scratchDir, app.Id+".sh"))
+	if err == nil {
+		scratch.Script = string(script)
+	} else {
+		scratch.Exec = exec
+	}
+	app.Scratch = scratch
+	return app
+}
+
+// install returns the value used in the docked apps settings.
+func (app *DockLayoutApp) install() (string, error) {
+	if app.Scratch == nil {
+		return app.Id, nil
+	}
+
+	exec := app.Scratch.Exec
+	if app.Scratch.Script != "" {
+		// the script comes from the imported file, it is not made
+		// executable but run by the shell explicitly
+		scriptFile := filepath.Join(scratchDir, app.Id+".sh")
+		err := ioutil.WriteFile(scriptFile, []byte(app.Scratch.Script), 0644)
+		if err != nil {
+			return "", err
+		}
+		exec = "/bin/sh " + quoteExecArg(scriptFile) + " %U"
+	}
+	err := createScratchDesktopFile(app.Id, app.Scratch.Name, app.Scratch.Icon, exec)
+	if err != nil {
+		return "", err
+	}
+	return zipDesktopPath(filepath.Join(scratchDir, app.Id+desktopExt)), nil
+}
+
+// quoteExecArg quotes the argument of the Exec key of desktop entries, the
+// quoted argument is escaped again for the string value of the key file.
+func quoteExecArg(arg string) string {
+	var quoted bytes.Buffer
+	quoted.WriteByte('"')
+	for _, r := range arg {
+		switch r {
+		case '"', '`', '$', '\\':
+			quoted.WriteByte('\\')
+		}
+		quoted.WriteRune(r)
+	}
+	quoted.WriteByte('"')
+	return strings.NewReplacer("\\", "\\\\", "%", "%%").Replace(quoted.String())
+}
+
+// getDockLayout collects the layout from the docked apps, the group policy
+// overrides and the pinned files.
+func (m *DockManager) getDockLayout() *DockLayout {
+	layout := &DockLayout{
+		Version:              dockLayoutVersion,
+		DockedApps:           []*DockLayoutApp{},
+		GroupPolicyOverrides: make(map[string]string),
+		PinnedFiles:          make(map[string][]string),
+	}
+
+	for _, app := range m.DockedApps.Get() {
+		ai := NewDockedAppInfo(app)
+		if ai == nil {
+			logger.Warningf("getDockLayout: invalid docked app %q", app)
+			continue
+		}
+		layout.DockedApps = append(layout.DockedApps, newDockLayoutApp(ai))
+	}
+
+	m.groupPolicyMutex.RLock()
+	for appId, policy := range m.groupPolicyOverrides {
+		layout.GroupPolicyOverrides[appId] = policy.String()
+	}
+	m.groupPolicyMutex.RUnlock()
+
+	m.recentFilesMutex.Lock()
+	for appId, uris := range m.pinnedFiles {
+		layout.PinnedFiles[appId] = uris
+	}
+	m.recentFilesMutex.Unlock()
+	return layout
+}
+
+func (m *DockManager) saveDockLayout() {
+	if !m.dockLayoutLoaded {
+		return
+	}
+	err := m.getDockLayout().save(getDockLayoutFile())
+	if err != nil {
+		logger.Warning("saveDockLayout failed:", err)
+	}
+}
+
+// loadDockLayout is called before the entries are initialized, it loads
+// the pinned files from the layout file and updates the file with the
+// settings, or creates the layout file.
+func (m *DockManager) loadDockLayout() {
+	m.loadGroupPolicyOverrides()
+
+	file := getDockLayoutFile()
+	layout, err := loadDockLayout(file)
+	if err == nil {
+		pinned := make(pinnedFiles)
+		for appId, uris := range layout.PinnedFiles {
+			pinned[appId] = uris
+		}
+		m.setPinnedFiles(pinned)
+	} else if os.IsNotExist(err) {
+		logger.Info("migrate dock settings to", file)
+		m.setPinnedFiles(loadLegacyPinnedFiles())
+	} else {
+		logger.Warning("loadDockLayout failed:", err)
+		m.setPinnedFiles(loadLegacyPinnedFiles())
+		// keep the corrupt file for the user to recover
+		bakFile := file + ".bak"
+		err = os.Rename(file, bakFile)
+		if err != nil {
+			logger.Warning("backup dock layout failed:", err)
+			return
+		}
+		logger.Warning("corrupt dock layout is moved to", bakFile)
+	}
+
+	m.dockLayoutLoaded = true
+	m.saveDockLayout()
+}
+
+// applyDockLayoutSettings saves the layout to the settings and returns the
+// docked apps.
+func (m *DockManager) applyDockLayoutSettings(layout *DockLayout) []string {
+	var dockedApps []string
+	for _, app := range layout.DockedApps {
+		value, err := app.install()
+		if err != nil {
+			logger.Warningf("install docked app %q failed: %v", app.Id, err)
+			continue
+		}
+		dockedApps = append(dockedApps, value)
+	}
+	m.DockedApps.Set(dockedApps)
+
+	var overrides []string
+	for appId, policy := range layout.GroupPolicyOverrides {
+		overrides = append(overrides, appId+"="+policy)
+	}
+	m.GroupPolicyOverrides.Set(overrides)
+	m.loadGroupPolicyOverrides()
+
+	pinned := make(pinnedFiles)
+	for appId, uris := range layout.PinnedFiles {
+		pinned[appId] = uris
+	}
+	m.setPinnedFiles(pinned)
+	return dockedApps
+}
+
+// ExportLayout returns the dock layout in JSON.
+func (m *DockManager) ExportLayout() (string, error) {
+	content, err := json.MarshalIndent(m.getDockLayout(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// ImportLayout replaces the docked apps, the group policy overrides and the
+// pinned files with the layout exported by ExportLayout.
+func (m *DockManager) ImportLayout(layoutJSON string) error {
+	layout, err := parseDockLayout([]byte(layoutJSON))
+	if err != nil {
+		logger.Warning("ImportLayout failed:", err)
+		return err
+	}
+
+	dockedApps := m.applyDockLayoutSettings(layout)
+	return m.runInXEventLoop(func() {
+		m.importDockedApps(dockedApps)
+	})
+}
+
+// importDockedApps docks the apps in order and undocks the others, it
+// changes the entries so it runs in the X event loop.
+func (m *DockManager) importDockedApps(dockedApps []string) {
+	var innerIds []string
+	for _, app := range dockedApps {
+		if ai := NewDockedAppInfo(app); ai != nil {
+			innerIds = append(innerIds, ai.innerId)
+		}
+	}
+	for _, entry := range m.Entries.FilterDocked() {
+		if !strSliceContains(innerIds, entry.innerId) {
+			m.undockEntry(entry)
+		}
+	}
+	for _, app := range dockedApps {
+		m.appendDockedApp(app)
+	}
+	m.Entries = m.Entries.sortDockedByInnerIds(innerIds)
+
+	for _, entry := range m.Entries {
+		entry.updateMenu()
+	}
+	m.regroupAllEntries()
+	m.saveDockedApps()
+}
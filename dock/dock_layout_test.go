@@ -0,0 +1,62 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func Test_loadDockLayout(t *testing.T) {
+	Convey("loadDockLayout", t, func() {
+		layout, err := loadDockLayout("testdata/dock_layout/layout.json")
+		So(err, ShouldBeNil)
+		So(layout.Version, ShouldEqual, dockLayoutVersion)
+		So(len(layout.DockedApps), ShouldEqual, 2)
+		So(layout.DockedApps[0].Id, ShouldEqual, "deepin-terminal")
+		So(layout.DockedApps[0].Scratch, ShouldBeNil)
+		So(layout.DockedApps[1].Scratch.Name, ShouldEqual, "My Tool")
+		So(layout.GroupPolicyOverrides["thunderbird"], ShouldEqual, "3")
+		So(layout.PinnedFiles["gedit"], ShouldResemble, []string{"file:///home/user/todo.txt"})
+	})
+
+	Convey("parseDockLayout invalid", t, func() {
+		_, err := parseDockLayout([]byte(`{"dockedApps": []}`))
+		So(err, ShouldNotBeNil)
+
+		_, err = parseDockLayout([]byte(`{"version": 100, "dockedApps": []}`))
+		So(err, ShouldNotBeNil)
+
+		_, err = parseDockLayout([]byte(`{"version": 1, "dockedApps": [{"id": "/usr/bin/sh"}]}`))
+		So(err, ShouldNotBeNil)
+
+		_, err = parseDockLayout([]byte(`{"version": 1, "dockedApps": [], "groupPolicyOverrides": {"a": "sometimes"}}`))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func Test_sortDockedByInnerIds(t *testing.T) {
+	Convey("AppEntries sortDockedByInnerIds", t, func() {
+		a := &AppEntry{Id: "a", innerId: "ia", IsDocked: true}
+		b := &AppEntry{Id: "b", innerId: "ib"}
+		c := &AppEntry{Id: "c", innerId: "ic", IsDocked: true}
+		entries := AppEntries{a, b, c}
+
+		sorted := entries.sortDockedByInnerIds([]string{"ic", "ia"})
+		So(sorted, ShouldResemble, AppEntries{c, a, b})
+	})
+}
+
+func Test_quoteExecArg(t *testing.T) {
+	Convey("quoteExecArg", t, func() {
+		So(quoteExecArg("/tmp/run.sh"), ShouldEqual, `"/tmp/run.sh"`)
+		So(quoteExecArg("/tmp/a b/\"$`\\%.sh"), ShouldEqual, `"/tmp/a b/\\"\\$\\`+"`"+`\\\\%%.sh"`)
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"gir/gio-2.0"
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xevent"
 	"pkg.deepin.io/lib/dbus"
 	"pkg.deepin.io/lib/dbus/property"
 	"sync"
@@ -42,17 +43,55 @@ type DockManager struct {
 	HideTimeout *property.GSettingsUintProperty `access:"readwrite"`
 	DockedApps  *property.GSettingsStrvProperty
 
+	// daemonSettings keeps the settings only used by dde-daemon
+	daemonSettings *gio.Settings
+
+	FilterWindowsByWorkspace *property.GSettingsBoolProperty `access:"readwrite"`
+	FilterWindowsByMonitor   *property.GSettingsBoolProperty `access:"readwrite"`
+	windowFilter             windowFilter
+	windowFilterMutex        sync.RWMutex
+
+	GroupPolicy          *property.GSettingsEnumProperty `access:"readwrite"`
+	GroupThreshold       *property.GSettingsUintProperty `access:"readwrite"`
+	GroupPolicyOverrides *property.GSettingsStrvProperty
+	groupPolicyOverrides map[string]groupPolicy
+	groupPolicyMutex     sync.RWMutex
+
 	activeWindow xproto.Window
+	activeSerial uint64
 
 	HideState HideStateType
 
-	smartHideModeTimer *time.Timer
-	smartHideModeMutex sync.Mutex
+	hideStateTimer *time.Timer
+	hideStateMutex sync.Mutex
 
 	entryCount         uint
 	FrontendWindowRect *Rect
 	identifyWindowFuns []*IdentifyWindowFunc
-	windowPatterns     WindowPatterns
+
+	windowPatterns        WindowPatterns
+	windowPatternsMutex   sync.RWMutex
+	windowPatternsWatcher *fileWatcher
+
+	// dockLayoutLoaded prevents saving the layout file before it is
+	// applied.
+	dockLayoutLoaded bool
+
+	recentFiles        recentFiles
+	pinnedFiles        pinnedFiles
+	recentFilesMutex   sync.Mutex
+	recentFilesWatcher *fileWatcher
+
+	thumbnailManager *thumbnailManager
+
+	launcherEntryStates  map[string]*launcherEntryState
+	launcherEntryMutex   sync.Mutex
+	launcherEntrySigChan <-chan *dbus.Signal
+
+	// tasksWindow receives the client messages waking up the X event
+	// loop to run the tasks queued by runInXEventLoop.
+	tasksWindow xproto.Window
+	tasks       chan func()
 
 	launcher         *launcher.Launcher
 	wm               *wm.Wm
@@ -66,6 +105,7 @@ type DockManager struct {
 
 const (
 	dockSchema            = "com.deepin.dde.dock"
+	dockDaemonSchema      = "com.deepin.dde.daemon.dock"
 	settingKeyHideMode    = "hide-mode"
 	settingKeyDisplayMode = "display-mode"
 	settingKeyPosition    = "position"
@@ -73,6 +113,13 @@ const (
 	settingKeyDockedApps  = "docked-apps"
 	settingKeyShowTimeout = "show-timeout"
 	settingKeyHideTimeout = "hide-timeout"
+
+	settingKeyFilterWindowsByWorkspace = "filter-windows-by-workspace"
+	settingKeyFilterWindowsByMonitor   = "filter-windows-by-monitor"
+
+	settingKeyGroupPolicy          = "group-policy"
+	settingKeyGroupThreshold       = "group-threshold"
+	settingKeyGroupPolicyOverrides = "group-policy-overrides"
 )
 
 func NewDockManager() (*DockManager, error) {
@@ -85,9 +132,9 @@ func NewDockManager() (*DockManager, error) {
 }
 
 func (m *DockManager) destroy() {
-	if m.smartHideModeTimer != nil {
-		m.smartHideModeTimer.Stop()
-		m.smartHideModeTimer = nil
+	if m.hideStateTimer != nil {
+		m.hideStateTimer.Stop()
+		m.hideStateTimer = nil
 	}
 
 	if m.settings != nil {
@@ -95,6 +142,21 @@ func (m *DockManager) destroy() {
 		m.settings = nil
 	}
 
+	if m.daemonSettings != nil {
+		m.daemonSettings.Unref()
+		m.daemonSettings = nil
+	}
+
+	if m.tasksWindow != 0 {
+		xevent.Detach(XU, m.tasksWindow)
+		xproto.DestroyWindow(XU.Conn(), m.tasksWindow)
+		m.tasksWindow = 0
+	}
+
+	m.stopWatchWindowPatterns()
+	m.stopWatchRecentFiles()
+	m.stopListenLauncherEntrySignal()
+
 	if m.wm != nil {
 		wm.DestroyWm(m.wm)
 		m.wm = nil
@@ -161,6 +223,9 @@ func (m *DockManager) SetFrontendWindowRect(x, y int32, width, height uint32) {
 	m.FrontendWindowRect.Width = width
 	m.FrontendWindowRect.Height = height
 	dbus.NotifyChange(m, "FrontendWindowRect")
+	if m.isFilterWindowsByMonitor() {
+		m.updateWindowFilter()
+	}
 	m.updateHideStateWithoutDelay()
 }
 
@@ -61,6 +61,24 @@ func removeScratchFiles(id string) {
 	}
 }
 
+// saveScratchIcon saves the data uri icon to the scratch dir, returns the
+// icon used in the desktop file.
+func saveScratchIcon(id, icon string) string {
+	if strings.HasPrefix(icon, "data:image") {
+		path, err := dataUriToFile(icon, filepath.Join(scratchDir, id+".png"))
+		if err != nil {
+			logger.Warning(err)
+			icon = ""
+		} else {
+			icon = path
+		}
+	}
+	if icon == "" {
+		icon = "application-default-icon"
+	}
+	return icon
+}
+
 func createScratchDesktopFileWithAppEntry(entry *AppEntry) string {
 	appId := "docked:" + entry.innerId
 
@@ -78,19 +96,7 @@ func createScratchDesktopFileWithAppEntry(entry *AppEntry) string {
 
 	title := entry.current.getDisplayName()
 	// icon
-	icon := entry.current.getIcon()
-	if strings.HasPrefix(icon, "data:image") {
-		path, err := dataUriToFile(icon, filepath.Join(scratchDir, appId+".png"))
-		if err != nil {
-			logger.Warning(err)
-			icon = ""
-		} else {
-			icon = path
-		}
-	}
-	if icon == "" {
-		icon = "application-default-icon"
-	}
+	icon := saveScratchIcon(appId, entry.current.getIcon())
 
 	// cmd
 	scriptContent := entry.getExec(false)
@@ -116,7 +122,8 @@ func (m *DockManager) saveDockedApps() {
 		path := entry.appInfo.GetFileName()
 		list = append(list, zipDesktopPath(path))
 	}
-	m.DockedApps.Set(list)
+	m.DockedApps.Set(uniqStrSlice(list))
+	m.saveDockLayout()
 }
 
 func (m *DockManager) dockEntry(entry *AppEntry) bool {
@@ -144,6 +151,14 @@ func (m *DockManager) dockEntry(entry *AppEntry) bool {
 			entryOldInnerId := entry.innerId
 			entry.innerId = entry.appInfo.innerId
 			logger.Debug("dockEntry: createScratchDesktopFile successed, entry use new innerId", entry.innerId)
+			// the entries split from the same group follow the docked one
+			for _, e := range m.getEntriesByInnerId(entryOldInnerId) {
+				e.windowMutex.Lock()
+				e.setAppInfo(entry.appInfo)
+				e.innerId = entry.innerId
+				e.updateIcon()
+				e.windowMutex.Unlock()
+			}
 
 			if strings.HasPrefix(entryOldInnerId, windowHashPrefix) {
 				// entryOldInnerId is window hash
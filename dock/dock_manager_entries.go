@@ -43,7 +43,10 @@ func (m *DockManager) attachOrDetachWindow(winInfo *WindowInfo) {
 	} else {
 
 		if winInfo.entryInnerId == "" {
-			winInfo.entryInnerId, winInfo.appInfo = m.identifyWindow(winInfo)
+			entryInnerId, appInfo := m.identifyWindow(winInfo)
+			winInfo.mutex.Lock()
+			winInfo.entryInnerId, winInfo.appInfo = entryInnerId, appInfo
+			winInfo.mutex.Unlock()
 			go m.markAppLaunched(winInfo.appInfo)
 		} else {
 			logger.Debugf("win %v identified", win)
@@ -85,6 +88,8 @@ func (m *DockManager) installAppEntry(e *AppEntry) {
 		return
 	}
 
+	e.updateLauncherEntry()
+
 	entryObjPath := dbus.ObjectPath(entryDBusObjPathPrefix + e.Id)
 	logger.Debugf("insertAndInstallAppEntry %v", entryObjPath)
 	index := m.Entries.IndexOf(e)
@@ -144,10 +149,8 @@ func (m *DockManager) removeAppEntry(e *AppEntry) {
 }
 
 func (m *DockManager) attachWindow(winInfo *WindowInfo) {
-	entry, isNewAdded := m.addAppEntry(winInfo.entryInnerId, winInfo.appInfo, -1)
+	entry, isNewAdded := m.getEntryForWindow(winInfo)
 	entry.windowMutex.Lock()
-	defer entry.windowMutex.Unlock()
-
 	entry.attachWindow(winInfo)
 	entry.updateMenu()
 	if isNewAdded {
@@ -155,6 +158,9 @@ func (m *DockManager) attachWindow(winInfo *WindowInfo) {
 		entry.updateIcon()
 		m.installAppEntry(entry)
 	}
+	entry.windowMutex.Unlock()
+
+	m.regroupEntries(winInfo.entryInnerId)
 }
 
 func (m *DockManager) detachWindow(winInfo *WindowInfo) {
@@ -162,20 +168,25 @@ func (m *DockManager) detachWindow(winInfo *WindowInfo) {
 	if entry == nil {
 		return
 	}
+	winInfo.mutex.Lock()
 	winInfo.entry = nil
+	winInfo.mutex.Unlock()
 	entry.windowMutex.Lock()
-	defer entry.windowMutex.Unlock()
 
 	detached := entry.detachWindow(winInfo)
 	if !detached {
+		entry.windowMutex.Unlock()
 		return
 	}
 	if !entry.hasWindow() && !entry.IsDocked {
 		m.removeAppEntry(entry)
-		return
+	} else {
+		entry.updateWindowTitles()
+		entry.updateIcon()
+		entry.updateMenu()
+		entry.updateIsActive()
 	}
-	entry.updateWindowTitles()
-	entry.updateIcon()
-	entry.updateMenu()
-	entry.updateIsActive()
+	entry.windowMutex.Unlock()
+
+	m.regroupEntries(entry.innerId)
 }
@@ -10,10 +10,10 @@
 package dock
 
 import (
-	"errors"
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/BurntSushi/xgbutil/ewmh"
 	"github.com/BurntSushi/xgbutil/icccm"
+	"github.com/BurntSushi/xgbutil/xinerama"
 	"github.com/BurntSushi/xgbutil/xrect"
 	"pkg.deepin.io/lib/dbus"
 	"time"
@@ -47,145 +47,183 @@ func hasIntersection(rectA, rectB xrect.Rect) bool {
 	return ax < bx && ay < by
 }
 
-func (m *DockManager) isWindowDockOverlap(win xproto.Window) (bool, error) {
-	// overlap condition:
-	// window type is not desktop
-	// window showing and  on current workspace,
-	// window dock rect has intersection
-	windowType, err := ewmh.WmWindowTypeGet(XU, win)
+const (
+	DDELauncher = "dde-launcher"
+)
+
+func (m *DockManager) isDeepinLauncherShown() bool {
+	winClass, err := icccm.WmClassGet(XU, m.activeWindow)
 	if err != nil {
 		logger.Debug(err)
+		return false
 	}
-	if strSliceContains(windowType, "_NET_WM_WINDOW_TYPE_DESKTOP") {
-		return false, nil
+	return winClass.Instance == DDELauncher
+}
+
+// hideStateWindow is a window taken into account by the hide state.
+type hideStateWindow struct {
+	geometry   xrect.Rect
+	fullscreen bool
+}
+
+type hideStateContext struct {
+	mode          HideModeType
+	launcherShown bool
+	dockRect      xrect.Rect
+	// monitor the dock is on, nil if unknown
+	monitor xrect.Rect
+	// the windows shown on the current workspace
+	windows []hideStateWindow
+}
+
+// isHideStateGeometryDependent tells whether the hide state changes when
+// the window moves or resizes, that is in auto hide and smart hide modes, or
+// the fullscreen window moves to another monitor.
+func isHideStateGeometryDependent(mode HideModeType, fullscreen bool) bool {
+	return mode == HideModeAutoHide || mode == HideModeSmartHide || fullscreen
+}
+
+func (m *DockManager) isWindowHideStateGeometryDependent(winInfo *WindowInfo) bool {
+	winInfo.mutex.RLock()
+	fullscreen := strSliceContains(winInfo.wmState, "_NET_WM_STATE_FULLSCREEN")
+	winInfo.mutex.RUnlock()
+	return isHideStateGeometryDependent(HideModeType(m.HideMode.Get()), fullscreen)
+}
+
+func calcHideState(ctx *hideStateContext) HideStateType {
+	if ctx.launcherShown {
+		return HideStateShow
 	}
 
-	if isHiddenPre(win) || (!onCurrentWorkspacePre(win)) {
-		logger.Debugf("window %v is hidden or not on current workspace", win)
-		return false, nil
+	var windows []hideStateWindow
+	for _, win := range ctx.windows {
+		if win.geometry == nil {
+			continue
+		}
+		if ctx.monitor == nil || hasIntersection(win.geometry, ctx.monitor) {
+			windows = append(windows, win)
+		}
 	}
 
-	winRect, err := getWindowGeometry(XU, win)
-	if err != nil {
-		logger.Warning("Get target window geometry failed", err)
-		return false, err
+	// fullscreen windows always hide the dock
+	for _, win := range windows {
+		if win.fullscreen {
+			return HideStateHide
+		}
 	}
 
-	logger.Debug("window rect:", winRect)
-	logger.Debug("dock rect:", m.FrontendWindowRect)
-	result := hasIntersection(winRect, m.FrontendWindowRect.ToXRect())
-	logger.Debug("window dock overlap:", result)
-	return result, nil
-}
+	switch ctx.mode {
+	case HideModeKeepHidden:
+		return HideStateHide
 
-const (
-	DDELauncher = "dde-launcher"
-)
+	case HideModeAutoHide:
+		// hide when any window is on the monitor
+		if len(windows) > 0 {
+			return HideStateHide
+		}
+		return HideStateShow
 
-func (m *DockManager) isDeepinLauncherShown() bool {
-	winClass, err := icccm.WmClassGet(XU, m.activeWindow)
+	case HideModeSmartHide:
+		// hide when any window overlaps the dock
+		for _, win := range windows {
+			if hasIntersection(win.geometry, ctx.dockRect) {
+				return HideStateHide
+			}
+		}
+		return HideStateShow
+
+	default:
+		return HideStateShow
+	}
+}
+
+func (m *DockManager) getHideStateWindow(win xproto.Window) (hideStateWindow, bool) {
+	var result hideStateWindow
+	windowType, err := ewmh.WmWindowTypeGet(XU, win)
 	if err != nil {
 		logger.Debug(err)
-		return false
 	}
-	return winClass.Instance == DDELauncher
-}
+	if strSliceContains(windowType, "_NET_WM_WINDOW_TYPE_DESKTOP") ||
+		strSliceContains(windowType, "_NET_WM_WINDOW_TYPE_DOCK") {
+		return result, false
+	}
 
-func (m *DockManager) shouldHideOnSmartHideMode() (bool, error) {
-	if m.activeWindow == 0 {
-		logger.Debug("shouldHideOnSmartHideMode: activeWindow is 0")
-		return false, errors.New("activeWindow is 0")
+	state, _ := ewmh.WmStateGet(XU, win)
+	if strSliceContains(state, "_NET_WM_STATE_HIDDEN") || !onCurrentWorkspacePre(win) {
+		return result, false
 	}
-	if m.isDeepinLauncherShown() {
-		logger.Debug("launcher is shown")
-		return false, nil
+	result.fullscreen = strSliceContains(state, "_NET_WM_STATE_FULLSCREEN")
+
+	result.geometry, err = getWindowGeometry(XU, win)
+	if err != nil {
+		logger.Debug("Get window geometry failed:", err)
+		return result, false
 	}
-	return m.isWindowDockOverlap(m.activeWindow)
+	return result, true
 }
 
-func (m *DockManager) smartHideModeTimerExpired() {
-	logger.Debug("smartHideModeTimer expired!")
-	shouldHide, err := m.shouldHideOnSmartHideMode()
-	if err != nil {
-		m.setPropHideState(HideStateUnknown)
-		return
+func (m *DockManager) getHideStateContext() *hideStateContext {
+	ctx := &hideStateContext{
+		mode:          HideModeType(m.HideMode.Get()),
+		launcherShown: m.isDeepinLauncherShown(),
+		dockRect:      m.FrontendWindowRect.ToXRect(),
 	}
 
-	if shouldHide {
-		m.setPropHideState(HideStateHide)
+	monitors, err := xinerama.PhysicalHeads(XU)
+	if err != nil {
+		logger.Warning("get monitors failed:", err)
 	} else {
-		m.setPropHideState(HideStateShow)
+		ctx.monitor = getMonitorOfRect(monitors, ctx.dockRect)
 	}
-}
 
-func (m *DockManager) resetSmartHideModeTimer(delay time.Duration) {
-	m.smartHideModeMutex.Lock()
-	defer m.smartHideModeMutex.Unlock()
+	for _, win := range m.clientList {
+		if hideStateWin, ok := m.getHideStateWindow(win); ok {
+			ctx.windows = append(ctx.windows, hideStateWin)
+		}
+	}
+	return ctx
+}
 
-	m.smartHideModeTimer.Reset(delay)
-	logger.Debug("reset smart hide mode timer ", delay)
+func (m *DockManager) hideStateTimerExpired() {
+	logger.Debug("hideStateTimer expired!")
+	m.setPropHideState(calcHideState(m.getHideStateContext()))
 }
 
-func (m *DockManager) cancelSmartHideModeTimer() {
-	m.smartHideModeMutex.Lock()
-	defer m.smartHideModeMutex.Unlock()
+func (m *DockManager) resetHideStateTimer(delay time.Duration) {
+	m.hideStateMutex.Lock()
+	defer m.hideStateMutex.Unlock()
 
-	m.smartHideModeTimer.Stop()
-	logger.Debug("cancel smart hide mode timer ")
+	m.hideStateTimer.Reset(delay)
+	logger.Debug("reset hide state timer ", delay)
 }
 
-func (m *DockManager) smartHideModeDelayHandle() {
-	shouldHide, err := m.shouldHideOnSmartHideMode()
-	if err != nil {
-		m.cancelSmartHideModeTimer()
-		m.setPropHideState(HideStateUnknown)
-		return
-	}
+func (m *DockManager) cancelHideStateTimer() {
+	m.hideStateMutex.Lock()
+	defer m.hideStateMutex.Unlock()
 
-	switch m.HideState {
-	case HideStateShow:
-		if shouldHide {
-			logger.Debug("smartHideModeDelayHandle: show -> hide")
-			m.resetSmartHideModeTimer(time.Millisecond * 500)
-		} else {
-			logger.Debug("smartHideModeDelayHandle: show -> show")
-			m.cancelSmartHideModeTimer()
-		}
-	case HideStateHide:
-		if shouldHide {
-			logger.Debug("smartHideModeDelayHandle: hide -> hide")
-			m.cancelSmartHideModeTimer()
-		} else {
-			logger.Debug("smartHideModeDelayHandle: hide -> show")
-			m.resetSmartHideModeTimer(time.Millisecond * 500)
-		}
-	default:
-		m.resetSmartHideModeTimer(0)
-	}
+	m.hideStateTimer.Stop()
+	logger.Debug("cancel hide state timer ")
 }
 
+// updateHideState changes the hide state after ShowTimeout or HideTimeout
+// if delay is true.
 func (m *DockManager) updateHideState(delay bool) {
-	if m.isDeepinLauncherShown() {
-		logger.Debug("updateHideState: launcher is shown, show dock")
-		m.setPropHideState(HideStateShow)
+	if !delay {
+		m.resetHideStateTimer(0)
 		return
 	}
 
-	hideMode := HideModeType(m.HideMode.Get())
-	logger.Debug("updateHideState: mode is", hideMode)
-	switch hideMode {
-	case HideModeKeepShowing:
-		m.setPropHideState(HideStateShow)
-
-	case HideModeKeepHidden:
-		m.setPropHideState(HideStateHide)
-
-	case HideModeSmartHide:
-		if delay {
-			m.smartHideModeDelayHandle()
-		} else {
-			m.smartHideModeTimer.Reset(0)
-		}
+	hideState := calcHideState(m.getHideStateContext())
+	logger.Debugf("updateHideState: %v -> %v", m.HideState, hideState)
+	switch {
+	case m.HideState == hideState:
+		m.cancelHideStateTimer()
+	case m.HideState == HideStateUnknown:
+		m.resetHideStateTimer(0)
+	case hideState == HideStateShow:
+		m.resetHideStateTimer(time.Duration(m.ShowTimeout.Get()) * time.Millisecond)
+	default:
+		m.resetHideStateTimer(time.Duration(m.HideTimeout.Get()) * time.Millisecond)
 	}
 }
 
@@ -0,0 +1,72 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"github.com/BurntSushi/xgbutil/xrect"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func Test_calcHideState(t *testing.T) {
+	left := xrect.New(0, 0, 1920, 1080)
+	dockRect := xrect.New(660, 1020, 600, 60)
+
+	newCtx := func(mode HideModeType, windows ...hideStateWindow) *hideStateContext {
+		return &hideStateContext{
+			mode:     mode,
+			dockRect: dockRect,
+			monitor:  left,
+			windows:  windows,
+		}
+	}
+	overlapping := hideStateWindow{geometry: xrect.New(0, 0, 1920, 1050)}
+	small := hideStateWindow{geometry: xrect.New(100, 100, 400, 300)}
+	otherMonitor := hideStateWindow{geometry: xrect.New(1920, 0, 1280, 1024)}
+	fullscreen := hideStateWindow{geometry: xrect.New(0, 0, 1920, 1080), fullscreen: true}
+	fullscreenOther := hideStateWindow{geometry: xrect.New(1920, 0, 1280, 1024), fullscreen: true}
+
+	Convey("keep modes", t, func() {
+		So(calcHideState(newCtx(HideModeKeepShowing, overlapping)), ShouldEqual, HideStateShow)
+		So(calcHideState(newCtx(HideModeKeepHidden)), ShouldEqual, HideStateHide)
+	})
+
+	Convey("smart hide considers all windows on the monitor", t, func() {
+		So(calcHideState(newCtx(HideModeSmartHide)), ShouldEqual, HideStateShow)
+		So(calcHideState(newCtx(HideModeSmartHide, small)), ShouldEqual, HideStateShow)
+		So(calcHideState(newCtx(HideModeSmartHide, small, overlapping)), ShouldEqual, HideStateHide)
+		So(calcHideState(newCtx(HideModeSmartHide, otherMonitor)), ShouldEqual, HideStateShow)
+	})
+
+	Convey("auto hide", t, func() {
+		So(calcHideState(newCtx(HideModeAutoHide)), ShouldEqual, HideStateShow)
+		So(calcHideState(newCtx(HideModeAutoHide, small)), ShouldEqual, HideStateHide)
+		So(calcHideState(newCtx(HideModeAutoHide, otherMonitor)), ShouldEqual, HideStateShow)
+	})
+
+	Convey("fullscreen windows", t, func() {
+		So(calcHideState(newCtx(HideModeKeepShowing, fullscreen)), ShouldEqual, HideStateHide)
+		So(calcHideState(newCtx(HideModeKeepShowing, fullscreenOther)), ShouldEqual, HideStateShow)
+
+		ctx := newCtx(HideModeKeepShowing, fullscreen)
+		ctx.launcherShown = true
+		So(calcHideState(ctx), ShouldEqual, HideStateShow)
+	})
+}
+
+func Test_isHideStateGeometryDependent(t *testing.T) {
+	Convey("isHideStateGeometryDependent", t, func() {
+		So(isHideStateGeometryDependent(HideModeKeepShowing, false), ShouldBeFalse)
+		So(isHideStateGeometryDependent(HideModeKeepHidden, false), ShouldBeFalse)
+		So(isHideStateGeometryDependent(HideModeAutoHide, false), ShouldBeTrue)
+		So(isHideStateGeometryDependent(HideModeSmartHide, false), ShouldBeTrue)
+		So(isHideStateGeometryDependent(HideModeKeepShowing, true), ShouldBeTrue)
+	})
+}
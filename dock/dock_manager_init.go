@@ -50,6 +50,10 @@ func (m *DockManager) connectSettingKeyChanged(key string, handler func(*gio.Set
 	m.settings.Connect("changed::"+key, handler)
 }
 
+func (m *DockManager) connectDaemonSettingKeyChanged(key string, handler func(*gio.Settings, string)) {
+	m.daemonSettings.Connect("changed::"+key, handler)
+}
+
 func (m *DockManager) listenSettingsChanged() {
 	// listen hide mode change
 	m.connectSettingKeyChanged(settingKeyHideMode, func(g *gio.Settings, key string) {
@@ -64,6 +68,27 @@ func (m *DockManager) listenSettingsChanged() {
 		logger.Debug(key, "changed to", mode)
 	})
 
+	m.connectDaemonSettingKeyChanged(settingKeyFilterWindowsByWorkspace, func(g *gio.Settings, key string) {
+		m.updateWindowFilter()
+	})
+	m.connectDaemonSettingKeyChanged(settingKeyFilterWindowsByMonitor, func(g *gio.Settings, key string) {
+		m.updateWindowFilter()
+	})
+
+	// listen group policy change
+	m.connectDaemonSettingKeyChanged(settingKeyGroupPolicy, func(g *gio.Settings, key string) {
+		logger.Debug(key, "changed to", GroupPolicyType(g.GetEnum(key)))
+		m.regroupAllEntries()
+	})
+	m.connectDaemonSettingKeyChanged(settingKeyGroupThreshold, func(g *gio.Settings, key string) {
+		m.regroupAllEntries()
+	})
+	m.connectDaemonSettingKeyChanged(settingKeyGroupPolicyOverrides, func(g *gio.Settings, key string) {
+		m.loadGroupPolicyOverrides()
+		m.regroupAllEntries()
+		m.saveDockLayout()
+	})
+
 	// listen position change
 	m.connectSettingKeyChanged(settingKeyPosition, func(g *gio.Settings, key string) {
 		position := positionType(g.GetEnum(key))
@@ -108,6 +133,7 @@ func (m *DockManager) init() error {
 	var err error
 
 	m.settings = gio.NewSettings(dockSchema)
+	m.daemonSettings = gio.NewSettings(dockDaemonSchema)
 
 	m.HideMode = property.NewGSettingsEnumProperty(m, "HideMode", m.settings, settingKeyHideMode)
 	m.DisplayMode = property.NewGSettingsEnumProperty(m, "DisplayMode", m.settings, settingKeyDisplayMode)
@@ -116,10 +142,15 @@ func (m *DockManager) init() error {
 	m.ShowTimeout = property.NewGSettingsUintProperty(m, "ShowTimeout", m.settings, settingKeyShowTimeout)
 	m.HideTimeout = property.NewGSettingsUintProperty(m, "HideTimeout", m.settings, settingKeyHideTimeout)
 	m.DockedApps = property.NewGSettingsStrvProperty(m, "DockedApps", m.settings, settingKeyDockedApps)
+	m.FilterWindowsByWorkspace = property.NewGSettingsBoolProperty(m, "FilterWindowsByWorkspace", m.daemonSettings, settingKeyFilterWindowsByWorkspace)
+	m.FilterWindowsByMonitor = property.NewGSettingsBoolProperty(m, "FilterWindowsByMonitor", m.daemonSettings, settingKeyFilterWindowsByMonitor)
+	m.GroupPolicy = property.NewGSettingsEnumProperty(m, "GroupPolicy", m.daemonSettings, settingKeyGroupPolicy)
+	m.GroupThreshold = property.NewGSettingsUintProperty(m, "GroupThreshold", m.daemonSettings, settingKeyGroupThreshold)
+	m.GroupPolicyOverrides = property.NewGSettingsStrvProperty(m, "GroupPolicyOverrides", m.daemonSettings, settingKeyGroupPolicyOverrides)
 
 	m.FrontendWindowRect = NewRect()
-	m.smartHideModeTimer = time.AfterFunc(10*time.Second, m.smartHideModeTimerExpired)
-	m.smartHideModeTimer.Stop()
+	m.hideStateTimer = time.AfterFunc(10*time.Second, m.hideStateTimerExpired)
+	m.hideStateTimer.Stop()
 
 	m.listenSettingsChanged()
 
@@ -129,11 +160,14 @@ func (m *DockManager) init() error {
 		return err
 	}
 	m.windowInfoMap = make(map[xproto.Window]*WindowInfo)
-	m.windowPatterns, err = loadWindowPatterns(windowPatternsFile)
-	if err != nil {
-		logger.Warning("loadWindowPatterns failed:", err)
-	}
+	m.thumbnailManager = newThumbnailManager()
+	m.loadWindowPatterns()
+	m.watchWindowPatterns()
 	m.registerIdentifyWindowFuncs()
+	m.updateWindowFilter()
+	m.loadDockLayout()
+	m.loadRecentFiles()
+	m.watchRecentFiles()
 	m.initEntries()
 
 	m.wm, err = wm.NewWm("com.deepin.wm", "/com/deepin/wm")
@@ -157,6 +191,11 @@ func (m *DockManager) init() error {
 		return err
 	}
 
+	err = m.listenLauncherEntrySignal()
+	if err != nil {
+		logger.Warning("listen launcher entry signal failed:", err)
+	}
+
 	// 强制将 ClassicMode 转为 EfficientMode
 	if m.DisplayMode.Get() == int32(DisplayModeClassicMode) {
 		m.DisplayMode.Set(int32(DisplayModeEfficientMode))
@@ -10,6 +10,7 @@
 package dock
 
 import (
+	"errors"
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/BurntSushi/xgbutil"
 	"github.com/BurntSushi/xgbutil/ewmh"
@@ -49,6 +50,7 @@ func (m *DockManager) unregisterWindow(win xproto.Window) {
 	m.windowInfoMapMutex.Lock()
 	delete(m.windowInfoMap, win)
 	m.windowInfoMapMutex.Unlock()
+	m.thumbnailManager.remove(win)
 }
 
 func (m *DockManager) handleClientListChanged() {
@@ -72,6 +74,7 @@ func (m *DockManager) handleClientListChanged() {
 		logger.Debug("client list remove:", remove)
 	}
 	m.clientList = newClientList
+	m.updateHideStateWithDelay()
 }
 
 func (m *DockManager) handleActiveWindowChanged() {
@@ -87,10 +90,12 @@ func (m *DockManager) handleActiveWindowChanged() {
 
 	logger.Debug("Active window changed window:", activeWindow)
 	m.activeWindow = activeWindow
+	m.activeSerial++
 
 	for _, entry := range m.Entries {
 		winInfo, ok := entry.windows[activeWindow]
 		if ok {
+			winInfo.activeSerial = m.activeSerial
 			entry.setIsActive(true)
 			entry.setCurrentWindowInfo(winInfo)
 			entry.current.updateWmName()
@@ -114,6 +119,9 @@ func (m *DockManager) listenRootWindowPropertyChange() {
 			m.handleActiveWindowChanged()
 		case _NET_SHOWING_DESKTOP:
 			m.updateHideStateWithoutDelay()
+		case _NET_CURRENT_DESKTOP:
+			m.updateWindowFilter()
+			m.updateHideStateWithDelay()
 		}
 	}).Connect(XU, rootWin)
 
@@ -126,6 +134,66 @@ func (m *DockManager) listenRootWindowPropertyChange() {
 
 	m.handleActiveWindowChanged()
 	m.handleClientListChanged()
+	m.listenTasks()
+}
+
+const tasksQueueSize = 16
+
+// listenTasks creates the window the tasks are sent to, the tasks are run by
+// the client message handler of the window.
+func (m *DockManager) listenTasks() {
+	win, err := xwindow.Generate(XU)
+	if err != nil {
+		logger.Warning("generate tasks window failed:", err)
+		return
+	}
+	err = xproto.CreateWindowChecked(XU.Conn(), 0, win.Id, XU.RootWin(), 0, 0, 1, 1, 0,
+		xproto.WindowClassInputOnly, 0, 0, nil).Check()
+	if err != nil {
+		logger.Warning("create tasks window failed:", err)
+		return
+	}
+	m.tasks = make(chan func(), tasksQueueSize)
+	xevent.ClientMessageFun(func(XU *xgbutil.XUtil, ev xevent.ClientMessageEvent) {
+		if ev.Type != ATOM_DOCK_RUN_TASKS {
+			return
+		}
+		for {
+			select {
+			case task := <-m.tasks:
+				task()
+			default:
+				return
+			}
+		}
+	}).Connect(XU, win.Id)
+	m.tasksWindow = win.Id
+}
+
+// runInXEventLoop runs fn in the X event loop, where the X event handlers
+// change the entries, and waits for it to return. It must not be called by
+// the X event handlers.
+func (m *DockManager) runInXEventLoop(fn func()) error {
+	if m.tasksWindow == 0 {
+		return errors.New("X event loop is not listened")
+	}
+	cm, err := xevent.NewClientMessage(32, m.tasksWindow, ATOM_DOCK_RUN_TASKS)
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	m.tasks <- func() {
+		defer close(done)
+		fn()
+	}
+	// the event is sent to the client created the window if no event mask
+	// is given
+	err = xproto.SendEventChecked(XU.Conn(), false, m.tasksWindow, 0, string(cm.Bytes())).Check()
+	if err != nil {
+		return err
+	}
+	<-done
+	return nil
 }
 
 func (m *DockManager) listenWindowXEvent(winInfo *WindowInfo) {
@@ -167,7 +235,7 @@ func (m *DockManager) handleVisibilityNotifyEvent(winInfo *WindowInfo, ev xevent
 }
 
 func (m *DockManager) handleConfigureNotifyEvent(winInfo *WindowInfo, ev xevent.ConfigureNotifyEvent) {
-	if HideModeType(m.HideMode.Get()) != HideModeSmartHide {
+	if !m.isWindowHideStateGeometryDependent(winInfo) && !m.isFilterWindowsByMonitor() {
 		return
 	}
 
@@ -181,6 +249,7 @@ func (m *DockManager) handleConfigureNotifyEvent(winInfo *WindowInfo, ev xevent.
 			ev := winInfo.lastConfigureNotifyEvent
 			logger.Debugf("in closure: configure notify ev %s", ev)
 			isXYWHChange := false
+			winInfo.mutex.Lock()
 			if winInfo.x != ev.X {
 				winInfo.x = ev.X
 				isXYWHChange = true
@@ -200,7 +269,19 @@ func (m *DockManager) handleConfigureNotifyEvent(winInfo *WindowInfo, ev xevent.
 				winInfo.height = ev.Height
 				isXYWHChange = true
 			}
+			winInfo.mutex.Unlock()
 			logger.Debug("isXYWHChange", isXYWHChange)
+			if isXYWHChange && m.isFilterWindowsByMonitor() {
+				winInfo.updateGeometry()
+				if entry := winInfo.entry; entry != nil {
+					entry.windowMutex.Lock()
+					entry.updateWindowsByFilter()
+					entry.windowMutex.Unlock()
+				}
+			}
+			if !m.isWindowHideStateGeometryDependent(winInfo) {
+				return
+			}
 			if isXYWHChange {
 				m.updateHideStateWithoutDelay()
 			} else {
@@ -0,0 +1,88 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const fileWatcherDelay = time.Second
+
+// fileWatcher calls handler once the events of the watched files settle
+// for fileWatcherDelay, programs often write files in several steps.
+type fileWatcher struct {
+	name    string
+	watcher *fsnotify.Watcher
+	timer   *time.Timer
+	// filter tells whether the event should trigger handler.
+	filter func(w *fileWatcher, ev fsnotify.Event) bool
+}
+
+func newFileWatcher(name string, filter func(w *fileWatcher, ev fsnotify.Event) bool,
+	handler func()) (*fileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &fileWatcher{
+		name:    name,
+		watcher: watcher,
+		timer:   time.AfterFunc(fileWatcherDelay, handler),
+		filter:  filter,
+	}
+	w.timer.Stop()
+	return w, nil
+}
+
+// start handles the events, call it after the files are added.
+func (w *fileWatcher) start() {
+	go w.handleEvents()
+}
+
+func (w *fileWatcher) add(name string) error {
+	return w.watcher.Add(name)
+}
+
+func (w *fileWatcher) remove(name string) error {
+	return w.watcher.Remove(name)
+}
+
+// trigger calls handler after fileWatcherDelay, the pending call is
+// delayed again.
+func (w *fileWatcher) trigger() {
+	w.timer.Reset(fileWatcherDelay)
+}
+
+func (w *fileWatcher) handleEvents() {
+	for {
+		select {
+		case ev, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			logger.Debugf("%s event: %v", w.name, ev)
+			if w.filter == nil || w.filter(w, ev) {
+				w.trigger()
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warningf("%s watcher error: %v", w.name, err)
+		}
+	}
+}
+
+func (w *fileWatcher) close() {
+	w.watcher.Close()
+	w.timer.Stop()
+}
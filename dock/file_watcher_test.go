@@ -0,0 +1,52 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"github.com/fsnotify/fsnotify"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_fileWatcher(t *testing.T) {
+	Convey("fileWatcher calls the handler once the events settle", t, func() {
+		dir, err := ioutil.TempDir("", "dock-file-watcher")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		var calls int32
+		w, err := newFileWatcher("test", func(w *fileWatcher, ev fsnotify.Event) bool {
+			return filepath.Base(ev.Name) == "watched"
+		}, func() {
+			atomic.AddInt32(&calls, 1)
+		})
+		So(err, ShouldBeNil)
+		defer w.close()
+		So(w.add(dir), ShouldBeNil)
+		w.start()
+
+		// written in several steps
+		file := filepath.Join(dir, "watched")
+		for i := 0; i < 3; i++ {
+			So(ioutil.WriteFile(file, []byte("data"), 0644), ShouldBeNil)
+			time.Sleep(100 * time.Millisecond)
+		}
+		// filtered out
+		So(ioutil.WriteFile(filepath.Join(dir, "other"), nil, 0644), ShouldBeNil)
+
+		time.Sleep(fileWatcherDelay + 500*time.Millisecond)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,320 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type GroupPolicyType int32
+
+const (
+	GroupPolicyAlways GroupPolicyType = iota
+	GroupPolicyNever
+	GroupPolicyThreshold
+)
+
+func (t GroupPolicyType) String() string {
+	switch t {
+	case GroupPolicyAlways:
+		return "always"
+	case GroupPolicyNever:
+		return "never"
+	case GroupPolicyThreshold:
+		return "threshold"
+	default:
+		return "unknown"
+	}
+}
+
+type groupPolicy struct {
+	policy GroupPolicyType
+	// windows are grouped only when there are more than threshold windows
+	threshold uint
+}
+
+func (p groupPolicy) shouldGroup(numWindows int) bool {
+	switch p.policy {
+	case GroupPolicyNever:
+		return false
+	case GroupPolicyThreshold:
+		return numWindows > int(p.threshold)
+	default:
+		return true
+	}
+}
+
+// String returns the value used in the overrides list:
+// "always", "never" or the threshold number.
+func (p groupPolicy) String() string {
+	if p.policy == GroupPolicyThreshold {
+		return strconv.FormatUint(uint64(p.threshold), 10)
+	}
+	return p.policy.String()
+}
+
+func parseGroupPolicy(value string) (groupPolicy, error) {
+	switch value {
+	case "always":
+		return groupPolicy{policy: GroupPolicyAlways}, nil
+	case "never":
+		return groupPolicy{policy: GroupPolicyNever}, nil
+	}
+	threshold, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return groupPolicy{}, fmt.Errorf("invalid group policy %q", value)
+	}
+	return groupPolicy{policy: GroupPolicyThreshold, threshold: uint(threshold)}, nil
+}
+
+// overrides item format: <app id>=<policy>
+func parseGroupPolicyOverrides(items []string) map[string]groupPolicy {
+	overrides := make(map[string]groupPolicy)
+	for _, item := range items {
+		idx := strings.LastIndex(item, "=")
+		if idx <= 0 {
+			logger.Warningf("invalid group policy override %q", item)
+			continue
+		}
+		policy, err := parseGroupPolicy(item[idx+1:])
+		if err != nil {
+			logger.Warning(err)
+			continue
+		}
+		overrides[item[:idx]] = policy
+	}
+	return overrides
+}
+
+func (entry *AppEntry) getGroupPolicyKey() string {
+	if entry.appInfo != nil {
+		return entry.appInfo.GetId()
+	}
+	return entry.innerId
+}
+
+func (m *DockManager) loadGroupPolicyOverrides() {
+	overrides := parseGroupPolicyOverrides(m.GroupPolicyOverrides.Get())
+	m.groupPolicyMutex.Lock()
+	m.groupPolicyOverrides = overrides
+	m.groupPolicyMutex.Unlock()
+}
+
+func (m *DockManager) getGroupPolicy(entry *AppEntry) groupPolicy {
+	m.groupPolicyMutex.RLock()
+	policy, ok := m.groupPolicyOverrides[entry.getGroupPolicyKey()]
+	m.groupPolicyMutex.RUnlock()
+	if ok {
+		return policy
+	}
+	return groupPolicy{
+		policy:    GroupPolicyType(m.GroupPolicy.Get()),
+		threshold: uint(m.GroupThreshold.Get()),
+	}
+}
+
+// SetAppGroupPolicy overrides the group policy of app appId, policy is
+// "always", "never" or the window count threshold. An empty policy removes
+// the override.
+func (m *DockManager) SetAppGroupPolicy(appId, policy string) error {
+	if appId == "" || strings.Contains(appId, "=") {
+		return errors.New("invalid app id")
+	}
+
+	var value string
+	if policy != "" {
+		p, err := parseGroupPolicy(policy)
+		if err != nil {
+			return err
+		}
+		value = appId + "=" + p.String()
+	}
+
+	var items []string
+	for _, item := range m.GroupPolicyOverrides.Get() {
+		if strings.HasPrefix(item, appId+"=") {
+			continue
+		}
+		items = append(items, item)
+	}
+	if value != "" {
+		items = append(items, value)
+	}
+	m.GroupPolicyOverrides.Set(items)
+	return nil
+}
+
+func (m *DockManager) getEntriesByInnerId(innerId string) AppEntries {
+	var entries AppEntries
+	for _, entry := range m.Entries {
+		if entry.innerId == innerId {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// getEntryForWindow returns the entry winInfo should be attached to.
+func (m *DockManager) getEntryForWindow(winInfo *WindowInfo) (*AppEntry, bool) {
+	entries := m.getEntriesByInnerId(winInfo.entryInnerId)
+	if len(entries) == 0 {
+		return m.addAppEntry(winInfo.entryInnerId, winInfo.appInfo, -1)
+	}
+
+	numWindows := 1
+	for _, entry := range entries {
+		numWindows += len(entry.windows)
+	}
+	if m.getGroupPolicy(entries[0]).shouldGroup(numWindows) {
+		return entries.GetFirstByInnerId(winInfo.entryInnerId), false
+	}
+
+	// one entry per window, reuse the docked entry without window
+	for _, entry := range entries {
+		if !entry.hasWindow() {
+			return entry, false
+		}
+	}
+	entry := newAppEntry(m, winInfo.entryInnerId, winInfo.appInfo)
+	index := m.Entries.IndexOf(entries[len(entries)-1]) + 1
+	m.Entries = m.Entries.Insert(entry, index)
+	return entry, true
+}
+
+func (m *DockManager) regroupAllEntries() {
+	var innerIds []string
+	for _, entry := range m.Entries {
+		if !strSliceContains(innerIds, entry.innerId) {
+			innerIds = append(innerIds, entry.innerId)
+		}
+	}
+	for _, innerId := range innerIds {
+		m.regroupEntries(innerId)
+	}
+}
+
+// regroupEntries merges or splits the entries with the same inner id
+// according to the group policy.
+func (m *DockManager) regroupEntries(innerId string) {
+	entries := m.getEntriesByInnerId(innerId)
+	if len(entries) == 0 {
+		return
+	}
+
+	numWindows := 0
+	for _, entry := range entries {
+		numWindows += len(entry.windows)
+	}
+
+	if m.getGroupPolicy(entries[0]).shouldGroup(numWindows) {
+		m.mergeEntries(entries)
+	} else {
+		m.splitEntries(entries)
+	}
+}
+
+func (m *DockManager) mergeEntries(entries AppEntries) {
+	if len(entries) < 2 {
+		return
+	}
+
+	// keep the leader, the docked entry if any
+	target := entries.GetFirstByInnerId(entries[0].innerId)
+	logger.Debugf("merge %d entries into %v", len(entries), target.Id)
+
+	for _, entry := range entries {
+		if entry == target {
+			continue
+		}
+		entry.windowMutex.Lock()
+		windows := entry.getWindowsMRU()
+		for _, winInfo := range windows {
+			entry.detachWindow(winInfo)
+		}
+		entry.windowMutex.Unlock()
+
+		for _, winInfo := range windows {
+			m.moveWindowToEntry(winInfo, target)
+		}
+
+		if entry.IsDocked {
+			entry.windowMutex.Lock()
+			entry.updateWindowTitles()
+			entry.updateIcon()
+			entry.updateMenu()
+			entry.updateIsActive()
+			entry.windowMutex.Unlock()
+		} else {
+			m.removeAppEntry(entry)
+		}
+	}
+}
+
+func (m *DockManager) splitEntries(entries AppEntries) {
+	// entries without window can take a window, only docked ones exist
+	var empty AppEntries
+	for _, entry := range entries {
+		if !entry.hasWindow() {
+			empty = append(empty, entry)
+		}
+	}
+
+	for _, entry := range entries {
+		entry.windowMutex.Lock()
+		windows := entry.getWindowsMRU()
+		keep := entry.current
+		if keep == nil && len(windows) > 0 {
+			keep = windows[0]
+		}
+		var extra []*WindowInfo
+		for _, winInfo := range windows {
+			if winInfo != keep {
+				extra = append(extra, winInfo)
+			}
+		}
+		for _, winInfo := range extra {
+			entry.detachWindow(winInfo)
+		}
+		if len(extra) > 0 {
+			entry.updateWindowTitles()
+			entry.updateIcon()
+			entry.updateMenu()
+			entry.updateIsActive()
+		}
+		entry.windowMutex.Unlock()
+
+		for _, winInfo := range extra {
+			if len(empty) > 0 {
+				m.moveWindowToEntry(winInfo, empty[0])
+				empty = empty[1:]
+				continue
+			}
+
+			newEntry := newAppEntry(m, entry.innerId, entry.appInfo)
+			index := m.Entries.IndexOf(entry) + 1
+			m.Entries = m.Entries.Insert(newEntry, index)
+			m.moveWindowToEntry(winInfo, newEntry)
+			newEntry.updateName()
+			m.installAppEntry(newEntry)
+		}
+	}
+}
+
+func (m *DockManager) moveWindowToEntry(winInfo *WindowInfo, entry *AppEntry) {
+	entry.windowMutex.Lock()
+	defer entry.windowMutex.Unlock()
+
+	entry.attachWindow(winInfo)
+	entry.updateIcon()
+	entry.updateMenu()
+}
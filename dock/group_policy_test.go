@@ -0,0 +1,100 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func Test_groupPolicy(t *testing.T) {
+	Convey("groupPolicy", t, func() {
+		policy, err := parseGroupPolicy("never")
+		So(err, ShouldBeNil)
+		So(policy.shouldGroup(1), ShouldBeFalse)
+		So(policy.shouldGroup(10), ShouldBeFalse)
+
+		policy, err = parseGroupPolicy("always")
+		So(err, ShouldBeNil)
+		So(policy.shouldGroup(1), ShouldBeTrue)
+
+		policy, err = parseGroupPolicy("2")
+		So(err, ShouldBeNil)
+		So(policy.policy, ShouldEqual, GroupPolicyThreshold)
+		So(policy.shouldGroup(2), ShouldBeFalse)
+		So(policy.shouldGroup(3), ShouldBeTrue)
+		So(policy.String(), ShouldEqual, "2")
+
+		_, err = parseGroupPolicy("sometimes")
+		So(err, ShouldNotBeNil)
+
+		overrides := parseGroupPolicyOverrides([]string{
+			"deepin-terminal=never",
+			"google-chrome=3",
+			"invalid",
+			"gedit=sometimes",
+		})
+		So(len(overrides), ShouldEqual, 2)
+		So(overrides["deepin-terminal"].policy, ShouldEqual, GroupPolicyNever)
+		So(overrides["google-chrome"].threshold, ShouldEqual, 3)
+	})
+}
+
+func Test_findNextLeader(t *testing.T) {
+	Convey("AppEntry.findNextLeader cycles in MRU order", t, func() {
+		entry := &AppEntry{
+			windows: map[xproto.Window]*WindowInfo{
+				1: {window: 1, activeSerial: 5},
+				2: {window: 2, activeSerial: 9},
+				3: {window: 3, activeSerial: 7},
+			},
+		}
+		windows := entry.getWindowsMRU()
+		So(windows[0].window, ShouldEqual, 2)
+		So(windows[1].window, ShouldEqual, 3)
+		So(windows[2].window, ShouldEqual, 1)
+
+		activate := func(win xproto.Window) {
+			entry.current = entry.windows[win]
+			entry.current.activeSerial = 10 + uint64(win)
+		}
+
+		entry.current = entry.windows[2]
+		next := entry.findNextLeader()
+		So(next, ShouldEqual, 3)
+		activate(next)
+		next = entry.findNextLeader()
+		So(next, ShouldEqual, 1)
+		activate(next)
+		So(entry.findNextLeader(), ShouldEqual, 2)
+
+		// activated by other means, restart from the MRU order
+		activate(3)
+		So(entry.findNextLeader(), ShouldEqual, 1)
+	})
+}
+
+func Test_GetFirstByInnerId(t *testing.T) {
+	Convey("AppEntries.GetFirstByInnerId returns the group leader", t, func() {
+		a1 := &AppEntry{Id: "e1", innerId: "a"}
+		b1 := &AppEntry{Id: "e2", innerId: "b"}
+		a2 := &AppEntry{Id: "e3", innerId: "a", IsDocked: true}
+		a3 := &AppEntry{Id: "e4", innerId: "a"}
+		entries := AppEntries{a1, b1, a2, a3}
+
+		So(entries.GetFirstByInnerId("a"), ShouldEqual, a2)
+		So(entries.GetFirstByInnerId("b"), ShouldEqual, b1)
+		So(entries.GetFirstByInnerId("c"), ShouldBeNil)
+
+		a2.IsDocked = false
+		So(entries.GetFirstByInnerId("a"), ShouldEqual, a1)
+	})
+}
@@ -8,6 +8,9 @@ import (
 type IdentifyWindowFunc struct {
 	Name string
 	Fn   _IdentifyWindowFunc
+	// Peek is the variant of Fn without side effects, used to explain the
+	// identification. Fn is used if Peek is nil.
+	Peek _IdentifyWindowFunc
 }
 
 type _IdentifyWindowFunc func(*DockManager, *WindowInfo) (string, *AppInfo)
@@ -17,16 +20,25 @@ func (m *DockManager) registerIdentifyWindowFuncs() {
 	m.registerIdentifyWindowFunc("Rule", identifyWindowByRule)
 	m.registerIdentifyWindowFunc("Bamf", identifyWindowByBamf)
 	m.registerIdentifyWindowFunc("Pid", identifyWindowByPid)
-	m.registerIdentifyWindowFunc("Cache", identifyWindowByCache)
+	m.registerIdentifyWindowFunc("Cache", identifyWindowByCache).Peek = peekWindowByCache
 	m.registerIdentifyWindowFunc("GtkAppId", identifyWindowByGtkAppId)
 	m.registerIdentifyWindowFunc("WmClass", identifyWindowByWmClass)
 }
 
-func (m *DockManager) registerIdentifyWindowFunc(name string, fn _IdentifyWindowFunc) {
-	m.identifyWindowFuns = append(m.identifyWindowFuns, &IdentifyWindowFunc{
+func (m *DockManager) registerIdentifyWindowFunc(name string, fn _IdentifyWindowFunc) *IdentifyWindowFunc {
+	item := &IdentifyWindowFunc{
 		Name: name,
 		Fn:   fn,
-	})
+	}
+	m.identifyWindowFuns = append(m.identifyWindowFuns, item)
+	return item
+}
+
+func (item *IdentifyWindowFunc) peek(m *DockManager, winInfo *WindowInfo) (string, *AppInfo) {
+	if item.Peek != nil {
+		return item.Peek(m, winInfo)
+	}
+	return item.Fn(m, winInfo)
 }
 
 func (m *DockManager) identifyWindow(winInfo *WindowInfo) (string, *AppInfo) {
@@ -43,34 +55,55 @@ func (m *DockManager) identifyWindow(winInfo *WindowInfo) (string, *AppInfo) {
 		if innerId != "" {
 			// success
 			logger.Debugf("identifyWindow by %s success, innerId: %q, appInfo: %v", name, innerId, appInfo)
+			winInfo.mutex.Lock()
+			winInfo.identifyMethod = name
+			winInfo.mutex.Unlock()
 			return innerId, appInfo
 		}
 	}
 	// fail
 	logger.Debugf("identifyWindow: failed")
+	winInfo.mutex.Lock()
+	winInfo.identifyMethod = ""
+	winInfo.mutex.Unlock()
 	return winInfo.innerId, nil
 }
 
-func identifyWindowByCache(m *DockManager, winInfo *WindowInfo) (string, *AppInfo) {
+func lookupWindowCache(m *DockManager, winInfo *WindowInfo) (string, *AppInfo) {
 	desktopHash := m.desktopWindowsMapCacheManager.GetKeyByValue(winInfo.innerId)
 	logger.Debugf("identifyWindowByCache: desktop hash: %q", desktopHash)
-	var appInfo *AppInfo
+	if desktopHash == "" {
+		return "", nil
+	}
+	return desktopHash, m.desktopHashFileMapCacheManager.GetAppInfo(desktopHash)
+}
+
+func identifyWindowByCache(m *DockManager, winInfo *WindowInfo) (string, *AppInfo) {
+	desktopHash, appInfo := lookupWindowCache(m, winInfo)
+	if appInfo != nil {
+		// success
+		return appInfo.innerId, appInfo
+	}
 	if desktopHash != "" {
-		appInfo = m.desktopHashFileMapCacheManager.GetAppInfo(desktopHash)
-		if appInfo != nil {
-			// success
-			return appInfo.innerId, appInfo
-		} else {
-			// cache fail
-			logger.Debug("identifyWindowByCache: cache fail")
-			m.desktopHashFileMapCacheManager.DeleteKey(desktopHash)
-			m.desktopWindowsMapCacheManager.DeleteKeyValue(desktopHash, winInfo.innerId)
-		}
+		// cache fail
+		logger.Debug("identifyWindowByCache: cache fail")
+		m.desktopHashFileMapCacheManager.DeleteKey(desktopHash)
+		m.desktopWindowsMapCacheManager.DeleteKeyValue(desktopHash, winInfo.innerId)
 	}
 	// fail
 	return "", nil
 }
 
+// peekWindowByCache is identifyWindowByCache without removing the invalid
+// cache entries.
+func peekWindowByCache(m *DockManager, winInfo *WindowInfo) (string, *AppInfo) {
+	_, appInfo := lookupWindowCache(m, winInfo)
+	if appInfo != nil {
+		return appInfo.innerId, appInfo
+	}
+	return "", nil
+}
+
 func identifyWindowByPid(m *DockManager, winInfo *WindowInfo) (string, *AppInfo) {
 	if winInfo.pid != 0 {
 		logger.Debugf("identifyWindowByPid: pid: %d", winInfo.pid)
@@ -122,7 +155,7 @@ func identifyWindowByPidEnv(m *DockManager, winInfo *WindowInfo) (string, *AppIn
 }
 
 func identifyWindowByRule(m *DockManager, winInfo *WindowInfo) (string, *AppInfo) {
-	ret := m.windowPatterns.Match(winInfo)
+	ret := m.getWindowPatterns().Match(winInfo)
 	if ret == "" {
 		return "", nil
 	}
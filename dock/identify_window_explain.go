@@ -0,0 +1,184 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/icccm"
+	"pkg.deepin.io/lib/procfs"
+)
+
+// windowProps are the synthetic window properties for
+// ExplainIdentifyWindowProps.
+type windowProps struct {
+	WmClass    string
+	WmInstance string
+	WmName     string
+	WmRole     string
+	GtkAppId   string
+	Pid        uint32
+	Exe        string
+	Args       []string
+	// Environ is a list of KEY=VALUE
+	Environ []string
+}
+
+type identifyExplanation struct {
+	InnerId     string
+	AppId       string
+	DesktopFile string
+	// Method is the name of the IdentifyWindowFunc which identified the
+	// window, empty if none did.
+	Method string
+	// Tried lists the names of the IdentifyWindowFuncs tried in order.
+	Tried []string
+	// Rule is the matched window pattern, set if the method is Rule.
+	Rule *ruleExplanation `json:",omitempty"`
+}
+
+type ruleExplanation struct {
+	File    string
+	Index   int
+	Result  string
+	Matches []string
+}
+
+func newWindowInfoWithProps(props *windowProps) *WindowInfo {
+	winInfo := NewWindowInfo(0)
+	winInfo.wmName = props.WmName
+	winInfo.wmRole = props.WmRole
+	winInfo.gtkAppId = props.GtkAppId
+	winInfo.pid = uint(props.Pid)
+	if props.WmClass != "" || props.WmInstance != "" {
+		winInfo.wmClass = &icccm.WmClass{
+			Class:    props.WmClass,
+			Instance: props.WmInstance,
+		}
+	}
+	if props.Exe != "" {
+		winInfo.process = &ProcessInfo{
+			cmdline: append([]string{props.Exe}, props.Args...),
+			args:    props.Args,
+			exe:     props.Exe,
+			environ: procfs.EnvVars(props.Environ),
+			hasPid:  props.Pid != 0,
+		}
+	}
+	winInfo.genInnerId()
+	return winInfo
+}
+
+// snapshot copies the fields used to identify the window under the lock.
+// The slices and pointers are shared, they are replaced, not modified, by
+// the updates.
+func (winInfo *WindowInfo) snapshot() *WindowInfo {
+	winInfo.mutex.RLock()
+	defer winInfo.mutex.RUnlock()
+	return &WindowInfo{
+		innerId:           winInfo.innerId,
+		window:            winInfo.window,
+		Title:             winInfo.Title,
+		wmState:           winInfo.wmState,
+		wmWindowType:      winInfo.wmWindowType,
+		wmAllowedActions:  winInfo.wmAllowedActions,
+		hasXEmbedInfo:     winInfo.hasXEmbedInfo,
+		hasWmTransientFor: winInfo.hasWmTransientFor,
+		mapState:          winInfo.mapState,
+		wmClass:           winInfo.wmClass,
+		wmName:            winInfo.wmName,
+		gtkAppId:          winInfo.gtkAppId,
+		wmRole:            winInfo.wmRole,
+		pid:               winInfo.pid,
+		process:           winInfo.process,
+		entryInnerId:      winInfo.entryInnerId,
+		appInfo:           winInfo.appInfo,
+		identifyMethod:    winInfo.identifyMethod,
+	}
+}
+
+// explainIdentifyWindow tries the identification methods like
+// identifyWindow, but without side effects on the window or the caches.
+func (m *DockManager) explainIdentifyWindow(winInfo *WindowInfo) *identifyExplanation {
+	result := &identifyExplanation{}
+	for _, item := range m.identifyWindowFuns {
+		result.Tried = append(result.Tried, item.Name)
+		innerId, appInfo := item.peek(m, winInfo)
+		if innerId == "" {
+			continue
+		}
+
+		result.InnerId = innerId
+		result.Method = item.Name
+		if appInfo != nil {
+			result.AppId = appInfo.GetId()
+			result.DesktopFile = appInfo.GetFileName()
+		}
+		if item.Name == "Rule" {
+			result.Rule = explainRule(m.getWindowPatterns().MatchPattern(winInfo), winInfo)
+		}
+		return result
+	}
+
+	result.InnerId = winInfo.innerId
+	return result
+}
+
+func explainRule(pattern *WindowPattern, winInfo *WindowInfo) *ruleExplanation {
+	if pattern == nil {
+		return nil
+	}
+	rule := &ruleExplanation{
+		File:   pattern.source,
+		Index:  pattern.index,
+		Result: pattern.Result,
+	}
+	for _, r := range pattern.ParsedRules {
+		rule.Matches = append(rule.Matches, r.Describe(winInfo))
+	}
+	return rule
+}
+
+func marshalExplanation(result *identifyExplanation) (string, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ExplainIdentifyWindow tells which identification method or window pattern
+// identifies the window, as JSON. It does not change the dock entries.
+func (m *DockManager) ExplainIdentifyWindow(win uint32) (string, error) {
+	m.windowInfoMapMutex.RLock()
+	winInfo, ok := m.windowInfoMap[xproto.Window(win)]
+	m.windowInfoMapMutex.RUnlock()
+	if !ok {
+		winInfo = NewWindowInfo(xproto.Window(win))
+		winInfo.update()
+	}
+	// the identify functions may be slow, they run on a copy so the X
+	// event handlers are not blocked
+	return marshalExplanation(m.explainIdentifyWindow(winInfo.snapshot()))
+}
+
+// ExplainIdentifyWindowProps is like ExplainIdentifyWindow, but with the
+// JSON encoded window properties, with keys WmClass, WmInstance, WmName,
+// WmRole, GtkAppId, Pid, Exe, Args and Environ.
+func (m *DockManager) ExplainIdentifyWindowProps(propsJSON string) (string, error) {
+	var props windowProps
+	err := json.Unmarshal([]byte(propsJSON), &props)
+	if err != nil {
+		return "", fmt.Errorf("invalid window properties: %v", err)
+	}
+	return marshalExplanation(m.explainIdentifyWindow(newWindowInfoWithProps(&props)))
+}
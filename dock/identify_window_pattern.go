@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -17,6 +18,11 @@ type WindowPattern struct {
 	Rules       []WindowRule `json:"rules"`
 	Result      string       `json:"ret"`
 	ParsedRules []*WindowRuleParsed
+
+	// source is the file the pattern loaded from, index is the position in
+	// the file.
+	source string
+	index  int
 }
 
 type WindowRule [2]string
@@ -48,6 +54,8 @@ func loadWindowPatterns(file string) (WindowPatterns, error) {
 	// parse pattterns
 	for i := range patterns {
 		pattern := &patterns[i]
+		pattern.source = file
+		pattern.index = i
 		rules := pattern.Rules
 		// parse rules in pattern
 		pattern.ParsedRules = make([]*WindowRuleParsed, len(rules))
@@ -60,7 +68,41 @@ func loadWindowPatterns(file string) (WindowPatterns, error) {
 	return patterns, nil
 }
 
+// loadLayeredWindowPatterns loads the *.json files in userDir in name order,
+// followed by the system file, so that the user patterns take precedence.
+func loadLayeredWindowPatterns(userDir, systemFile string) (WindowPatterns, error) {
+	files, _ := filepath.Glob(filepath.Join(userDir, "*.json"))
+	sort.Strings(files)
+	files = append(files, systemFile)
+
+	var result WindowPatterns
+	var lastErr error
+	for _, file := range files {
+		patterns, err := loadWindowPatterns(file)
+		if err != nil {
+			logger.Warningf("load window patterns from %q failed: %v", file, err)
+			lastErr = err
+			continue
+		}
+		result = append(result, patterns...)
+	}
+
+	if len(result) == 0 {
+		return nil, lastErr
+	}
+	return result, nil
+}
+
 func (patterns WindowPatterns) Match(winInfo *WindowInfo) string {
+	pattern := patterns.MatchPattern(winInfo)
+	if pattern == nil {
+		return ""
+	}
+	return pattern.Result
+}
+
+// MatchPattern returns the first pattern whose rules all match the window.
+func (patterns WindowPatterns) MatchPattern(winInfo *WindowInfo) *WindowPattern {
 	for i := range patterns {
 		pattern := &patterns[i]
 		rules := pattern.ParsedRules
@@ -80,11 +122,11 @@ func (patterns WindowPatterns) Match(winInfo *WindowInfo) string {
 		if patternOk {
 			// pattern match success
 			logger.Debugf("pattern match success")
-			return pattern.Result
+			return pattern
 		}
 	}
 	// fail
-	return ""
+	return nil
 }
 
 func parseRuleKey(winInfo *WindowInfo, key string) string {
@@ -141,6 +183,12 @@ func (rule *WindowRuleParsed) Match(winInfo *WindowInfo) bool {
 	return result
 }
 
+// Describe tells the rule and the value of the window property it matched
+// against.
+func (rule *WindowRuleParsed) Describe(winInfo *WindowInfo) string {
+	return fmt.Sprintf("%s %q %v", rule.Key, parseRuleKey(winInfo, rule.Key), rule.ValueParsed)
+}
+
 type RuleValueParsed struct {
 	Fn       RuleMatchFunc
 	Type     byte
@@ -0,0 +1,57 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func Test_loadLayeredWindowPatterns(t *testing.T) {
+	Convey("loadLayeredWindowPatterns", t, func() {
+		patterns, err := loadLayeredWindowPatterns("testdata/window_patterns/user",
+			"testdata/window_patterns/system.json")
+		So(err, ShouldBeNil)
+		So(len(patterns), ShouldEqual, 3)
+
+		winInfo := newWindowInfoWithProps(&windowProps{
+			Exe:  "java",
+			Args: []string{"-jar", "tool.jar"},
+		})
+		pattern := patterns.MatchPattern(winInfo)
+		So(pattern, ShouldNotBeNil)
+		So(pattern.Result, ShouldEqual, "id=in-house-tool")
+		So(pattern.source, ShouldEqual, "testdata/window_patterns/user/10-java.json")
+
+		winInfo = newWindowInfoWithProps(&windowProps{
+			Exe:  "java",
+			Args: []string{"-jar", "jftp.jar"},
+		})
+		So(patterns.Match(winInfo), ShouldEqual, "id=jftp")
+
+		winInfo = newWindowInfoWithProps(&windowProps{
+			WmClass:    "DManual",
+			WmInstance: "dman",
+		})
+		pattern = patterns.MatchPattern(winInfo)
+		So(pattern.Result, ShouldEqual, "env")
+		So(pattern.index, ShouldEqual, 1)
+		So(pattern.ParsedRules[0].Describe(winInfo), ShouldEqual, `wmi "dman" equal "dman"`)
+
+		So(patterns.MatchPattern(newWindowInfoWithProps(&windowProps{})), ShouldBeNil)
+	})
+
+	Convey("loadLayeredWindowPatterns without user dir", t, func() {
+		patterns, err := loadLayeredWindowPatterns("testdata/window_patterns/nonexistent",
+			"testdata/window_patterns/system.json")
+		So(err, ShouldBeNil)
+		So(len(patterns), ShouldEqual, 2)
+	})
+}
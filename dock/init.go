@@ -37,11 +37,14 @@ var (
 	_NET_SHOWING_DESKTOP    xproto.Atom
 	_NET_CLIENT_LIST        xproto.Atom
 	_NET_ACTIVE_WINDOW      xproto.Atom
+	_NET_CURRENT_DESKTOP    xproto.Atom
 	ATOM_WINDOW_ICON        xproto.Atom
 	ATOM_WINDOW_NAME        xproto.Atom
 	ATOM_WINDOW_STATE       xproto.Atom
 	ATOM_WINDOW_TYPE        xproto.Atom
+	ATOM_WINDOW_DESKTOP     xproto.Atom
 	ATOM_DOCK_APP_ID        xproto.Atom
+	ATOM_DOCK_RUN_TASKS     xproto.Atom
 	_NET_SYSTEM_TRAY_S0     xproto.Atom
 	_NET_SYSTEM_TRAY_OPCODE xproto.Atom
 	ATOM_XEMBED_INFO        xproto.Atom
@@ -61,11 +64,14 @@ func initAtom() {
 	_NET_SHOWING_DESKTOP, _ = xprop.Atm(XU, "_NET_SHOWING_DESKTOP")
 	_NET_CLIENT_LIST, _ = xprop.Atm(XU, "_NET_CLIENT_LIST")
 	_NET_ACTIVE_WINDOW, _ = xprop.Atm(XU, "_NET_ACTIVE_WINDOW")
+	_NET_CURRENT_DESKTOP, _ = xprop.Atm(XU, "_NET_CURRENT_DESKTOP")
 	ATOM_WINDOW_ICON, _ = xprop.Atm(XU, "_NET_WM_ICON")
 	ATOM_WINDOW_NAME, _ = xprop.Atm(XU, "_NET_WM_NAME")
 	ATOM_WINDOW_STATE, _ = xprop.Atm(XU, "_NET_WM_STATE")
 	ATOM_WINDOW_TYPE, _ = xprop.Atm(XU, "_NET_WM_WINDOW_TYPE")
+	ATOM_WINDOW_DESKTOP, _ = xprop.Atm(XU, "_NET_WM_DESKTOP")
 	ATOM_DOCK_APP_ID, _ = xprop.Atm(XU, "_DDE_DOCK_APP_ID")
+	ATOM_DOCK_RUN_TASKS, _ = xprop.Atm(XU, "_DDE_DOCK_RUN_TASKS")
 
 	ATOM_XEMBED_INFO, _ = xprop.Atm(XU, "_XEMBED_INFO")
 }
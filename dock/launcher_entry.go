@@ -0,0 +1,257 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"errors"
+	"pkg.deepin.io/lib/dbus"
+	"strings"
+)
+
+// Unity LauncherEntry protocol, see
+// https://wiki.ubuntu.com/Unity/LauncherAPI
+const (
+	launcherEntryInterface = "com.canonical.Unity.LauncherEntry"
+	launcherEntryUpdate    = launcherEntryInterface + ".Update"
+
+	launcherEntryMatchRule    = "type='signal',interface='" + launcherEntryInterface + "',member='Update'"
+	nameOwnerChangedRule      = "type='signal',sender='org.freedesktop.DBus',interface='org.freedesktop.DBus',member='NameOwnerChanged'"
+	nameOwnerChangedSignal    = "org.freedesktop.DBus.NameOwnerChanged"
+	launcherEntryAppUriScheme = "application://"
+)
+
+// progress of the entry when the client does not show the progress
+const progressInvisible = -1
+
+type launcherEntryState struct {
+	sender          string
+	count           int64
+	countVisible    bool
+	progress        float64
+	progressVisible bool
+	urgent          bool
+}
+
+// update merges the properties of the Update signal, absent properties keep
+// their values.
+func (s *launcherEntryState) update(props map[string]dbus.Variant) {
+	for key, variant := range props {
+		value := variant.Value()
+		switch key {
+		case "count":
+			if v, ok := value.(int64); ok {
+				s.count = v
+			}
+		case "count-visible":
+			if v, ok := value.(bool); ok {
+				s.countVisible = v
+			}
+		case "progress":
+			if v, ok := value.(float64); ok {
+				s.progress = v
+			}
+		case "progress-visible":
+			if v, ok := value.(bool); ok {
+				s.progressVisible = v
+			}
+		case "urgent":
+			if v, ok := value.(bool); ok {
+				s.urgent = v
+			}
+		}
+	}
+}
+
+func (s *launcherEntryState) getCount() int64 {
+	if s == nil || !s.countVisible {
+		return 0
+	}
+	return s.count
+}
+
+func (s *launcherEntryState) getProgress() float64 {
+	if s == nil || !s.progressVisible {
+		return progressInvisible
+	}
+	if s.progress < 0 {
+		return 0
+	}
+	if s.progress > 1 {
+		return 1
+	}
+	return s.progress
+}
+
+func (s *launcherEntryState) getUrgent() bool {
+	return s != nil && s.urgent
+}
+
+// parseLauncherEntryAppUri returns the app id of app uri like
+// application://firefox.desktop
+func parseLauncherEntryAppUri(appUri string) (string, error) {
+	if !strings.HasPrefix(appUri, launcherEntryAppUriScheme) {
+		return "", errors.New("invalid app uri " + appUri)
+	}
+	appId := strings.TrimSuffix(strings.TrimPrefix(appUri, launcherEntryAppUriScheme), ".desktop")
+	if appId == "" {
+		return "", errors.New("invalid app uri " + appUri)
+	}
+	return appId, nil
+}
+
+func (m *DockManager) listenLauncherEntrySignal() error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range []string{launcherEntryMatchRule, nameOwnerChangedRule} {
+		err = conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule).Store()
+		if err != nil {
+			return err
+		}
+	}
+
+	m.launcherEntryStates = make(map[string]*launcherEntryState)
+	m.launcherEntrySigChan = conn.Signal()
+	go func(ch <-chan *dbus.Signal) {
+		for sig := range ch {
+			switch sig.Name {
+			case launcherEntryUpdate:
+				m.handleLauncherEntryUpdate(sig)
+			case nameOwnerChangedSignal:
+				m.handleLauncherEntryNameOwnerChanged(sig)
+			}
+		}
+	}(m.launcherEntrySigChan)
+	return nil
+}
+
+func (m *DockManager) stopListenLauncherEntrySignal() {
+	if m.launcherEntrySigChan == nil {
+		return
+	}
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return
+	}
+	conn.DetachSignal(m.launcherEntrySigChan)
+	m.launcherEntrySigChan = nil
+	for _, rule := range []string{launcherEntryMatchRule, nameOwnerChangedRule} {
+		conn.BusObject().Call("org.freedesktop.DBus.RemoveMatch", 0, rule)
+	}
+}
+
+func (m *DockManager) handleLauncherEntryUpdate(sig *dbus.Signal) {
+	if len(sig.Body) != 2 {
+		return
+	}
+	appUri, _ := sig.Body[0].(string)
+	props, _ := sig.Body[1].(map[string]dbus.Variant)
+	appId, err := parseLauncherEntryAppUri(appUri)
+	if err != nil {
+		logger.Debug(err)
+		return
+	}
+	logger.Debugf("launcher entry update %q from %s: %v", appId, sig.Sender, props)
+
+	m.launcherEntryMutex.Lock()
+	state, ok := m.launcherEntryStates[appId]
+	if !ok || state.sender != sig.Sender {
+		state = &launcherEntryState{}
+	}
+	state.sender = sig.Sender
+	state.update(props)
+	m.launcherEntryStates[appId] = state
+	m.launcherEntryMutex.Unlock()
+
+	m.updateLauncherEntryOfApp(appId)
+}
+
+func (m *DockManager) handleLauncherEntryNameOwnerChanged(sig *dbus.Signal) {
+	if len(sig.Body) != 3 {
+		return
+	}
+	name, _ := sig.Body[0].(string)
+	newOwner, _ := sig.Body[2].(string)
+	if newOwner != "" || !strings.HasPrefix(name, ":") {
+		return
+	}
+
+	// the client left the bus
+	var appIds []string
+	m.launcherEntryMutex.Lock()
+	for appId, state := range m.launcherEntryStates {
+		if state.sender == name {
+			delete(m.launcherEntryStates, appId)
+			appIds = append(appIds, appId)
+		}
+	}
+	m.launcherEntryMutex.Unlock()
+
+	for _, appId := range appIds {
+		logger.Debugf("launcher entry %q cleared, %s left", appId, name)
+		m.updateLauncherEntryOfApp(appId)
+	}
+}
+
+func (m *DockManager) getLauncherEntryState(entry *AppEntry) *launcherEntryState {
+	if entry.appInfo == nil {
+		return nil
+	}
+	m.launcherEntryMutex.Lock()
+	defer m.launcherEntryMutex.Unlock()
+	for appId, state := range m.launcherEntryStates {
+		if strings.EqualFold(appId, entry.appInfo.GetId()) {
+			copied := *state
+			return &copied
+		}
+	}
+	return nil
+}
+
+func (m *DockManager) updateLauncherEntryOfApp(appId string) {
+	for _, entry := range m.Entries {
+		if entry.appInfo != nil && strings.EqualFold(appId, entry.appInfo.GetId()) {
+			entry.updateLauncherEntry()
+		}
+	}
+}
+
+func (entry *AppEntry) updateLauncherEntry() {
+	var state *launcherEntryState
+	if entry.dockManager != nil {
+		state = entry.dockManager.getLauncherEntryState(entry)
+	}
+	entry.setCount(state.getCount())
+	entry.setProgress(state.getProgress())
+	entry.setUrgent(state.getUrgent())
+}
+
+func (e *AppEntry) setCount(count int64) {
+	if e.Count != count {
+		e.Count = count
+		dbus.NotifyChange(e, "Count")
+	}
+}
+
+func (e *AppEntry) setProgress(progress float64) {
+	if e.Progress != progress {
+		e.Progress = progress
+		dbus.NotifyChange(e, "Progress")
+	}
+}
+
+func (e *AppEntry) setUrgent(urgent bool) {
+	if e.Urgent != urgent {
+		e.Urgent = urgent
+		dbus.NotifyChange(e, "Urgent")
+	}
+}
@@ -0,0 +1,63 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"pkg.deepin.io/lib/dbus"
+	"testing"
+)
+
+func Test_parseLauncherEntryAppUri(t *testing.T) {
+	Convey("parseLauncherEntryAppUri", t, func() {
+		appId, err := parseLauncherEntryAppUri("application://thunderbird.desktop")
+		So(err, ShouldBeNil)
+		So(appId, ShouldEqual, "thunderbird")
+
+		_, err = parseLauncherEntryAppUri("thunderbird.desktop")
+		So(err, ShouldNotBeNil)
+		_, err = parseLauncherEntryAppUri("application://")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func Test_launcherEntryState(t *testing.T) {
+	Convey("launcherEntryState update", t, func() {
+		var nilState *launcherEntryState
+		So(nilState.getCount(), ShouldEqual, 0)
+		So(nilState.getProgress(), ShouldEqual, progressInvisible)
+		So(nilState.getUrgent(), ShouldBeFalse)
+
+		state := &launcherEntryState{}
+		state.update(map[string]dbus.Variant{
+			"count":         dbus.MakeVariant(int64(3)),
+			"count-visible": dbus.MakeVariant(true),
+			"progress":      dbus.MakeVariant(0.5),
+		})
+		So(state.getCount(), ShouldEqual, 3)
+		So(state.getProgress(), ShouldEqual, progressInvisible)
+
+		state.update(map[string]dbus.Variant{
+			"progress-visible": dbus.MakeVariant(true),
+			"urgent":           dbus.MakeVariant(true),
+			"count":            dbus.MakeVariant("wrong type"),
+		})
+		So(state.getCount(), ShouldEqual, 3)
+		So(state.getProgress(), ShouldEqual, 0.5)
+		So(state.getUrgent(), ShouldBeTrue)
+
+		state.update(map[string]dbus.Variant{
+			"progress":      dbus.MakeVariant(1.5),
+			"count-visible": dbus.MakeVariant(false),
+		})
+		So(state.getProgress(), ShouldEqual, 1)
+		So(state.getCount(), ShouldEqual, 0)
+	})
+}
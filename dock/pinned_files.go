@@ -0,0 +1,130 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"pkg.deepin.io/lib/xdg/basedir"
+)
+
+// pinnedFiles maps app id to the uris of the pinned files.
+type pinnedFiles map[string][]string
+
+// getPinnedFilesFile returns the file the pinned files were saved in before
+// the dock layout file.
+func getPinnedFilesFile() string {
+	return filepath.Join(basedir.GetUserConfigDir(), "deepin/dde-daemon/dock/pinned_files.json")
+}
+
+func loadPinnedFiles(file string) (pinnedFiles, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var files pinnedFiles
+	err = json.Unmarshal(content, &files)
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// pin returns false if uri is already pinned.
+func (files pinnedFiles) pin(appId, uri string) bool {
+	if strSliceContains(files[appId], uri) {
+		return false
+	}
+	files[appId] = append(files[appId], uri)
+	return true
+}
+
+// unpin returns false if uri is not pinned.
+func (files pinnedFiles) unpin(appId, uri string) bool {
+	var uris []string
+	for _, v := range files[appId] {
+		if v != uri {
+			uris = append(uris, v)
+		}
+	}
+	if len(uris) == len(files[appId]) {
+		return false
+	}
+	if len(uris) == 0 {
+		delete(files, appId)
+	} else {
+		files[appId] = uris
+	}
+	return true
+}
+
+// loadLegacyPinnedFiles loads the pinned files saved before the dock
+// layout file.
+func loadLegacyPinnedFiles() pinnedFiles {
+	files, err := loadPinnedFiles(getPinnedFilesFile())
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warning("loadPinnedFiles failed:", err)
+	}
+	if files == nil {
+		files = make(pinnedFiles)
+	}
+	return files
+}
+
+func (m *DockManager) setPinnedFiles(files pinnedFiles) {
+	m.recentFilesMutex.Lock()
+	m.pinnedFiles = files
+	m.recentFilesMutex.Unlock()
+}
+
+func (m *DockManager) getPinnedFiles(appId string) []string {
+	m.recentFilesMutex.Lock()
+	defer m.recentFilesMutex.Unlock()
+	uris := m.pinnedFiles[appId]
+	if uris == nil {
+		return nil
+	}
+	// pin appends to the slice, don't share it
+	return append([]string(nil), uris...)
+}
+
+func (m *DockManager) setFilePinned(entry *AppEntry, uri string, pinned bool) error {
+	if entry.appInfo == nil {
+		return errors.New("entry has no app info")
+	}
+	if uri == "" {
+		return errors.New("empty uri")
+	}
+	appId := entry.appInfo.GetId()
+
+	m.recentFilesMutex.Lock()
+	var changed bool
+	if pinned {
+		changed = m.pinnedFiles.pin(appId, uri)
+	} else {
+		changed = m.pinnedFiles.unpin(appId, uri)
+	}
+	m.recentFilesMutex.Unlock()
+
+	if changed {
+		m.saveDockLayout()
+		for _, e := range m.Entries {
+			if e.appInfo != nil && e.appInfo.GetId() == appId {
+				e.windowMutex.Lock()
+				e.updateMenu()
+				e.windowMutex.Unlock()
+			}
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"pkg.deepin.io/lib/procfs"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -120,8 +121,65 @@ func (p *ProcessInfo) getJoinedExeArgs() string {
 }
 
 func (p *ProcessInfo) GetShellScriptLines() string {
-	cmdline := p.getJoinedExeArgs()
-	return fmt.Sprintf("#!/bin/sh\ncd %q\nexec %s\n", p.cwd, cmdline)
+	return p.getShellScript(nil)
+}
+
+var shellVarNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// shellQuote quotes s in single quotes, nothing in it is expanded by the
+// shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// getShellScript returns the script running the process again, environ
+// is exported before that. Env vars whose name is not a valid shell
+// variable name are skipped.
+func (p *ProcessInfo) getShellScript(environ []string) string {
+	var exports string
+	for _, kv := range environ {
+		idx := strings.Index(kv, "=")
+		if idx <= 0 {
+			continue
+		}
+		name := kv[:idx]
+		if !shellVarNameRegexp.MatchString(name) {
+			logger.Debugf("getShellScript: skip env var %q", name)
+			continue
+		}
+		exports += fmt.Sprintf("export %s=%s\n", name, shellQuote(kv[idx+1:]))
+	}
+
+	cmdline := shellQuote(p.exe)
+	for _, arg := range p.args {
+		cmdline += " " + shellQuote(arg)
+	}
+	return fmt.Sprintf("#!/bin/sh\ncd %s\n%sexec %s \"$@\"\n", shellQuote(p.cwd), exports, cmdline)
+}
+
+// env vars bound to the session or to the process instance
+var scratchEnvBlacklist = []string{
+	"_", "PWD", "OLDPWD", "SHLVL", "DISPLAY", "XAUTHORITY", "WINDOWID",
+	"DBUS_SESSION_BUS_ADDRESS", "SESSION_MANAGER", "DESKTOP_STARTUP_ID",
+	"XDG_SESSION_ID", "XDG_SEAT", "XDG_VTNR", "XDG_RUNTIME_DIR",
+	"GIO_LAUNCHED_DESKTOP_FILE", "GIO_LAUNCHED_DESKTOP_FILE_PID",
+	"SSH_AUTH_SOCK", "GPG_AGENT_INFO",
+}
+
+// getRelevantEnviron returns the env vars of environ different from base,
+// the env vars in scratchEnvBlacklist are ignored.
+func getRelevantEnviron(environ, base []string) []string {
+	var result []string
+	for _, kv := range environ {
+		idx := strings.Index(kv, "=")
+		if idx <= 0 || strSliceContains(scratchEnvBlacklist, kv[:idx]) {
+			continue
+		}
+		if !strSliceContains(base, kv) {
+			result = append(result, kv)
+		}
+	}
+	return result
 }
 
 func (p *ProcessInfo) GetOneCommandLine() string {
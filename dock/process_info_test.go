@@ -0,0 +1,64 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func Test_getRelevantEnviron(t *testing.T) {
+	Convey("getRelevantEnviron", t, func() {
+		base := []string{"HOME=/home/user", "LANG=en_US.UTF-8"}
+		environ := []string{
+			"HOME=/home/user",
+			"LANG=zh_CN.UTF-8",
+			"JAVA_HOME=/opt/jdk",
+			"DISPLAY=:0",
+			"PWD=/tmp",
+			"invalid",
+		}
+		So(getRelevantEnviron(environ, base), ShouldResemble,
+			[]string{"LANG=zh_CN.UTF-8", "JAVA_HOME=/opt/jdk"})
+	})
+}
+
+func Test_getShellScript(t *testing.T) {
+	Convey("ProcessInfo getShellScript", t, func() {
+		p := &ProcessInfo{
+			exe:  "/opt/tool/run",
+			args: []string{"--fast"},
+			cwd:  "/opt/tool",
+		}
+		So(p.getShellScript([]string{"JAVA_HOME=/opt/jdk"}), ShouldEqual,
+			"#!/bin/sh\ncd '/opt/tool'\nexport JAVA_HOME='/opt/jdk'\nexec '/opt/tool/run' '--fast' \"$@\"\n")
+		So(p.GetShellScriptLines(), ShouldEqual,
+			"#!/bin/sh\ncd '/opt/tool'\nexec '/opt/tool/run' '--fast' \"$@\"\n")
+	})
+
+	Convey("ProcessInfo getShellScript quotes the values", t, func() {
+		p := &ProcessInfo{
+			exe:  "/opt/it's/run",
+			args: []string{"$(reboot)", "`id`"},
+			cwd:  "/opt/$HOME",
+		}
+		So(p.getShellScript([]string{
+			`GREETING=你好 "world"`,
+			"CMD=$(reboot); echo 'x'",
+			"BAD-NAME=1",
+			"1BAD=1",
+			"A;reboot=1",
+		}), ShouldEqual, "#!/bin/sh\n"+
+			"cd '/opt/$HOME'\n"+
+			"export GREETING='你好 \"world\"'\n"+
+			`export CMD='$(reboot); echo '\''x'\'''`+"\n"+
+			`exec '/opt/it'\''s/run' '$(reboot)' '`+"`id`"+`' "$@"`+"\n")
+	})
+}
@@ -0,0 +1,214 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"encoding/xml"
+	"github.com/fsnotify/fsnotify"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"pkg.deepin.io/lib/appinfo/desktopappinfo"
+	"pkg.deepin.io/lib/xdg/basedir"
+	"sort"
+	"strings"
+	"time"
+)
+
+// max number of recent files in the menu of an entry, pinned files not
+// included.
+const recentFilesLimit = 5
+
+const recentFilesFileName = "recently-used.xbel"
+
+func getRecentFilesFile() string {
+	return filepath.Join(basedir.GetUserDataDir(), recentFilesFileName)
+}
+
+type xbelBookmark struct {
+	Href     string `xml:"href,attr"`
+	Modified string `xml:"modified,attr"`
+	Visited  string `xml:"visited,attr"`
+	MimeType struct {
+		Type string `xml:"type,attr"`
+	} `xml:"info>metadata>mime-type"`
+}
+
+type xbelDocument struct {
+	Bookmarks []xbelBookmark `xml:"bookmark"`
+}
+
+type recentFile struct {
+	Uri      string
+	MimeType string
+	Time     time.Time
+}
+
+// getPath returns the local path of the file, or "" for the non-local file.
+func (f *recentFile) getPath() string {
+	u, err := url.Parse(f.Uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return u.Path
+}
+
+func (f *recentFile) getDisplayName() string {
+	if path := f.getPath(); path != "" {
+		return filepath.Base(path)
+	}
+	return f.Uri
+}
+
+// recentFiles is sorted by time, the most recent first.
+type recentFiles []*recentFile
+
+func (a recentFiles) Len() int           { return len(a) }
+func (a recentFiles) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a recentFiles) Less(i, j int) bool { return a[i].Time.After(a[j].Time) }
+
+func parseXbelTime(bookmark *xbelBookmark) time.Time {
+	var result time.Time
+	for _, value := range []string{bookmark.Modified, bookmark.Visited} {
+		t, err := time.Parse(time.RFC3339, value)
+		if err == nil && t.After(result) {
+			result = t
+		}
+	}
+	return result
+}
+
+func loadRecentFiles(file string) (recentFiles, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc xbelDocument
+	err = xml.Unmarshal(content, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(recentFiles, 0, len(doc.Bookmarks))
+	for i := range doc.Bookmarks {
+		bookmark := &doc.Bookmarks[i]
+		if bookmark.Href == "" {
+			continue
+		}
+		files = append(files, &recentFile{
+			Uri:      bookmark.Href,
+			MimeType: bookmark.MimeType.Type,
+			Time:     parseXbelTime(bookmark),
+		})
+	}
+	sort.Sort(files)
+	return files, nil
+}
+
+func matchMimeType(mimeTypes []string, mimeType string) bool {
+	for _, pattern := range mimeTypes {
+		if pattern == mimeType {
+			return true
+		}
+		// image/*
+		if strings.HasSuffix(pattern, "/*") &&
+			strings.HasPrefix(mimeType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns at most limit files matching mimeTypes, the local files
+// not exist are skipped.
+func (files recentFiles) filter(mimeTypes []string, limit int) recentFiles {
+	var result recentFiles
+	for _, file := range files {
+		if len(result) >= limit {
+			break
+		}
+		if !matchMimeType(mimeTypes, file.MimeType) {
+			continue
+		}
+		if path := file.getPath(); path != "" {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+		}
+		result = append(result, file)
+	}
+	return result
+}
+
+func getAppMimeTypes(ai *AppInfo) []string {
+	value, _ := ai.GetString(desktopappinfo.MainSection, "MimeType")
+	var mimeTypes []string
+	for _, mimeType := range strings.Split(value, ";") {
+		mimeType = strings.TrimSpace(mimeType)
+		if mimeType != "" {
+			mimeTypes = append(mimeTypes, mimeType)
+		}
+	}
+	return mimeTypes
+}
+
+func (m *DockManager) getRecentFiles() recentFiles {
+	m.recentFilesMutex.Lock()
+	defer m.recentFilesMutex.Unlock()
+	return m.recentFiles
+}
+
+func (m *DockManager) loadRecentFiles() {
+	files, err := loadRecentFiles(getRecentFilesFile())
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warning("loadRecentFiles failed:", err)
+	}
+	m.recentFilesMutex.Lock()
+	m.recentFiles = files
+	m.recentFilesMutex.Unlock()
+}
+
+func (m *DockManager) reloadRecentFiles() {
+	m.loadRecentFiles()
+	for _, entry := range m.Entries {
+		if entry.appInfo != nil {
+			entry.windowMutex.Lock()
+			entry.updateMenu()
+			entry.windowMutex.Unlock()
+		}
+	}
+}
+
+func (m *DockManager) watchRecentFiles() {
+	var err error
+	m.recentFilesWatcher, err = newFileWatcher("recent files", func(w *fileWatcher, ev fsnotify.Event) bool {
+		return filepath.Base(ev.Name) == recentFilesFileName
+	}, m.reloadRecentFiles)
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+
+	// the file is replaced when saved, watch the directory
+	err = m.recentFilesWatcher.add(filepath.Dir(getRecentFilesFile()))
+	if err != nil {
+		logger.Warning(err)
+	}
+	m.recentFilesWatcher.start()
+}
+
+func (m *DockManager) stopWatchRecentFiles() {
+	if m.recentFilesWatcher != nil {
+		m.recentFilesWatcher.close()
+		m.recentFilesWatcher = nil
+	}
+}
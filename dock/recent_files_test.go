@@ -0,0 +1,53 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func Test_loadRecentFiles(t *testing.T) {
+	Convey("loadRecentFiles", t, func() {
+		files, err := loadRecentFiles("testdata/recent_files/recently-used.xbel")
+		So(err, ShouldBeNil)
+		So(len(files), ShouldEqual, 3)
+		So(files[0].Uri, ShouldEqual, "http://example.com/notes.txt")
+		So(files[1].Uri, ShouldEqual, "file:///nonexistent/report.txt")
+		So(files[1].getDisplayName(), ShouldEqual, "report.txt")
+
+		// local file not exist is skipped
+		textFiles := files.filter([]string{"text/plain"}, 5)
+		So(len(textFiles), ShouldEqual, 1)
+		So(textFiles[0].MimeType, ShouldEqual, "text/plain")
+
+		imageFiles := files.filter([]string{"image/*"}, 5)
+		So(len(imageFiles), ShouldEqual, 1)
+		So(imageFiles[0].Uri, ShouldEqual, "http://example.com/photo.png")
+
+		So(len(files.filter([]string{"text/plain", "image/png"}, 1)), ShouldEqual, 1)
+	})
+}
+
+func Test_pinnedFiles(t *testing.T) {
+	Convey("pinnedFiles pin and unpin", t, func() {
+		files := make(pinnedFiles)
+		So(files.pin("gedit", "file:///a.txt"), ShouldBeTrue)
+		So(files.pin("gedit", "file:///a.txt"), ShouldBeFalse)
+		So(files.pin("gedit", "file:///b.txt"), ShouldBeTrue)
+		So(files["gedit"], ShouldResemble, []string{"file:///a.txt", "file:///b.txt"})
+
+		So(files.unpin("gedit", "file:///c.txt"), ShouldBeFalse)
+		So(files.unpin("gedit", "file:///a.txt"), ShouldBeTrue)
+		So(files.unpin("gedit", "file:///b.txt"), ShouldBeTrue)
+		_, ok := files["gedit"]
+		So(ok, ShouldBeFalse)
+	})
+}
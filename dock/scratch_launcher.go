@@ -0,0 +1,138 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"github.com/BurntSushi/xgb/xproto"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"pkg.deepin.io/lib/appinfo/desktopappinfo"
+)
+
+const processHashPrefix = "p:"
+
+// createScratchLauncher creates the scratch desktop file running the
+// process again, returns the desktop file.
+func createScratchLauncher(id, name, icon string, process *ProcessInfo) (string, error) {
+	if process.cwd == "" {
+		return "", errors.New("unknown working directory of the process")
+	}
+	err := os.MkdirAll(scratchDir, 0775)
+	if err != nil {
+		return "", err
+	}
+
+	icon = saveScratchIcon(id, icon)
+	environ := getRelevantEnviron(process.environ, os.Environ())
+	scriptFile := filepath.Join(scratchDir, id+".sh")
+	err = ioutil.WriteFile(scriptFile, []byte(process.getShellScript(environ)), 0744)
+	if err != nil {
+		return "", err
+	}
+
+	err = createScratchDesktopFile(id, name, icon, scriptFile+" %U")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(scratchDir, id+desktopExt), nil
+}
+
+func (m *DockManager) getWindowInfoByPid(pid uint) *WindowInfo {
+	m.windowInfoMapMutex.RLock()
+	defer m.windowInfoMapMutex.RUnlock()
+	for _, winInfo := range m.windowInfoMap {
+		if winInfo.pid == pid {
+			return winInfo
+		}
+	}
+	return nil
+}
+
+// CreateScratchLauncherFromPid creates a desktop file running the command
+// line of the process in its working directory and environment, returns
+// the desktop file which can be passed to RequestDock.
+func (m *DockManager) CreateScratchLauncherFromPid(pid uint32) (string, error) {
+	process, err := NewProcessInfo(uint(pid))
+	if err != nil {
+		logger.Warning("CreateScratchLauncherFromPid failed:", err)
+		return "", err
+	}
+
+	name := filepath.Base(process.exe)
+	var icon string
+	if winInfo := m.getWindowInfoByPid(uint(pid)); winInfo != nil {
+		name = winInfo.snapshot().getDisplayName()
+		icon = winInfo.getIcon()
+	}
+
+	hasher := md5.New()
+	hasher.Write([]byte(process.getJoinedExeArgs()))
+	id := "docked:" + processHashPrefix + hex.EncodeToString(hasher.Sum(nil))
+	return createScratchLauncher(id, name, icon, process)
+}
+
+// CreateScratchLauncherFromWindow is like CreateScratchLauncherFromPid, with
+// the process, name and icon of the window.
+func (m *DockManager) CreateScratchLauncherFromWindow(win uint32) (string, error) {
+	m.windowInfoMapMutex.RLock()
+	winInfo, ok := m.windowInfoMap[xproto.Window(win)]
+	m.windowInfoMapMutex.RUnlock()
+	if !ok {
+		return "", errors.New("window not found")
+	}
+	// the fields are changed by the X event handlers
+	snapshot := winInfo.snapshot()
+	if snapshot.process == nil || !snapshot.process.hasPid {
+		return "", errors.New("unknown process of the window")
+	}
+
+	id := "docked:" + snapshot.innerId
+	return createScratchLauncher(id, snapshot.getDisplayName(), winInfo.getIcon(), snapshot.process)
+}
+
+// UpdateScratchLauncher changes the name and icon of the scratch desktop
+// file, icon can be a name, a file or a data uri.
+func (m *DockManager) UpdateScratchLauncher(desktopFile, name, icon string) error {
+	if !isFileInDir(desktopFile, scratchDir) {
+		return errors.New("not a scratch desktop file")
+	}
+	if name == "" {
+		return errors.New("empty name")
+	}
+	ai, err := desktopappinfo.NewDesktopAppInfoFromFile(desktopFile)
+	if err != nil {
+		return err
+	}
+
+	id := trimDesktopExt(filepath.Base(desktopFile))
+	exec, _ := ai.GetString(desktopappinfo.MainSection, "Exec")
+	err = createScratchDesktopFile(id, name, saveScratchIcon(id, icon), exec)
+	if err != nil {
+		return err
+	}
+
+	newAppInfo := NewAppInfoFromFile(desktopFile)
+	for _, entry := range m.Entries {
+		if newAppInfo == nil || entry.appInfo == nil ||
+			entry.appInfo.GetFileName() != desktopFile {
+			continue
+		}
+		entry.setAppInfo(newAppInfo)
+		entry.updateName()
+		entry.updateIcon()
+		entry.updateMenu()
+	}
+	m.saveDockLayout()
+	return nil
+}
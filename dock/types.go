@@ -18,7 +18,7 @@ type HideModeType int32
 const (
 	HideModeKeepShowing HideModeType = iota
 	HideModeKeepHidden
-	HideModeAutoHide // invalid
+	HideModeAutoHide
 	HideModeSmartHide
 )
 
@@ -0,0 +1,119 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xinerama"
+	"github.com/BurntSushi/xgbutil/xrect"
+)
+
+// windows with this workspace are shown on all workspaces.
+const allWorkspaces = 0xFFFFFFFF
+
+// windowFilter decides which windows of an entry are exposed in its
+// WindowTitles, selected as its CurrentWindow and activated by Activate.
+// The entries are always shown, an entry whose windows are all filtered
+// out launches a new window when activated.
+type windowFilter struct {
+	byWorkspace      bool
+	byMonitor        bool
+	currentWorkspace uint
+	// monitor is the rect of the monitor the dock sits on.
+	monitor xrect.Rect
+}
+
+func (f *windowFilter) contains(workspace uint, geometry xrect.Rect) bool {
+	if f.byWorkspace && workspace != allWorkspaces && workspace != f.currentWorkspace {
+		return false
+	}
+
+	if f.byMonitor && f.monitor != nil && geometry != nil {
+		x, y, w, h := geometry.Pieces()
+		if !rectContainsPoint(f.monitor, x+w/2, y+h/2) {
+			return false
+		}
+	}
+	return true
+}
+
+func rectContainsPoint(rect xrect.Rect, x, y int) bool {
+	rx, ry, rw, rh := rect.Pieces()
+	return rx <= x && x < rx+rw && ry <= y && y < ry+rh
+}
+
+// getMonitorOfRect returns the monitor containing the center of the rect.
+func getMonitorOfRect(monitors []xrect.Rect, rect xrect.Rect) xrect.Rect {
+	x, y, w, h := rect.Pieces()
+	for _, monitor := range monitors {
+		if rectContainsPoint(monitor, x+w/2, y+h/2) {
+			return monitor
+		}
+	}
+	return nil
+}
+
+func (m *DockManager) isWindowVisibleByFilter(winInfo *WindowInfo) bool {
+	winInfo.mutex.RLock()
+	workspace := winInfo.workspace
+	geometry := winInfo.geometry
+	winInfo.mutex.RUnlock()
+
+	m.windowFilterMutex.RLock()
+	defer m.windowFilterMutex.RUnlock()
+	return m.windowFilter.contains(workspace, geometry)
+}
+
+func (m *DockManager) isFilterWindowsByMonitor() bool {
+	m.windowFilterMutex.RLock()
+	defer m.windowFilterMutex.RUnlock()
+	return m.windowFilter.byMonitor
+}
+
+// updateWindowFilter is called when the settings, the current workspace or
+// the dock rect changed.
+func (m *DockManager) updateWindowFilter() {
+	filter := windowFilter{
+		byWorkspace: m.FilterWindowsByWorkspace.Get(),
+		byMonitor:   m.FilterWindowsByMonitor.Get(),
+	}
+
+	if filter.byWorkspace {
+		workspace, err := ewmh.CurrentDesktopGet(XU)
+		if err != nil {
+			logger.Warning("get current workspace failed:", err)
+			filter.byWorkspace = false
+		}
+		filter.currentWorkspace = workspace
+	}
+
+	if filter.byMonitor {
+		monitors, err := xinerama.PhysicalHeads(XU)
+		if err != nil {
+			logger.Warning("get monitors failed:", err)
+		} else {
+			filter.monitor = getMonitorOfRect(monitors, m.FrontendWindowRect.ToXRect())
+		}
+	}
+
+	m.windowFilterMutex.Lock()
+	m.windowFilter = filter
+	m.windowFilterMutex.Unlock()
+
+	m.updateEntriesByWindowFilter()
+}
+
+func (m *DockManager) updateEntriesByWindowFilter() {
+	for _, entry := range m.Entries {
+		entry.windowMutex.Lock()
+		entry.updateWindowsByFilter()
+		entry.windowMutex.Unlock()
+	}
+}
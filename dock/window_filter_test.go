@@ -0,0 +1,74 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/xrect"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func Test_windowFilter(t *testing.T) {
+	Convey("windowFilter contains", t, func() {
+		left := xrect.New(0, 0, 1920, 1080)
+		right := xrect.New(1920, 0, 1280, 1024)
+		onLeft := xrect.New(100, 100, 800, 600)
+		onRight := xrect.New(2000, 100, 800, 600)
+
+		var filter windowFilter
+		So(filter.contains(3, onRight), ShouldBeTrue)
+
+		filter = windowFilter{byWorkspace: true, currentWorkspace: 1}
+		So(filter.contains(1, onLeft), ShouldBeTrue)
+		So(filter.contains(0, onLeft), ShouldBeFalse)
+		So(filter.contains(allWorkspaces, onLeft), ShouldBeTrue)
+
+		filter = windowFilter{byMonitor: true, monitor: left}
+		So(filter.contains(0, onLeft), ShouldBeTrue)
+		So(filter.contains(0, onRight), ShouldBeFalse)
+		So(filter.contains(0, nil), ShouldBeTrue)
+
+		monitors := []xrect.Rect{left, right}
+		So(getMonitorOfRect(monitors, xrect.New(1920, 1000, 1280, 24)), ShouldEqual, right)
+		So(getMonitorOfRect(monitors, xrect.New(5000, 0, 10, 10)), ShouldBeNil)
+	})
+}
+
+func Test_entryWindowsByFilter(t *testing.T) {
+	Convey("AppEntry selects the windows passing the filter", t, func() {
+		m := &DockManager{
+			windowFilter: windowFilter{byWorkspace: true, currentWorkspace: 1},
+		}
+		entry := &AppEntry{
+			dockManager: m,
+			windows: map[xproto.Window]*WindowInfo{
+				1: {window: 1, activeSerial: 5, workspace: 1},
+				2: {window: 2, activeSerial: 9, workspace: 2},
+				3: {window: 3, activeSerial: 7, workspace: allWorkspaces},
+			},
+		}
+		windows := entry.getVisibleWindowsMRU()
+		So(len(windows), ShouldEqual, 2)
+		So(windows[0].window, ShouldEqual, 3)
+		So(windows[1].window, ShouldEqual, 1)
+
+		// the filtered out window is skipped when cycling
+		entry.current = entry.windows[3]
+		So(entry.findNextLeader(), ShouldEqual, 1)
+		entry.current = entry.windows[2]
+		So(entry.findNextLeader(), ShouldEqual, 3)
+
+		m.windowFilter.currentWorkspace = 4
+		windows = entry.getVisibleWindowsMRU()
+		So(len(windows), ShouldEqual, 1)
+		So(windows[0].window, ShouldEqual, 3)
+	})
+}
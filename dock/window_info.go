@@ -18,8 +18,10 @@ import (
 	"github.com/BurntSushi/xgbutil/icccm"
 	"github.com/BurntSushi/xgbutil/xevent"
 	"github.com/BurntSushi/xgbutil/xprop"
+	"github.com/BurntSushi/xgbutil/xrect"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 )
@@ -27,6 +29,10 @@ import (
 const windowHashPrefix = "w:"
 
 type WindowInfo struct {
+	// mutex protects the fields written by the X event handlers from the
+	// readers outside of them, such as GetWindowsInfo.
+	mutex sync.RWMutex
+
 	innerId string
 	window  xproto.Window
 	Title   string
@@ -39,6 +45,13 @@ type WindowInfo struct {
 	lastConfigureNotifyEvent *xevent.ConfigureNotifyEvent
 	updateConfigureTimer     *time.Timer
 
+	// geometry is in root window coordinates
+	geometry xrect.Rect
+	// workspace is the _NET_WM_DESKTOP of the window
+	workspace uint
+	// activeSerial increases each time the window becomes active
+	activeSerial uint64
+
 	propertyNotifyTimer     *time.Timer
 	propertyNotifyAtomTable map[xproto.Atom]bool
 	propertyNotifyEnabled   bool
@@ -62,6 +75,9 @@ type WindowInfo struct {
 
 	entryInnerId string
 	appInfo      *AppInfo
+	// identifyMethod is the name of the IdentifyWindowFunc which identified
+	// the window, empty if no one did.
+	identifyMethod string
 }
 
 func NewWindowInfo(win xproto.Window) *WindowInfo {
@@ -74,20 +90,47 @@ func NewWindowInfo(win xproto.Window) *WindowInfo {
 
 // window type
 func (winInfo *WindowInfo) updateWmWindowType() {
-	var err error
-	winInfo.wmWindowType, err = ewmh.WmWindowTypeGet(XU, winInfo.window)
+	wmWindowType, err := ewmh.WmWindowTypeGet(XU, winInfo.window)
+	if err != nil {
+		logger.Debug(err)
+	}
+	winInfo.mutex.Lock()
+	winInfo.wmWindowType = wmWindowType
+	winInfo.mutex.Unlock()
+}
+
+// workspace
+func (winInfo *WindowInfo) updateWorkspace() {
+	workspace, err := ewmh.WmDesktopGet(XU, winInfo.window)
 	if err != nil {
 		logger.Debug(err)
+		workspace = allWorkspaces
 	}
+	winInfo.mutex.Lock()
+	winInfo.workspace = workspace
+	winInfo.mutex.Unlock()
+}
+
+func (winInfo *WindowInfo) updateGeometry() {
+	geometry, err := getWindowGeometry(XU, winInfo.window)
+	if err != nil {
+		logger.Debug(err)
+		return
+	}
+	winInfo.mutex.Lock()
+	winInfo.geometry = geometry
+	winInfo.mutex.Unlock()
 }
 
 // wm allowed actions
 func (winInfo *WindowInfo) updateWmAllowedActions() {
-	var err error
-	winInfo.wmAllowedActions, err = ewmh.WmAllowedActionsGet(XU, winInfo.window)
+	wmAllowedActions, err := ewmh.WmAllowedActionsGet(XU, winInfo.window)
 	if err != nil {
 		logger.Debug(err)
 	}
+	winInfo.mutex.Lock()
+	winInfo.wmAllowedActions = wmAllowedActions
+	winInfo.mutex.Unlock()
 }
 func (winInfo *WindowInfo) isActionMinimizeAllowed() bool {
 	logger.Debugf("wmAllowedActions: %#v", winInfo.wmAllowedActions)
@@ -96,11 +139,13 @@ func (winInfo *WindowInfo) isActionMinimizeAllowed() bool {
 
 // wm state
 func (winInfo *WindowInfo) updateWmState() {
-	var err error
-	winInfo.wmState, err = ewmh.WmStateGet(XU, winInfo.window)
+	wmState, err := ewmh.WmStateGet(XU, winInfo.window)
 	if err != nil {
 		logger.Debug(err)
 	}
+	winInfo.mutex.Lock()
+	winInfo.wmState = wmState
+	winInfo.mutex.Unlock()
 }
 
 func (winInfo *WindowInfo) hasWmStateSkipTaskbar() bool {
@@ -122,7 +167,9 @@ func (winInfo *WindowInfo) updateMapState() {
 		logger.Debug(err)
 		return
 	}
+	winInfo.mutex.Lock()
 	winInfo.mapState = windowAttributes.MapState
+	winInfo.mutex.Unlock()
 	logger.Debug("update map state:", winInfo.mapState)
 }
 
@@ -133,11 +180,13 @@ func (winInfo *WindowInfo) isMapStateViewable() bool {
 
 // wm class
 func (winInfo *WindowInfo) updateWmClass() {
-	var err error
-	winInfo.wmClass, err = icccm.WmClassGet(XU, winInfo.window)
+	wmClass, err := icccm.WmClassGet(XU, winInfo.window)
 	if err != nil {
 		logger.Debug(err)
 	}
+	winInfo.mutex.Lock()
+	winInfo.wmClass = wmClass
+	winInfo.mutex.Unlock()
 }
 
 // 通过 wmClass 判断是否需要隐藏此窗口
@@ -157,7 +206,9 @@ func (winInfo *WindowInfo) isWmClassOk() bool {
 // 一般 trayicon 会带有 _XEMBED_INFO 属性
 func (winInfo *WindowInfo) updateHasXEmbedInfo() {
 	_, err := xprop.GetProperty(XU, winInfo.window, "_XEMBED_INFO")
+	winInfo.mutex.Lock()
 	winInfo.hasXEmbedInfo = (err == nil)
+	winInfo.mutex.Unlock()
 }
 
 // WM_TRANSIENT_FOR
@@ -168,8 +219,11 @@ func (winInfo *WindowInfo) updateHasWmTransientFor() {
 
 // wm name
 func (winInfo *WindowInfo) updateWmName() {
-	winInfo.wmName = getWmName(XU, winInfo.window)
+	wmName := getWmName(XU, winInfo.window)
+	winInfo.mutex.Lock()
+	winInfo.wmName = wmName
 	winInfo.Title = winInfo.getTitle()
+	winInfo.mutex.Unlock()
 	entry := winInfo.entry
 	if entry != nil {
 		entry.updateWindowTitles()
@@ -252,11 +306,17 @@ func (winInfo *WindowInfo) getTitle() string {
 }
 
 func (winInfo *WindowInfo) getIcon() string {
-	if winInfo.Icon == "" {
+	winInfo.mutex.RLock()
+	icon := winInfo.Icon
+	winInfo.mutex.RUnlock()
+	if icon == "" {
 		logger.Debug("get icon from window", winInfo.window)
-		winInfo.Icon = getIconFromWindow(XU, winInfo.window)
+		icon = getIconFromWindow(XU, winInfo.window)
+		winInfo.mutex.Lock()
+		winInfo.Icon = icon
+		winInfo.mutex.Unlock()
 	}
-	return winInfo.Icon
+	return icon
 }
 
 var skipTaskbarWindowTypes []string = []string{
@@ -304,18 +364,21 @@ func (winInfo *WindowInfo) canShowOnDock() bool {
 
 func (winInfo *WindowInfo) initProcessInfo() {
 	win := winInfo.window
-	winInfo.pid = getWmPid(XU, win)
-	var err error
-	winInfo.process, err = NewProcessInfo(winInfo.pid)
+	pid := getWmPid(XU, win)
+	process, err := NewProcessInfo(pid)
 	if err != nil {
 		logger.Debug(err)
 		// Try WM_COMMAND
 		wmCommand, err := getWmCommand(XU, win)
 		if err == nil {
-			winInfo.process = NewProcessInfoWithCmdline(wmCommand)
+			process = NewProcessInfoWithCmdline(wmCommand)
 		}
 	}
-	logger.Debugf("process: %#v", winInfo.process)
+	logger.Debugf("process: %#v", process)
+	winInfo.mutex.Lock()
+	winInfo.pid = pid
+	winInfo.process = process
+	winInfo.mutex.Unlock()
 }
 
 func (winInfo *WindowInfo) update() {
@@ -326,13 +389,19 @@ func (winInfo *WindowInfo) update() {
 	winInfo.updateWmState()
 	winInfo.updateWmWindowType()
 	winInfo.updateWmAllowedActions()
+	winInfo.updateWorkspace()
+	winInfo.updateGeometry()
 	if len(winInfo.wmWindowType) == 0 {
 		winInfo.updateHasXEmbedInfo()
 	}
 	winInfo.updateHasWmTransientFor()
 	winInfo.initProcessInfo()
-	winInfo.wmRole = getWmWindowRole(XU, win)
-	winInfo.gtkAppId = getWindowGtkApplicationId(XU, win)
+	wmRole := getWmWindowRole(XU, win)
+	gtkAppId := getWindowGtkApplicationId(XU, win)
+	winInfo.mutex.Lock()
+	winInfo.wmRole = wmRole
+	winInfo.gtkAppId = gtkAppId
+	winInfo.mutex.Unlock()
 	winInfo.updateWmName()
 	winInfo.genInnerId()
 }
@@ -380,7 +449,9 @@ func (winInfo *WindowInfo) genInnerId() {
 
 	hasher := md5.New()
 	hasher.Write([]byte(str))
+	winInfo.mutex.Lock()
 	winInfo.innerId = windowHashPrefix + hex.EncodeToString(hasher.Sum(nil))
+	winInfo.mutex.Unlock()
 	logger.Debugf("genInnerId win: %v str: %s, md5sum: %s", win, str, winInfo.innerId)
 }
 
@@ -411,6 +482,12 @@ func (winInfo *WindowInfo) initPropertyNotifyEventHandler(dockManager *DockManag
 			dockManager.attachOrDetachWindow(winInfo)
 		}
 
+		// minimized, fullscreen or moved to other workspace
+		if winInfo.propertyNotifyAtomTable[ATOM_WINDOW_STATE] ||
+			winInfo.propertyNotifyAtomTable[ATOM_WINDOW_DESKTOP] {
+			dockManager.updateHideStateWithDelay()
+		}
+
 		// end
 		winInfo.propertyNotifyAtomTable = make(map[xproto.Atom]bool)
 		winInfo.propertyNotifyEnabled = true
@@ -443,9 +520,22 @@ func (winInfo *WindowInfo) handlePropertyNotifyAtom(atom xproto.Atom) bool {
 		winInfo.updateWmName()
 		return false
 
+	case ATOM_WINDOW_DESKTOP:
+		winInfo.updateWorkspace()
+		entry := winInfo.entry
+		if entry != nil {
+			entry.windowMutex.Lock()
+			entry.updateWindowsByFilter()
+			entry.windowMutex.Unlock()
+		}
+		return false
+
 	case ATOM_WINDOW_ICON:
 		//  update icon cache
-		winInfo.Icon = getIconFromWindow(XU, winInfo.window)
+		icon := getIconFromWindow(XU, winInfo.window)
+		winInfo.mutex.Lock()
+		winInfo.Icon = icon
+		winInfo.mutex.Unlock()
 		entry := winInfo.entry
 		if entry != nil && entry.current == winInfo {
 			entry.updateIcon()
@@ -0,0 +1,118 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// windowInfoQuery is the exported information of a tracked window, for
+// debugging why a window is attached to an entry.
+type windowInfoQuery struct {
+	Window         uint32
+	Title          string
+	InnerId        string
+	AppId          string
+	DesktopFile    string
+	IdentifyMethod string
+	EntryId        string
+
+	X      int16
+	Y      int16
+	Width  uint16
+	Height uint16
+
+	WmClass          string
+	WmInstance       string
+	WmName           string
+	WmRole           string
+	GtkAppId         string
+	WmState          []string
+	WmWindowType     []string
+	WmAllowedActions []string
+	HasXEmbedInfo    bool
+	MapState         byte
+
+	Pid  uint32
+	Exe  string
+	Args []string
+}
+
+func newWindowInfoQuery(winInfo *WindowInfo) *windowInfoQuery {
+	winInfo.mutex.RLock()
+	defer winInfo.mutex.RUnlock()
+
+	q := &windowInfoQuery{
+		Window:           uint32(winInfo.window),
+		Title:            winInfo.Title,
+		InnerId:          winInfo.innerId,
+		IdentifyMethod:   winInfo.identifyMethod,
+		X:                winInfo.x,
+		Y:                winInfo.y,
+		Width:            winInfo.width,
+		Height:           winInfo.height,
+		WmName:           winInfo.wmName,
+		WmRole:           winInfo.wmRole,
+		GtkAppId:         winInfo.gtkAppId,
+		WmState:          winInfo.wmState,
+		WmWindowType:     winInfo.wmWindowType,
+		WmAllowedActions: winInfo.wmAllowedActions,
+		HasXEmbedInfo:    winInfo.hasXEmbedInfo,
+		MapState:         winInfo.mapState,
+		Pid:              uint32(winInfo.pid),
+	}
+
+	if winInfo.appInfo != nil {
+		q.AppId = winInfo.appInfo.GetId()
+		q.DesktopFile = winInfo.appInfo.GetFileName()
+	} else {
+		q.AppId = winInfo.entryInnerId
+	}
+	if winInfo.entry != nil {
+		q.EntryId = winInfo.entry.Id
+	}
+	if winInfo.wmClass != nil {
+		q.WmClass = winInfo.wmClass.Class
+		q.WmInstance = winInfo.wmClass.Instance
+	}
+	if winInfo.process != nil {
+		q.Exe = winInfo.process.exe
+		q.Args = winInfo.process.args
+	}
+	return q
+}
+
+// GetWindowsInfo returns the JSON encoded information of every tracked
+// window, including the app it is identified as and the identification
+// method, one of PidEnv, Rule, Bamf, Pid, Cache, GtkAppId and WmClass.
+// IdentifyMethod is empty if the window is not identified.
+func (m *DockManager) GetWindowsInfo() (string, error) {
+	m.windowInfoMapMutex.RLock()
+	var windows []xproto.Window
+	for win := range m.windowInfoMap {
+		windows = append(windows, win)
+	}
+	sort.Sort(windowSlice(windows))
+
+	var infos []*windowInfoQuery
+	for _, win := range windows {
+		infos = append(infos, newWindowInfoQuery(m.windowInfoMap[win]))
+	}
+	m.windowInfoMapMutex.RUnlock()
+
+	data, err := json.Marshal(infos)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,114 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"pkg.deepin.io/lib/xdg/basedir"
+)
+
+// The *.json files in this directory have the same format as
+// windowPatternsFile, and are tried before it.
+func getUserWindowPatternsDir() string {
+	return filepath.Join(basedir.GetUserConfigDir(), "deepin/dde-daemon/dock/window_patterns.d")
+}
+
+func (m *DockManager) getWindowPatterns() WindowPatterns {
+	m.windowPatternsMutex.RLock()
+	defer m.windowPatternsMutex.RUnlock()
+	return m.windowPatterns
+}
+
+func (m *DockManager) loadWindowPatterns() {
+	patterns, err := loadLayeredWindowPatterns(getUserWindowPatternsDir(), windowPatternsFile)
+	if err != nil {
+		logger.Warning("loadWindowPatterns failed:", err)
+		return
+	}
+
+	m.windowPatternsMutex.Lock()
+	m.windowPatterns = patterns
+	m.windowPatternsMutex.Unlock()
+	logger.Debugf("window patterns loaded, count %d", len(patterns))
+}
+
+func (m *DockManager) watchWindowPatterns() {
+	userDir := getUserWindowPatternsDir()
+	var watchedDir string
+	var err error
+	m.windowPatternsWatcher, err = newFileWatcher("window patterns", func(w *fileWatcher, ev fsnotify.Event) bool {
+		if ev.Name == watchedDir && ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+			// the watch is gone with the directory
+			watchedDir = ""
+		}
+		if watchedDir != userDir {
+			watchedDir = watchUserWindowPatternsDir(w, watchedDir)
+			if watchedDir == userDir {
+				// files may be written before the watch is added
+				return true
+			}
+		}
+		return ev.Name == windowPatternsFile || ev.Name == userDir ||
+			strings.HasPrefix(ev.Name, userDir+"/")
+	}, m.loadWindowPatterns)
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+
+	err = m.windowPatternsWatcher.add(windowPatternsFile)
+	if err != nil {
+		logger.Warning(err)
+	}
+	watchedDir = watchUserWindowPatternsDir(m.windowPatternsWatcher, "")
+	m.windowPatternsWatcher.start()
+}
+
+// watchUserWindowPatternsDir watches the user window patterns directory, or
+// its nearest existing parent until it is created, and returns the watched
+// directory. watched is the directory watched before.
+func watchUserWindowPatternsDir(w *fileWatcher, watched string) string {
+	dir := getUserWindowPatternsDir()
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return watched
+		}
+		dir = parent
+	}
+	if dir == watched {
+		return watched
+	}
+
+	err := w.add(dir)
+	if err != nil {
+		logger.Warning(err)
+		return watched
+	}
+	if watched != "" {
+		w.remove(watched)
+	}
+	logger.Debug("watch window patterns in", dir)
+	return dir
+}
+
+func (m *DockManager) stopWatchWindowPatterns() {
+	if m.windowPatternsWatcher != nil {
+		m.windowPatternsWatcher.close()
+		m.windowPatternsWatcher = nil
+	}
+}
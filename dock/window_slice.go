@@ -28,6 +28,17 @@ func (winSlice windowSlice) Contains(win xproto.Window) bool {
 	return false
 }
 
+type windowInfosMRU []*WindowInfo
+
+func (a windowInfosMRU) Len() int      { return len(a) }
+func (a windowInfosMRU) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a windowInfosMRU) Less(i, j int) bool {
+	if a[i].activeSerial != a[j].activeSerial {
+		return a[i].activeSerial > a[j].activeSerial
+	}
+	return uint32(a[i].window) < uint32(a[j].window)
+}
+
 // from a to b
 // return [add, remove]
 func diffSortedWindowSlice(a, b windowSlice) (add, remove windowSlice) {
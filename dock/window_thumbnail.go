@@ -0,0 +1,335 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/BurntSushi/xgb/composite"
+	"github.com/BurntSushi/xgb/damage"
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/xevent"
+	"github.com/BurntSushi/xgbutil/xgraphics"
+	"github.com/BurntSushi/xgbutil/xwindow"
+	"image"
+	"image/png"
+	"strconv"
+	"sync"
+)
+
+const (
+	thumbnailDefaultSize = 200
+	// the sizes are clamped to thumbnailMaxSize, and at most
+	// thumbnailMaxImages sizes are cached for a window, the least recently
+	// used one is dropped first
+	thumbnailMaxSize   = 1024
+	thumbnailMaxImages = 4
+)
+
+type thumbnail struct {
+	// drawable is the window or its frame the image is taken from
+	drawable xproto.Drawable
+	damage   damage.Damage
+	// png images of different sizes, cleared when the window is damaged
+	images map[[2]int][]byte
+	// imageKeys are the keys of images, the most recently used last
+	imageKeys [][2]int
+	// serial is increased when the window is damaged, an image captured
+	// before that is not cached
+	serial uint64
+}
+
+type thumbnailManager struct {
+	mutex      sync.Mutex
+	thumbnails map[xproto.Window]*thumbnail
+	// composite and damage extensions are available
+	hasComposite bool
+}
+
+func newThumbnailManager() *thumbnailManager {
+	tm := &thumbnailManager{
+		thumbnails: make(map[xproto.Window]*thumbnail),
+	}
+	conn := XU.Conn()
+	if composite.Init(conn) == nil && damage.Init(conn) == nil {
+		_, err1 := composite.QueryVersion(conn, 0, 4).Reply()
+		_, err2 := damage.QueryVersion(conn, 1, 1).Reply()
+		tm.hasComposite = err1 == nil && err2 == nil
+	}
+	if !tm.hasComposite {
+		logger.Info("composite or damage extension unavailable, thumbnails of hidden windows are blank")
+	}
+
+	xevent.HookFun(func(xu *xgbutil.XUtil, event interface{}) bool {
+		if ev, ok := event.(damage.NotifyEvent); ok {
+			tm.handleDamage(ev)
+		}
+		return true
+	}).Connect(XU)
+	return tm
+}
+
+// fitSize scales width x height to fit in maxWidth x maxHeight, keeping the
+// aspect ratio and never enlarging.
+func fitSize(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+	// compare width/maxWidth with height/maxHeight
+	if width*maxHeight > height*maxWidth {
+		h := height * maxWidth / width
+		if h < 1 {
+			h = 1
+		}
+		return maxWidth, h
+	}
+	w := width * maxHeight / height
+	if w < 1 {
+		w = 1
+	}
+	return w, maxHeight
+}
+
+// getToplevel returns the child of the root window containing win, that is
+// the frame of the window for reparenting window managers.
+func getToplevel(win xproto.Window) xproto.Window {
+	root := XU.RootWin()
+	for {
+		tree, err := xproto.QueryTree(XU.Conn(), win).Reply()
+		if err != nil || tree.Parent == root || tree.Parent == 0 {
+			return win
+		}
+		win = tree.Parent
+	}
+}
+
+// getImage returns the cached image of size key and marks it most recently
+// used.
+func (t *thumbnail) getImage(key [2]int) []byte {
+	data, ok := t.images[key]
+	if !ok {
+		return nil
+	}
+	for i, k := range t.imageKeys {
+		if k == key {
+			copy(t.imageKeys[i:], t.imageKeys[i+1:])
+			t.imageKeys[len(t.imageKeys)-1] = key
+			break
+		}
+	}
+	return data
+}
+
+func (t *thumbnail) addImage(key [2]int, data []byte) {
+	if _, ok := t.images[key]; ok {
+		t.images[key] = data
+		t.getImage(key)
+		return
+	}
+	if len(t.imageKeys) >= thumbnailMaxImages {
+		delete(t.images, t.imageKeys[0])
+		t.imageKeys = t.imageKeys[1:]
+	}
+	t.images[key] = data
+	t.imageKeys = append(t.imageKeys, key)
+}
+
+func (t *thumbnail) clearImages() {
+	t.images = make(map[[2]int][]byte)
+	t.imageKeys = nil
+}
+
+func (tm *thumbnailManager) newThumbnail(win xproto.Window) *thumbnail {
+	t := &thumbnail{
+		drawable: xproto.Drawable(win),
+		images:   make(map[[2]int][]byte),
+	}
+	if !tm.hasComposite {
+		return t
+	}
+
+	conn := XU.Conn()
+	t.drawable = xproto.Drawable(getToplevel(win))
+	d, err := damage.NewDamageId(conn)
+	if err != nil {
+		logger.Debug("new damage id failed:", err)
+		return t
+	}
+	err = damage.CreateChecked(conn, d, t.drawable, damage.ReportLevelNonEmpty).Check()
+	if err != nil {
+		logger.Debug("create damage failed:", err)
+		return t
+	}
+	t.damage = d
+	return t
+}
+
+func (tm *thumbnailManager) handleDamage(ev damage.NotifyEvent) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+	for _, t := range tm.thumbnails {
+		if t.damage == ev.Damage {
+			t.clearImages()
+			t.serial++
+			// report the next damage
+			damage.Subtract(XU.Conn(), t.damage, 0, 0)
+			return
+		}
+	}
+}
+
+func (tm *thumbnailManager) remove(win xproto.Window) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+	t, ok := tm.thumbnails[win]
+	if !ok {
+		return
+	}
+	if t.damage != 0 {
+		damage.Destroy(XU.Conn(), t.damage)
+	}
+	delete(tm.thumbnails, win)
+}
+
+func (tm *thumbnailManager) capture(t *thumbnail) (image.Image, error) {
+	if tm.hasComposite {
+		conn := XU.Conn()
+		pixmap, err := xproto.NewPixmapId(conn)
+		if err != nil {
+			return nil, err
+		}
+		defer xproto.FreePixmap(conn, pixmap)
+		err = composite.NameWindowPixmapChecked(conn, xproto.Window(t.drawable), pixmap).Check()
+		if err == nil {
+			return xgraphics.NewDrawable(XU, xproto.Drawable(pixmap))
+		}
+		logger.Debug("NameWindowPixmap failed:", err)
+	}
+	// only the visible parts of the window
+	return xgraphics.NewDrawable(XU, t.drawable)
+}
+
+// getThumbnail returns the thumbnail of win, the cached image of size key
+// and the serial of the thumbnail.
+func (tm *thumbnailManager) getThumbnail(win xproto.Window, key [2]int) (*thumbnail, []byte, uint64) {
+	tm.mutex.Lock()
+	t, ok := tm.thumbnails[win]
+	if ok {
+		data := t.getImage(key)
+		serial := t.serial
+		tm.mutex.Unlock()
+		return t, data, serial
+	}
+	tm.mutex.Unlock()
+
+	// the damage is created without the lock held, handleDamage is called
+	// from the X event loop
+	newT := tm.newThumbnail(win)
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+	t, ok = tm.thumbnails[win]
+	if ok {
+		// added by another call meanwhile
+		if newT.damage != 0 {
+			damage.Destroy(XU.Conn(), newT.damage)
+		}
+		return t, t.getImage(key), t.serial
+	}
+	tm.thumbnails[win] = newT
+	return newT, nil, newT.serial
+}
+
+// get returns the png thumbnail of win fitting in maxWidth x maxHeight.
+// The window is captured and encoded without the lock held.
+func (tm *thumbnailManager) get(win xproto.Window, maxWidth, maxHeight int) ([]byte, error) {
+	key := [2]int{maxWidth, maxHeight}
+	t, data, serial := tm.getThumbnail(win, key)
+	if data != nil {
+		return data, nil
+	}
+
+	// drawable is never changed after the thumbnail is created
+	img, err := tm.capture(t)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	width, height := fitSize(bounds.Dx(), bounds.Dy(), maxWidth, maxHeight)
+	if width == 0 {
+		return nil, errors.New("empty window")
+	}
+	if width != bounds.Dx() || height != bounds.Dy() {
+		img = xgraphics.Scale(img, width, height)
+	}
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, img)
+	if err != nil {
+		return nil, err
+	}
+	data = buf.Bytes()
+
+	tm.mutex.Lock()
+	// not cached if the window is damaged or removed meanwhile
+	if t.serial == serial && tm.thumbnails[win] == t {
+		t.addImage(key, data)
+	}
+	tm.mutex.Unlock()
+	return data, nil
+}
+
+// GetWindowThumbnails returns the PNG thumbnails of the windows in JSON,
+// an object mapping window ids to data uris. Thumbnails fit in
+// maxWidth x maxHeight, 0 means the default size.
+func (entry *AppEntry) GetWindowThumbnails(maxWidth, maxHeight uint32) (string, error) {
+	if entry.dockManager == nil || entry.dockManager.thumbnailManager == nil {
+		return "", errors.New("thumbnails unavailable")
+	}
+	if maxWidth == 0 {
+		maxWidth = thumbnailDefaultSize
+	}
+	if maxHeight == 0 {
+		maxHeight = thumbnailDefaultSize
+	}
+	if maxWidth > thumbnailMaxSize {
+		maxWidth = thumbnailMaxSize
+	}
+	if maxHeight > thumbnailMaxSize {
+		maxHeight = thumbnailMaxSize
+	}
+
+	entry.windowMutex.Lock()
+	windows := entry.getWindowIds()
+	entry.windowMutex.Unlock()
+
+	tm := entry.dockManager.thumbnailManager
+	result := make(map[string]string, len(windows))
+	for _, win := range windows {
+		data, err := tm.get(xproto.Window(win), int(maxWidth), int(maxHeight))
+		if err != nil {
+			logger.Debugf("get thumbnail of window %v failed: %v", win, err)
+			continue
+		}
+		result[strconv.FormatUint(uint64(win), 10)] = "data:image/png;base64," +
+			base64.StdEncoding.EncodeToString(data)
+	}
+
+	content, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
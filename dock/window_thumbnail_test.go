@@ -0,0 +1,59 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package dock
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func Test_fitSize(t *testing.T) {
+	Convey("fitSize", t, func() {
+		w, h := fitSize(1920, 1080, 200, 200)
+		So(w, ShouldEqual, 200)
+		So(h, ShouldEqual, 112)
+
+		w, h = fitSize(600, 1200, 200, 200)
+		So(w, ShouldEqual, 100)
+		So(h, ShouldEqual, 200)
+
+		// never enlarged
+		w, h = fitSize(100, 50, 200, 200)
+		So(w, ShouldEqual, 100)
+		So(h, ShouldEqual, 50)
+
+		w, h = fitSize(10000, 1, 100, 100)
+		So(w, ShouldEqual, 100)
+		So(h, ShouldEqual, 1)
+
+		w, _ = fitSize(0, 100, 200, 200)
+		So(w, ShouldEqual, 0)
+	})
+}
+
+func Test_thumbnailImages(t *testing.T) {
+	Convey("thumbnail images", t, func() {
+		th := &thumbnail{images: make(map[[2]int][]byte)}
+		for i := 0; i < thumbnailMaxImages; i++ {
+			th.addImage([2]int{i, i}, []byte{byte(i)})
+		}
+		// the image of size 0 is used recently, size 1 is dropped
+		So(th.getImage([2]int{0, 0}), ShouldResemble, []byte{0})
+		th.addImage([2]int{100, 100}, []byte{100})
+		So(len(th.images), ShouldEqual, thumbnailMaxImages)
+		So(th.getImage([2]int{1, 1}), ShouldBeNil)
+		So(th.getImage([2]int{0, 0}), ShouldResemble, []byte{0})
+		So(th.getImage([2]int{100, 100}), ShouldResemble, []byte{100})
+
+		th.clearImages()
+		So(th.images, ShouldBeEmpty)
+		So(th.imageKeys, ShouldBeEmpty)
+	})
+}
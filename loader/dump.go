@@ -0,0 +1,201 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"pkg.deepin.io/dde/daemon/graph"
+)
+
+type GraphNode struct {
+	Name         string
+	Enabled      bool
+	Missing      bool
+	InCycle      bool
+	Dependencies []string
+}
+
+// ModuleGraph is the dependency graph of all the registered modules.
+type ModuleGraph struct {
+	Nodes  []*GraphNode
+	Errors []string
+}
+
+// GetGraph builds the dependency graph of all the registered modules, the
+// enabled state of the nodes is the running state of the modules. Missing
+// dependencies and dependency circles are reported in Errors as
+// EnableModules would report them.
+func (l *Loader) GetGraph() *ModuleGraph {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	g := &ModuleGraph{}
+	dag := graph.New()
+	nodes := map[string]*graph.Node{}
+	graphNodes := map[string]*GraphNode{}
+
+	for _, module := range l.modules {
+		name := module.Name()
+		graphNodes[name] = &GraphNode{
+			Name:         name,
+			Enabled:      module.IsEnable(),
+			Dependencies: []string{},
+		}
+		createNodeIfNeeded(dag, nodes, name)
+	}
+
+	for _, module := range l.modules {
+		name := module.Name()
+		node := graphNodes[name]
+		for _, dependency := range module.GetDependencies() {
+			node.Dependencies = append(node.Dependencies, dependency)
+			if _, ok := l.modules[dependency]; !ok {
+				if _, ok := graphNodes[dependency]; !ok {
+					graphNodes[dependency] = &GraphNode{
+						Name:         dependency,
+						Missing:      true,
+						Dependencies: []string{},
+					}
+				}
+				err := &EnableError{ModuleName: name, Code: ErrorNoDependencies, detail: dependency}
+				g.Errors = append(g.Errors, err.Error())
+			}
+
+			depNode := createNodeIfNeeded(dag, nodes, dependency)
+			dag.UpdateEdgeWeight(depNode, nodes[name], 0)
+		}
+		sort.Strings(node.Dependencies)
+	}
+
+	if _, ok := dag.TopologicalDag(); !ok {
+		for _, name := range findCycleNodes(nodes) {
+			graphNodes[name].InCycle = true
+			err := &EnableError{ModuleName: name, Code: ErrorCircleDependencies}
+			g.Errors = append(g.Errors, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+
+	for _, node := range graphNodes {
+		g.Nodes = append(g.Nodes, node)
+	}
+	sort.Sort(graphNodesByName(g.Nodes))
+	sort.Strings(g.Errors)
+	return g
+}
+
+// findCycleNodes returns the nodes on the dependency circles.
+func findCycleNodes(nodes map[string]*graph.Node) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := map[*graph.Node]int{}
+	inCycle := map[string]struct{}{}
+	var stack []*graph.Node
+
+	var visit func(node *graph.Node)
+	visit = func(node *graph.Node) {
+		states[node] = visiting
+		stack = append(stack, node)
+		for next := range node.WeightTo {
+			switch states[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				for i := len(stack) - 1; i >= 0; i-- {
+					inCycle[stack[i].ID] = struct{}{}
+					if stack[i] == next {
+						break
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		states[node] = visited
+	}
+
+	for _, node := range nodes {
+		if states[node] == unvisited {
+			visit(node)
+		}
+	}
+
+	var names []string
+	for name := range inCycle {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type graphNodesByName []*GraphNode
+
+func (l graphNodesByName) Len() int {
+	return len(l)
+}
+
+func (l graphNodesByName) Swap(i, j int) {
+	l[i], l[j] = l[j], l[i]
+}
+
+func (l graphNodesByName) Less(i, j int) bool {
+	return l[i].Name < l[j].Name
+}
+
+func (g *ModuleGraph) JSON() ([]byte, error) {
+	return json.Marshal(g)
+}
+
+// DOT returns the graph in Graphviz DOT language, the edges point from the
+// dependencies to the modules depending on them.
+func (g *ModuleGraph) DOT() string {
+	var buf bytes.Buffer
+	buf.WriteString("digraph modules {\n")
+	for _, node := range g.Nodes {
+		var attrs string
+		switch {
+		case node.Missing:
+			attrs = `style=dashed, color=red, label="` + node.Name + ` (missing)"`
+		case node.Enabled:
+			attrs = "style=filled, fillcolor=palegreen"
+		default:
+			attrs = "style=filled, fillcolor=lightgrey"
+		}
+		if node.InCycle {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(&buf, "\t%q [%s];\n", node.Name, attrs)
+	}
+
+	inCycle := map[string]bool{}
+	for _, node := range g.Nodes {
+		inCycle[node.Name] = node.InCycle
+	}
+	for _, node := range g.Nodes {
+		for _, dependency := range node.Dependencies {
+			if inCycle[node.Name] && inCycle[dependency] {
+				fmt.Fprintf(&buf, "\t%q -> %q [color=red];\n", dependency, node.Name)
+				continue
+			}
+			fmt.Fprintf(&buf, "\t%q -> %q;\n", dependency, node.Name)
+		}
+	}
+
+	for _, err := range g.Errors {
+		fmt.Fprintf(&buf, "\t// %s\n", err)
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
@@ -0,0 +1,164 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"pkg.deepin.io/dde/daemon/graph"
+	"testing"
+)
+
+func TestGetGraph(t *testing.T) {
+	var infos = []struct {
+		name    string
+		modules []*fakeModule
+		enabled []string
+		nodes   []*GraphNode
+		errors  []string
+	}{
+		{
+			name: "chain",
+			modules: []*fakeModule{
+				newFakeModule("a"),
+				newFakeModule("b", "a"),
+				newFakeModule("c", "b", "a"),
+			},
+			enabled: []string{"b"},
+			nodes: []*GraphNode{
+				{Name: "a", Enabled: true, Dependencies: []string{}},
+				{Name: "b", Enabled: true, Dependencies: []string{"a"}},
+				{Name: "c", Dependencies: []string{"a", "b"}},
+			},
+		},
+		{
+			name: "missing dependency",
+			modules: []*fakeModule{
+				newFakeModule("a", "x"),
+			},
+			nodes: []*GraphNode{
+				{Name: "a", Dependencies: []string{"x"}},
+				{Name: "x", Missing: true, Dependencies: []string{}},
+			},
+			errors: []string{"a's dependencies is not meet, x is need"},
+		},
+		{
+			name: "cycle",
+			modules: []*fakeModule{
+				newFakeModule("a"),
+				newFakeModule("b", "a", "d"),
+				newFakeModule("c", "b"),
+				newFakeModule("d", "c"),
+				newFakeModule("e", "d"),
+			},
+			nodes: []*GraphNode{
+				{Name: "a", Dependencies: []string{}},
+				{Name: "b", InCycle: true, Dependencies: []string{"a", "d"}},
+				{Name: "c", InCycle: true, Dependencies: []string{"b"}},
+				{Name: "d", InCycle: true, Dependencies: []string{"c"}},
+				{Name: "e", Dependencies: []string{"d"}},
+			},
+			errors: []string{
+				"b: dependency circle",
+				"c: dependency circle",
+				"d: dependency circle",
+			},
+		},
+	}
+
+	Convey("Test GetGraph", t, func() {
+		for _, info := range infos {
+			var modules []Module
+			for _, m := range info.modules {
+				modules = append(modules, m)
+			}
+			l := newTestLoader(modules...)
+			if len(info.enabled) > 0 {
+				So(l.EnableModules(info.enabled, nil, EnableFlagNone), ShouldBeNil)
+			}
+
+			g := l.GetGraph()
+			So(g.Nodes, ShouldResemble, info.nodes)
+			So(g.Errors, ShouldResemble, info.errors)
+		}
+	})
+}
+
+func TestFindCycleNodes(t *testing.T) {
+	var infos = []struct {
+		edges   [][2]string
+		inCycle []string
+	}{
+		{[][2]string{{"a", "b"}, {"b", "c"}, {"a", "c"}}, nil},
+		{[][2]string{{"a", "a"}}, []string{"a"}},
+		{[][2]string{{"a", "b"}, {"b", "a"}, {"b", "c"}}, []string{"a", "b"}},
+		// two cycles sharing node c
+		{[][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"c", "d"}, {"d", "c"}, {"d", "e"}},
+			[]string{"a", "b", "c", "d"}},
+	}
+
+	Convey("Test findCycleNodes", t, func() {
+		for _, info := range infos {
+			dag := graph.New()
+			nodes := map[string]*graph.Node{}
+			for _, edge := range info.edges {
+				src := createNodeIfNeeded(dag, nodes, edge[0])
+				dst := createNodeIfNeeded(dag, nodes, edge[1])
+				dag.UpdateEdgeWeight(src, dst, 0)
+			}
+			So(findCycleNodes(nodes), ShouldResemble, info.inCycle)
+		}
+	})
+}
+
+func TestModuleGraphExport(t *testing.T) {
+	g := &ModuleGraph{
+		Nodes: []*GraphNode{
+			{Name: "a", Enabled: true, Dependencies: []string{}},
+			{Name: "b", InCycle: true, Dependencies: []string{"a", "c"}},
+			{Name: "c", InCycle: true, Dependencies: []string{"b"}},
+			{Name: "d", Dependencies: []string{"x"}},
+			{Name: "x", Missing: true, Dependencies: []string{}},
+		},
+		Errors: []string{
+			"b: dependency circle",
+			"c: dependency circle",
+			"d's dependencies is not meet, x is need",
+		},
+	}
+
+	Convey("Test ModuleGraph DOT", t, func() {
+		So(g.DOT(), ShouldEqual, `digraph modules {
+	"a" [style=filled, fillcolor=palegreen];
+	"b" [style=filled, fillcolor=lightgrey, color=red];
+	"c" [style=filled, fillcolor=lightgrey, color=red];
+	"d" [style=filled, fillcolor=lightgrey];
+	"x" [style=dashed, color=red, label="x (missing)"];
+	"a" -> "b";
+	"c" -> "b" [color=red];
+	"b" -> "c" [color=red];
+	"x" -> "d";
+	// b: dependency circle
+	// c: dependency circle
+	// d's dependencies is not meet, x is need
+}
+`)
+	})
+
+	Convey("Test ModuleGraph JSON", t, func() {
+		data, err := g.JSON()
+		So(err, ShouldBeNil)
+		var decoded ModuleGraph
+		So(json.Unmarshal(data, &decoded), ShouldBeNil)
+		So(&decoded, ShouldResemble, g)
+		So(string(data), ShouldContainSubstring,
+			`{"Name":"b","Enabled":false,"Missing":false,"InCycle":true,"Dependencies":["a","c"]}`)
+	})
+}
@@ -0,0 +1,188 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	"time"
+)
+
+// RetryPolicy controls how a module failing to start is retried. The
+// interval is doubled after each retry until it reaches MaxInterval.
+type RetryPolicy struct {
+	MaxRetries  int
+	Interval    time.Duration
+	MaxInterval time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:  3,
+	Interval:    2 * time.Second,
+	MaxInterval: 30 * time.Second,
+}
+
+func (p RetryPolicy) delay(retries int) time.Duration {
+	delay := p.Interval
+	for i := 0; i < retries; i++ {
+		delay *= 2
+		if delay >= p.MaxInterval {
+			return p.MaxInterval
+		}
+	}
+	return delay
+}
+
+// ModuleFailure describes why a module is not running.
+type ModuleFailure struct {
+	// Reason is the error returned by Start, or the reason of the failed
+	// dependency.
+	Reason string
+	// Dependency is the name of the failed dependency, it is empty if the
+	// module itself failed to start.
+	Dependency string
+	Retries    int
+	Time       time.Time
+}
+
+type enableArgs struct {
+	disableModules []string
+	flag           EnableFlag
+}
+
+type failureState struct {
+	ModuleFailure
+	retryTimer *time.Timer
+}
+
+func (l *Loader) SetRetryPolicy(policy RetryPolicy) {
+	l.stateLock.Lock()
+	l.retryPolicy = policy
+	l.stateLock.Unlock()
+}
+
+// ConnectModuleFailed registers fn to be called when a module fails to start,
+// or is not started because of a failed dependency.
+func (l *Loader) ConnectModuleFailed(fn func(name string, failure ModuleFailure)) {
+	l.stateLock.Lock()
+	l.failedHandlers = append(l.failedHandlers, fn)
+	l.stateLock.Unlock()
+}
+
+// GetFailure returns the failure of the module, ok is false if the module is
+// not failed.
+func (l *Loader) GetFailure(name string) (ModuleFailure, bool) {
+	l.stateLock.Lock()
+	defer l.stateLock.Unlock()
+	state, ok := l.failures[name]
+	if !ok {
+		return ModuleFailure{}, false
+	}
+	return state.ModuleFailure, true
+}
+
+func (l *Loader) setModuleFailed(module Module, err error) {
+	name := module.Name()
+	l.stateLock.Lock()
+	retries := 0
+	if state, ok := l.failures[name]; ok {
+		if state.Dependency == "" {
+			retries = state.Retries + 1
+		}
+		if state.retryTimer != nil {
+			state.retryTimer.Stop()
+		}
+	}
+	state := &failureState{
+		ModuleFailure: ModuleFailure{
+			Reason:  err.Error(),
+			Retries: retries,
+			Time:    time.Now(),
+		},
+	}
+	l.failures[name] = state
+
+	policy := l.retryPolicy
+	switch {
+	case runOnMainThread(module):
+		l.log.Warningf("module %s must run on main thread, no retry", name)
+	case retries >= policy.MaxRetries:
+		l.log.Warningf("module %s failed %d times, give up", name, retries+1)
+	default:
+		delay := policy.delay(retries)
+		l.log.Infof("retry module %s in %s", name, delay)
+		state.retryTimer = time.AfterFunc(delay, func() {
+			l.retry(name)
+		})
+	}
+	handlers := l.failedHandlers
+	failure := state.ModuleFailure
+	l.stateLock.Unlock()
+
+	for _, fn := range handlers {
+		fn(name, failure)
+	}
+}
+
+func (l *Loader) setDependencyFailed(name, dependency string) {
+	l.stateLock.Lock()
+	reason := "dependency " + dependency + " failed"
+	if state, ok := l.failures[dependency]; ok {
+		reason += ": " + state.Reason
+	}
+	if state, ok := l.failures[name]; ok && state.retryTimer != nil {
+		state.retryTimer.Stop()
+	}
+	failure := ModuleFailure{
+		Reason:     reason,
+		Dependency: dependency,
+		Time:       time.Now(),
+	}
+	l.failures[name] = &failureState{ModuleFailure: failure}
+	handlers := l.failedHandlers
+	l.stateLock.Unlock()
+
+	for _, fn := range handlers {
+		fn(name, failure)
+	}
+}
+
+func (l *Loader) clearFailure(name string) {
+	l.stateLock.Lock()
+	if state, ok := l.failures[name]; ok {
+		if state.retryTimer != nil {
+			state.retryTimer.Stop()
+		}
+		delete(l.failures, name)
+	}
+	l.stateLock.Unlock()
+}
+
+// retry starts the failed module again, along with the modules which were
+// not started because of it.
+func (l *Loader) retry(name string) {
+	l.stateLock.Lock()
+	if _, ok := l.failures[name]; !ok {
+		l.stateLock.Unlock()
+		return
+	}
+	modules := []string{name}
+	for n, state := range l.failures {
+		if state.Dependency == name {
+			modules = append(modules, n)
+		}
+	}
+	args := l.enableArgs[name]
+	l.stateLock.Unlock()
+
+	l.log.Info("retry module", name)
+	err := l.EnableModules(modules, args.disableModules, args.flag)
+	if err != nil {
+		l.log.Warning("retry module", name, "failed:", err)
+	}
+}
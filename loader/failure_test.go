@@ -0,0 +1,175 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	"errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	Convey("Test retry delay is doubled up to the max interval", t, func() {
+		policy := RetryPolicy{
+			MaxRetries:  5,
+			Interval:    time.Second,
+			MaxInterval: 5 * time.Second,
+		}
+		var infos = []struct {
+			retries int
+			delay   time.Duration
+		}{
+			{0, time.Second},
+			{1, 2 * time.Second},
+			{2, 4 * time.Second},
+			{3, 5 * time.Second},
+			{10, 5 * time.Second},
+		}
+		for _, info := range infos {
+			So(policy.delay(info.retries), ShouldEqual, info.delay)
+		}
+	})
+}
+
+func TestRetryFailedModule(t *testing.T) {
+	Convey("Test a module failing several times is retried until started", t, func() {
+		const failTimes = 3
+		a := newFakeModule("a")
+		b := newFakeModule("b", "a")
+		a.start = func() error {
+			starts, _ := a.counts()
+			if starts <= failTimes {
+				return errors.New("not ready")
+			}
+			return nil
+		}
+
+		l := newTestLoader(a, b)
+		l.SetRetryPolicy(RetryPolicy{
+			MaxRetries:  failTimes + 1,
+			Interval:    time.Millisecond,
+			MaxInterval: 10 * time.Millisecond,
+		})
+		var retries []int
+		var retriesLock sync.Mutex
+		l.ConnectModuleFailed(func(name string, failure ModuleFailure) {
+			if name == "a" {
+				retriesLock.Lock()
+				retries = append(retries, failure.Retries)
+				retriesLock.Unlock()
+			}
+		})
+		So(l.EnableModules([]string{"b"}, nil, EnableFlagNone), ShouldBeNil)
+
+		// the dependent is started along with the retried module
+		So(waitFor(b.IsEnable, 2*time.Second), ShouldBeTrue)
+		So(a.IsEnable(), ShouldBeTrue)
+		starts, _ := a.counts()
+		So(starts, ShouldEqual, failTimes+1)
+		retriesLock.Lock()
+		So(retries, ShouldResemble, []int{0, 1, 2})
+		retriesLock.Unlock()
+		_, ok := l.GetFailure("a")
+		So(ok, ShouldBeFalse)
+		_, ok = l.GetFailure("b")
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Test retrying stops after max retries", t, func() {
+		a := newFakeModule("a")
+		a.start = func() error {
+			return errors.New("broken")
+		}
+
+		l := newTestLoader(a)
+		l.SetRetryPolicy(RetryPolicy{
+			MaxRetries:  2,
+			Interval:    time.Millisecond,
+			MaxInterval: time.Millisecond,
+		})
+		So(l.EnableModules([]string{"a"}, nil, EnableFlagNone), ShouldBeNil)
+
+		So(waitFor(func() bool {
+			failure, _ := l.GetFailure("a")
+			return failure.Retries == 2
+		}, 2*time.Second), ShouldBeTrue)
+		time.Sleep(20 * time.Millisecond)
+		starts, _ := a.counts()
+		So(starts, ShouldEqual, 3)
+	})
+}
+
+func TestRetryEnableArgs(t *testing.T) {
+	Convey("Test a failed module is retried with the arguments it was enabled with", t, func() {
+		a := newFakeModule("a")
+		b := newFakeModule("b", "a")
+		c := newFakeModule("c")
+		a.start = func() error {
+			if starts, _ := a.counts(); starts == 1 {
+				return errors.New("not ready")
+			}
+			return nil
+		}
+
+		l := newTestLoader(a, b, c)
+		l.SetRetryPolicy(RetryPolicy{
+			MaxRetries:  1,
+			Interval:    time.Millisecond,
+			MaxInterval: time.Millisecond,
+		})
+		flag := EnableFlagForceStart
+		So(l.EnableModules([]string{"b"}, []string{"a", "c"}, flag), ShouldBeNil)
+		So(waitFor(b.IsEnable, 2*time.Second), ShouldBeTrue)
+
+		l.stateLock.Lock()
+		args := l.enableArgs["a"]
+		l.stateLock.Unlock()
+		So(args.disableModules, ShouldResemble, []string{"a", "c"})
+		So(args.flag, ShouldEqual, flag)
+		// the disabled module which is not a dependency is not started
+		So(c.IsEnable(), ShouldBeFalse)
+	})
+}
+
+func TestFailureIsolation(t *testing.T) {
+	Convey("Test dependents of a failed module are skipped while other branches start", t, func() {
+		// a -> b -> c
+		// d -> e
+		a := newFakeModule("a")
+		b := newFakeModule("b", "a")
+		c := newFakeModule("c", "b")
+		d := newFakeModule("d")
+		e := newFakeModule("e", "d")
+		b.start = func() error {
+			return errors.New("broken")
+		}
+
+		l := newTestLoader(a, b, c, d, e)
+		So(l.EnableModules([]string{"c", "e"}, nil, EnableFlagNone), ShouldBeNil)
+
+		So(a.IsEnable(), ShouldBeTrue)
+		So(b.IsEnable(), ShouldBeFalse)
+		So(c.IsEnable(), ShouldBeFalse)
+		So(d.IsEnable(), ShouldBeTrue)
+		So(e.IsEnable(), ShouldBeTrue)
+
+		starts, _ := c.counts()
+		So(starts, ShouldEqual, 0)
+		failure, ok := l.GetFailure("c")
+		So(ok, ShouldBeTrue)
+		So(failure.Dependency, ShouldEqual, "b")
+		for _, name := range []string{"a", "d", "e"} {
+			_, ok := l.GetFailure(name)
+			So(ok, ShouldBeFalse)
+		}
+	})
+}
@@ -10,8 +10,10 @@
 package loader
 
 import (
+	"io/ioutil"
 	"pkg.deepin.io/lib/log"
 	"sync"
+	"time"
 )
 
 var loaderInitializer sync.Once
@@ -20,10 +22,7 @@ var getLoader = func() func() *Loader {
 	var loader *Loader
 	return func() *Loader {
 		loaderInitializer.Do(func() {
-			loader = &Loader{
-				modules: map[string]Module{},
-				log:     log.NewLogger("daemon/loader"),
-			}
+			loader = newLoader(log.NewLogger("daemon/loader"))
 		})
 		return loader
 	}
@@ -50,6 +49,63 @@ func EnableModules(enablingModules []string, disableModules []string, flag Enabl
 	return getLoader().EnableModules(enablingModules, disableModules, flag)
 }
 
+func DisableModules(disableModules []string) ([]string, error) {
+	return getLoader().DisableModules(disableModules)
+}
+
+func CheckModules(enablingModules []string, disableModules []string, flag EnableFlag) error {
+	return getLoader().CheckModules(enablingModules, disableModules, flag)
+}
+
+func GetGraph() *ModuleGraph {
+	return getLoader().GetGraph()
+}
+
+func GetStartDuration(name string) time.Duration {
+	return getLoader().GetStartDuration(name)
+}
+
+func GetFailure(name string) (ModuleFailure, bool) {
+	return getLoader().GetFailure(name)
+}
+
+func SetRetryPolicy(policy RetryPolicy) {
+	getLoader().SetRetryPolicy(policy)
+}
+
+func ConnectModuleFailed(fn func(name string, failure ModuleFailure)) {
+	getLoader().ConnectModuleFailed(fn)
+}
+
+func StartWatchdog(config WatchdogConfig) {
+	getLoader().StartWatchdog(config)
+}
+
+func StopWatchdog() {
+	getLoader().StopWatchdog()
+}
+
+func ConnectModuleRestarted(fn func(name string, reason string, err error)) {
+	getLoader().ConnectModuleRestarted(fn)
+}
+
+func GetTrace() []TraceEvent {
+	return getLoader().GetTrace()
+}
+
+func ExportTrace(format string) ([]byte, error) {
+	return getLoader().ExportTrace(format)
+}
+
+// WriteTrace writes the recorded trace events to the file in the format.
+func WriteTrace(filename, format string) error {
+	data, err := ExportTrace(format)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
 func ToggleLogDebug(enabled bool) {
 	var priority log.Priority = log.LevelInfo
 	if enabled {
@@ -69,10 +125,12 @@ func StartAll() {
 	getLoader().EnableModules(modules, []string{}, EnableFlagNone)
 }
 
-// TODO: check dependencies
+// StopAll stops all the modules in reverse dependency order, with
+// DefaultStopTimeout for each module.
 func StopAll() {
-	modules := getLoader().List()
-	for _, module := range modules {
-		module.Enable(false)
-	}
+	Shutdown(DefaultStopTimeout)
+}
+
+func Shutdown(timeout time.Duration) []string {
+	return getLoader().Shutdown(timeout)
 }
@@ -11,6 +11,7 @@ package loader
 
 import (
 	"fmt"
+	"pkg.deepin.io/dde/daemon/graph"
 	"pkg.deepin.io/lib/log"
 	"sort"
 	"sync"
@@ -78,6 +79,31 @@ type Loader struct {
 	modules map[string]Module
 	log     *log.Logger
 	lock    sync.Mutex
+
+	// stateLock protects the runtime states of the modules.
+	stateLock   sync.Mutex
+	durations   map[string]time.Duration
+	failures    map[string]*failureState
+	retryPolicy RetryPolicy
+	// enableArgs are the arguments of EnableModules which started the
+	// modules, the failed modules are retried with them.
+	enableArgs map[string]enableArgs
+
+	failedHandlers []func(name string, failure ModuleFailure)
+
+	watchdog watchdog
+	tracer   tracer
+}
+
+func newLoader(logger *log.Logger) *Loader {
+	return &Loader{
+		modules:     map[string]Module{},
+		log:         logger,
+		durations:   map[string]time.Duration{},
+		failures:    map[string]*failureState{},
+		retryPolicy: DefaultRetryPolicy,
+		enableArgs:  map[string]enableArgs{},
+	}
 }
 
 func (l *Loader) SetLogLevel(pri log.Priority) {
@@ -145,18 +171,227 @@ func (l *Loader) EnableModules(enablingModules []string, disableModules []string
 		return &EnableError{Code: ErrorCircleDependencies}
 	}
 
+	args := enableArgs{disableModules: disableModules, flag: flag}
+	l.stateLock.Lock()
 	for _, node := range nodes {
-		module := l.modules[node.ID]
-		l.log.Info("enable module", node.ID)
-		startTime := time.Now()
-		err := module.Enable(true)
-		endTime := time.Now()
-		duration := endTime.Sub(startTime)
-		if err != nil {
-			l.log.Fatalf("enable module %s failed: %s, cost %s", node.ID, err, duration)
-		}
-		l.log.Info("enable module", node.ID, "done, cost", duration)
+		l.enableArgs[node.ID] = args
+	}
+	l.stateLock.Unlock()
+
+	l.startModules(nodes)
+	return nil
+}
+
+// CheckModules checks whether the modules could be enabled, without starting
+// any module.
+func (l *Loader) CheckModules(enablingModules []string, disableModules []string, flag EnableFlag) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	builder := NewDAGBuilder(l, enablingModules, disableModules, flag)
+	dag, err := builder.Execute()
+	if err != nil {
+		return err
 	}
 
+	if _, ok := dag.TopologicalDag(); !ok {
+		return &EnableError{Code: ErrorCircleDependencies}
+	}
 	return nil
 }
+
+type startResult struct {
+	name     string
+	err      error
+	duration time.Duration
+}
+
+// startModules starts the modules of the DAG, a module is started once all of
+// its dependencies are started, so that independent branches are started at
+// the same time. Modules which must run on the main thread are started by the
+// calling goroutine. A module failing to start marks the modules depending on
+// it as failed, the other modules keep starting.
+func (l *Loader) startModules(nodes []*graph.Node) {
+	results := make(chan startResult, len(nodes))
+	mainThreadQueue := []string{}
+	// readyTimes records when the dependencies of the queued modules
+	// became ready.
+	readyTimes := map[string]time.Time{}
+	waitingDeps := map[string]int{}
+	dependents := map[string][]string{}
+
+	dispatch := func(name string) {
+		module := l.modules[name]
+		if module.IsEnable() {
+			l.log.Debug("module", name, "is already enabled")
+			results <- startResult{name: name}
+			return
+		}
+
+		readyTime := time.Now()
+		if runOnMainThread(module) {
+			mainThreadQueue = append(mainThreadQueue, name)
+			readyTimes[name] = readyTime
+			return
+		}
+
+		go func() {
+			l.tracer.add(name, TraceEventWait, readyTime, time.Now(), nil)
+			results <- l.startModule(module)
+		}()
+	}
+
+	for _, node := range nodes {
+		waitingDeps[node.ID] = len(node.WeightFrom)
+		for dependent := range node.WeightTo {
+			dependents[node.ID] = append(dependents[node.ID], dependent.ID)
+		}
+	}
+
+	for _, node := range nodes {
+		if waitingDeps[node.ID] == 0 {
+			dispatch(node.ID)
+		}
+	}
+
+	// finished contains the started modules and the failed modules.
+	finished := map[string]struct{}{}
+	var markDependentsFailed func(name, cause string)
+	markDependentsFailed = func(name, cause string) {
+		for _, dependent := range dependents[name] {
+			if _, ok := finished[dependent]; ok {
+				continue
+			}
+			finished[dependent] = struct{}{}
+			l.log.Warningf("skip module %s, its dependency %s failed", dependent, cause)
+			l.setDependencyFailed(dependent, cause)
+			markDependentsFailed(dependent, cause)
+		}
+	}
+
+	for len(finished) < len(nodes) {
+		var result startResult
+		if len(mainThreadQueue) > 0 {
+			name := mainThreadQueue[0]
+			mainThreadQueue = mainThreadQueue[1:]
+			l.tracer.add(name, TraceEventWait, readyTimes[name], time.Now(), nil)
+			result = l.startModule(l.modules[name])
+		} else {
+			result = <-results
+		}
+
+		finished[result.name] = struct{}{}
+		if result.err != nil {
+			l.log.Warningf("enable module %s failed: %s, cost %s", result.name, result.err, result.duration)
+			l.setModuleFailed(l.modules[result.name], result.err)
+			markDependentsFailed(result.name, result.name)
+			continue
+		}
+		l.clearFailure(result.name)
+
+		for _, name := range dependents[result.name] {
+			if _, ok := finished[name]; ok {
+				continue
+			}
+			waitingDeps[name]--
+			if waitingDeps[name] == 0 {
+				dispatch(name)
+			}
+		}
+	}
+}
+
+func (l *Loader) startModule(module Module) startResult {
+	name := module.Name()
+	l.log.Info("enable module", name)
+	startTime := time.Now()
+	err := module.Enable(true)
+	endTime := time.Now()
+	duration := endTime.Sub(startTime)
+	l.tracer.add(name, TraceEventStart, startTime, endTime, err)
+	if err == nil {
+		l.stateLock.Lock()
+		l.durations[name] = duration
+		l.stateLock.Unlock()
+		l.log.Info("enable module", name, "done, cost", duration)
+	}
+	return startResult{name: name, err: err, duration: duration}
+}
+
+func (l *Loader) stopModule(module Module) error {
+	name := module.Name()
+	l.log.Info("disable module", name)
+	startTime := time.Now()
+	err := module.Enable(false)
+	l.tracer.add(name, TraceEventStop, startTime, time.Now(), err)
+	return err
+}
+
+// GetStartDuration returns how long the last Start of the module took.
+func (l *Loader) GetStartDuration(name string) time.Duration {
+	l.stateLock.Lock()
+	defer l.stateLock.Unlock()
+	return l.durations[name]
+}
+
+// DisableModules stops the modules and every enabled module depending on
+// them, dependents first. It returns the names of the stopped modules.
+func (l *Loader) DisableModules(disableModules []string) ([]string, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, name := range disableModules {
+		if _, ok := l.modules[name]; !ok {
+			return nil, &EnableError{ModuleName: name, Code: ErrorMissingModule}
+		}
+	}
+
+	var stopped []string
+	visited := map[string]struct{}{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		if _, ok := visited[name]; ok {
+			return nil
+		}
+		visited[name] = struct{}{}
+
+		for _, dependent := range l.getDependents(name) {
+			if err := visit(dependent); err != nil {
+				return err
+			}
+		}
+
+		l.clearFailure(name)
+		module := l.modules[name]
+		if !module.IsEnable() {
+			return nil
+		}
+		if err := l.stopModule(module); err != nil {
+			return &EnableError{ModuleName: name, Code: ErrorInternalError, detail: err.Error()}
+		}
+		stopped = append(stopped, name)
+		return nil
+	}
+
+	for _, name := range disableModules {
+		if err := visit(name); err != nil {
+			return stopped, err
+		}
+	}
+	return stopped, nil
+}
+
+// getDependents returns the modules which directly depend on the module.
+func (l *Loader) getDependents(name string) []string {
+	var dependents []string
+	for _, module := range l.modules {
+		for _, dependency := range module.GetDependencies() {
+			if dependency == name {
+				dependents = append(dependents, module.Name())
+				break
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
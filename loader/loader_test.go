@@ -0,0 +1,369 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	"bytes"
+	"errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"pkg.deepin.io/lib/log"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeModule records its starts and stops, the behaviors of Start and Stop
+// are replaced by the tests.
+type fakeModule struct {
+	*ModuleBase
+	deps       []string
+	mainThread bool
+	start      func() error
+	stop       func() error
+
+	lock   sync.Mutex
+	starts int
+	stops  int
+}
+
+func newFakeModule(name string, deps ...string) *fakeModule {
+	m := &fakeModule{deps: deps}
+	m.ModuleBase = NewModuleBase(name, m, log.NewLogger("test/"+name))
+	return m
+}
+
+func (m *fakeModule) GetDependencies() []string {
+	return m.deps
+}
+
+func (m *fakeModule) RunOnMainThread() bool {
+	return m.mainThread
+}
+
+func (m *fakeModule) Start() error {
+	m.lock.Lock()
+	m.starts++
+	m.lock.Unlock()
+	if m.start != nil {
+		return m.start()
+	}
+	return nil
+}
+
+func (m *fakeModule) Stop() error {
+	m.lock.Lock()
+	m.stops++
+	m.lock.Unlock()
+	if m.stop != nil {
+		return m.stop()
+	}
+	return nil
+}
+
+func (m *fakeModule) counts() (starts, stops int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.starts, m.stops
+}
+
+func newTestLoader(modules ...Module) *Loader {
+	l := newLoader(log.NewLogger("test/loader"))
+	// no retry unless the test wants it
+	l.retryPolicy = RetryPolicy{}
+	for _, m := range modules {
+		l.AddModule(m)
+	}
+	return l
+}
+
+// startRecorder records the order of the started modules.
+type startRecorder struct {
+	lock  sync.Mutex
+	names []string
+}
+
+func (r *startRecorder) record(name string) func() error {
+	return func() error {
+		r.lock.Lock()
+		r.names = append(r.names, name)
+		r.lock.Unlock()
+		return nil
+	}
+}
+
+func (r *startRecorder) index(name string) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i, n := range r.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func goroutineID() int {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	// goroutine 18 [running]:
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	id, _ := strconv.Atoi(string(buf[:bytes.IndexByte(buf, ' ')]))
+	return id
+}
+
+// waitFor polls cond until it returns true or the timeout expires.
+func waitFor(cond func() bool, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return cond()
+}
+
+func TestDisableModules(t *testing.T) {
+	Convey("Test disabling a module stops its enabled dependents first", t, func() {
+		// a -> b -> c
+		//   -> d
+		a := newFakeModule("a")
+		b := newFakeModule("b", "a")
+		c := newFakeModule("c", "b")
+		d := newFakeModule("d", "a")
+		e := newFakeModule("e")
+
+		l := newTestLoader(a, b, c, d, e)
+		So(l.EnableModules([]string{"b", "e"}, nil, EnableFlagNone), ShouldBeNil)
+
+		recorder := &startRecorder{}
+		for _, m := range []*fakeModule{a, b, c, d, e} {
+			m.stop = recorder.record(m.Name())
+		}
+		stopped, err := l.DisableModules([]string{"a"})
+		So(err, ShouldBeNil)
+		// c and d are not enabled, they are not stopped
+		So(stopped, ShouldResemble, []string{"b", "a"})
+		So(recorder.names, ShouldResemble, []string{"b", "a"})
+		So(a.IsEnable(), ShouldBeFalse)
+		So(b.IsEnable(), ShouldBeFalse)
+		So(e.IsEnable(), ShouldBeTrue)
+		_, stops := c.counts()
+		So(stops, ShouldEqual, 0)
+
+		_, err = l.DisableModules([]string{"missing"})
+		So(err, ShouldNotBeNil)
+		So(err.(*EnableError).Code, ShouldEqual, ErrorMissingModule)
+	})
+
+	Convey("Test disabling a module required by an enabled module is rejected", t, func() {
+		a := newFakeModule("a")
+		b := newFakeModule("b", "a")
+
+		// the session daemon disables modules by enabling the others
+		// with the disabled list
+		l := newTestLoader(a, b)
+		err := l.EnableModules([]string{"b"}, []string{"a"}, EnableFlagNone)
+		So(err, ShouldNotBeNil)
+		So(err.(*EnableError).Code, ShouldEqual, ErrorConflict)
+		So(a.IsEnable(), ShouldBeFalse)
+		So(b.IsEnable(), ShouldBeFalse)
+
+		// unless the start is forced
+		So(l.EnableModules([]string{"b"}, []string{"a"}, EnableFlagForceStart), ShouldBeNil)
+		So(b.IsEnable(), ShouldBeTrue)
+	})
+}
+
+func TestStartModulesParallelBranches(t *testing.T) {
+	Convey("Test independent branches are started in parallel", t, func() {
+		// a -> b -> d
+		//   -> c ->
+		a := newFakeModule("a")
+		b := newFakeModule("b", "a")
+		c := newFakeModule("c", "a")
+		d := newFakeModule("d", "b", "c")
+
+		recorder := &startRecorder{}
+		a.start = recorder.record("a")
+		d.start = recorder.record("d")
+		// b and c are blocked until both of them are started, so they
+		// must run at the same time
+		var started sync.WaitGroup
+		started.Add(2)
+		parallel := func(name string) func() error {
+			return func() error {
+				recorder.record(name)()
+				started.Done()
+				done := make(chan struct{})
+				go func() {
+					started.Wait()
+					close(done)
+				}()
+				select {
+				case <-done:
+					return nil
+				case <-time.After(time.Second):
+					return errors.New("branches are not started in parallel")
+				}
+			}
+		}
+		b.start = parallel("b")
+		c.start = parallel("c")
+
+		l := newTestLoader(a, b, c, d)
+		So(l.EnableModules([]string{"d"}, nil, EnableFlagNone), ShouldBeNil)
+
+		for _, m := range []*fakeModule{a, b, c, d} {
+			So(m.IsEnable(), ShouldBeTrue)
+		}
+		So(recorder.index("a"), ShouldEqual, 0)
+		So(recorder.index("d"), ShouldEqual, 3)
+	})
+}
+
+func TestStartModulesWaitDependencies(t *testing.T) {
+	Convey("Test modules wait for their dependencies", t, func() {
+		a := newFakeModule("a")
+		b := newFakeModule("b", "a")
+		var aDone time.Time
+		a.start = func() error {
+			time.Sleep(50 * time.Millisecond)
+			aDone = time.Now()
+			return nil
+		}
+		var bStarted time.Time
+		b.start = func() error {
+			bStarted = time.Now()
+			return nil
+		}
+
+		l := newTestLoader(a, b)
+		So(l.EnableModules([]string{"b"}, nil, EnableFlagNone), ShouldBeNil)
+		So(b.IsEnable(), ShouldBeTrue)
+		So(bStarted.Before(aDone), ShouldBeFalse)
+
+		// already enabled modules are not started again
+		So(l.EnableModules([]string{"b"}, nil, EnableFlagNone), ShouldBeNil)
+		starts, _ := a.counts()
+		So(starts, ShouldEqual, 1)
+		starts, _ = b.counts()
+		So(starts, ShouldEqual, 1)
+	})
+}
+
+func TestStartModulesMainThreadQueue(t *testing.T) {
+	Convey("Test RunOnMainThread modules are started by the calling goroutine", t, func() {
+		a := newFakeModule("a")
+		b := newFakeModule("b", "a")
+		c := newFakeModule("c")
+		d := newFakeModule("d", "b", "c")
+		b.mainThread = true
+		d.mainThread = true
+
+		ids := map[string]int{}
+		var lock sync.Mutex
+		for _, m := range []*fakeModule{a, b, c, d} {
+			name := m.Name()
+			m.start = func() error {
+				lock.Lock()
+				ids[name] = goroutineID()
+				lock.Unlock()
+				return nil
+			}
+		}
+
+		l := newTestLoader(a, b, c, d)
+		So(l.EnableModules([]string{"d"}, nil, EnableFlagNone), ShouldBeNil)
+
+		mainID := goroutineID()
+		So(ids["b"], ShouldEqual, mainID)
+		So(ids["d"], ShouldEqual, mainID)
+		So(ids["a"], ShouldNotEqual, mainID)
+		So(ids["c"], ShouldNotEqual, mainID)
+
+		// the wait of queued modules is traced
+		var waits []string
+		for _, ev := range l.GetTrace() {
+			if ev.Kind == TraceEventWait {
+				waits = append(waits, ev.Module)
+				So(ev.End.Before(ev.Begin), ShouldBeFalse)
+			}
+		}
+		So(waits, ShouldContain, "b")
+		So(waits, ShouldContain, "d")
+	})
+}
+
+func TestStartModulesFailurePropagation(t *testing.T) {
+	Convey("Test a failed module fails its dependents", t, func() {
+		// a -> b -> c
+		a := newFakeModule("a")
+		b := newFakeModule("b", "a")
+		c := newFakeModule("c", "b")
+		a.start = func() error {
+			return errors.New("no session bus")
+		}
+
+		var failed []string
+		l := newTestLoader(a, b, c)
+		l.ConnectModuleFailed(func(name string, failure ModuleFailure) {
+			failed = append(failed, name)
+		})
+		So(l.EnableModules([]string{"c"}, nil, EnableFlagNone), ShouldBeNil)
+
+		So(a.IsEnable(), ShouldBeFalse)
+		So(b.IsEnable(), ShouldBeFalse)
+		So(c.IsEnable(), ShouldBeFalse)
+		starts, _ := b.counts()
+		So(starts, ShouldEqual, 0)
+		starts, _ = c.counts()
+		So(starts, ShouldEqual, 0)
+		So(failed, ShouldResemble, []string{"a", "b", "c"})
+
+		failure, ok := l.GetFailure("a")
+		So(ok, ShouldBeTrue)
+		So(failure.Reason, ShouldEqual, "no session bus")
+		So(failure.Dependency, ShouldEqual, "")
+		failure, ok = l.GetFailure("c")
+		So(ok, ShouldBeTrue)
+		So(failure.Dependency, ShouldEqual, "a")
+		So(failure.Reason, ShouldEqual, "dependency a failed: no session bus")
+	})
+}
+
+func TestModuleBaseEnable(t *testing.T) {
+	Convey("Test enabling a module concurrently starts it once", t, func() {
+		m := newFakeModule("a")
+		m.start = func() error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				m.Enable(true)
+				wg.Done()
+			}()
+		}
+		wg.Wait()
+
+		starts, _ := m.counts()
+		So(starts, ShouldEqual, 1)
+		So(m.IsEnable(), ShouldBeTrue)
+		So(m.Enable(true), ShouldNotBeNil)
+		So(m.Enable(false), ShouldBeNil)
+		So(m.IsEnable(), ShouldBeFalse)
+	})
+}
@@ -12,6 +12,8 @@ package loader
 import (
 	"fmt"
 	"pkg.deepin.io/lib/log"
+	"sync"
+	"sync/atomic"
 )
 
 type Module interface {
@@ -29,11 +31,36 @@ type ModuleImpl interface {
 	Stop() error
 }
 
+// HealthChecker is implemented by modules which can tell whether they are
+// still working, e.g. their D-Bus proxies or X connection are alive. The
+// watchdog restarts the module when CheckHealth returns an error.
+type HealthChecker interface {
+	CheckHealth() error
+}
+
+// MainThreadModule is implemented by modules whose Start must be called on
+// the thread calling EnableModules, which is locked to the main loop thread.
+// Other modules are started concurrently once their dependencies are started.
+type MainThreadModule interface {
+	RunOnMainThread() bool
+}
+
+func runOnMainThread(module Module) bool {
+	m, ok := module.(MainThreadModule)
+	return ok && m.RunOnMainThread()
+}
+
 type ModuleBase struct {
-	impl    ModuleImpl
-	enabled bool
-	name    string
-	log     *log.Logger
+	impl ModuleImpl
+	name string
+	log  *log.Logger
+
+	// enableLock serializes Enable, modules are started and stopped from
+	// several goroutines by the loader and the watchdog.
+	enableLock sync.Mutex
+	// enabled is accessed atomically, so IsEnable doesn't block while the
+	// module is starting or stopping.
+	enabled int32
 }
 
 func NewModuleBase(name string, impl ModuleImpl, logger *log.Logger) *ModuleBase {
@@ -55,19 +82,26 @@ func (d *ModuleBase) doEnable(enable bool) error {
 			return err
 		}
 	}
-	d.enabled = enable
+	var enabled int32
+	if enable {
+		enabled = 1
+	}
+	atomic.StoreInt32(&d.enabled, enabled)
 	return nil
 }
 
 func (d *ModuleBase) Enable(enable bool) error {
-	if d.enabled == enable {
+	d.enableLock.Lock()
+	defer d.enableLock.Unlock()
+
+	if d.IsEnable() == enable {
 		return fmt.Errorf("%s daemon is already started", d.name)
 	}
 	return d.doEnable(enable)
 }
 
 func (d *ModuleBase) IsEnable() bool {
-	return d.enabled
+	return atomic.LoadInt32(&d.enabled) != 0
 }
 
 func (d *ModuleBase) Name() string {
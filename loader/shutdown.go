@@ -0,0 +1,105 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	"sort"
+	"time"
+
+	"pkg.deepin.io/dde/daemon/graph"
+)
+
+var DefaultStopTimeout = 3 * time.Second
+
+// shutdownOrder returns the enabled modules, the modules depending on
+// others come first.
+func (l *Loader) shutdownOrder() []string {
+	dag := graph.New()
+	nodes := map[string]*graph.Node{}
+	for name, module := range l.modules {
+		node := createNodeIfNeeded(dag, nodes, name)
+		for _, dependency := range module.GetDependencies() {
+			if _, ok := l.modules[dependency]; !ok {
+				continue
+			}
+			depNode := createNodeIfNeeded(dag, nodes, dependency)
+			dag.UpdateEdgeWeight(depNode, node, 0)
+		}
+	}
+
+	var order []string
+	sorted, ok := dag.TopologicalDag()
+	if ok {
+		for i := len(sorted) - 1; i >= 0; i-- {
+			order = append(order, sorted[i].ID)
+		}
+	} else {
+		l.log.Warning("dependency circle, stop modules by name")
+		for name := range l.modules {
+			order = append(order, name)
+		}
+		sort.Strings(order)
+	}
+
+	var enabled []string
+	for _, name := range order {
+		if l.modules[name].IsEnable() {
+			enabled = append(enabled, name)
+		}
+	}
+	return enabled
+}
+
+// Shutdown stops the enabled modules in reverse dependency order. Each
+// module has timeout to stop, the modules missing the deadline are left
+// behind and returned. The loader lock is not held while stopping, so a
+// hanging module doesn't block the other users of the loader.
+func (l *Loader) Shutdown(timeout time.Duration) []string {
+	l.StopWatchdog()
+
+	l.lock.Lock()
+	for name := range l.modules {
+		l.clearFailure(name)
+	}
+	var modules []Module
+	for _, name := range l.shutdownOrder() {
+		modules = append(modules, l.modules[name])
+	}
+	l.lock.Unlock()
+
+	var timedOut []string
+	for _, module := range modules {
+		name := module.Name()
+		startTime := time.Now()
+		done := make(chan error, 1)
+		go func(module Module) {
+			done <- l.stopModule(module)
+		}(module)
+
+		select {
+		case err := <-done:
+			if err != nil {
+				l.log.Warningf("stop module %s failed: %v", name, err)
+			}
+		case <-time.After(timeout):
+			l.log.Warningf("stop module %s timeout after %s", name, timeout)
+			timedOut = append(timedOut, name)
+			go l.waitLeakedStop(name, startTime, done)
+		}
+	}
+	return timedOut
+}
+
+// waitLeakedStop logs the result of the stop which missed the deadline.
+func (l *Loader) waitLeakedStop(name string, startTime time.Time, done <-chan error) {
+	err := <-done
+	l.log.Warningf("leaked stop of module %s finished after %s, err: %v",
+		name, time.Since(startTime), err)
+}
@@ -0,0 +1,82 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	Convey("Test modules are stopped before their dependencies", t, func() {
+		// a -> b -> c
+		recorder := &startRecorder{}
+		var modules []Module
+		for _, m := range []*fakeModule{
+			newFakeModule("a"),
+			newFakeModule("b", "a"),
+			newFakeModule("c", "b"),
+		} {
+			m.stop = recorder.record(m.Name())
+			modules = append(modules, m)
+		}
+		l := newTestLoader(modules...)
+		So(l.EnableModules([]string{"c"}, nil, EnableFlagNone), ShouldBeNil)
+
+		So(l.Shutdown(time.Second), ShouldBeEmpty)
+		So(recorder.names, ShouldResemble, []string{"c", "b", "a"})
+	})
+}
+
+func TestShutdownHangingStop(t *testing.T) {
+	Convey("Test a hanging Stop is left behind without blocking the loader", t, func() {
+		a := newFakeModule("a")
+		b := newFakeModule("b", "a")
+		release := make(chan struct{})
+		b.stop = func() error {
+			<-release
+			return nil
+		}
+		l := newTestLoader(a, b)
+		So(l.EnableModules([]string{"b"}, nil, EnableFlagNone), ShouldBeNil)
+
+		So(l.Shutdown(20*time.Millisecond), ShouldResemble, []string{"b"})
+		_, stops := a.counts()
+		So(stops, ShouldEqual, 1)
+		So(a.IsEnable(), ShouldBeFalse)
+
+		// the loader lock is released while b is still stopping
+		got := make(chan Module, 1)
+		go func() {
+			got <- l.GetModule("b")
+		}()
+		var module Module
+		select {
+		case module = <-got:
+		case <-time.After(time.Second):
+		}
+		So(module, ShouldEqual, b)
+
+		// the leaked stop finishes later
+		close(release)
+		So(waitFor(func() bool {
+			return !b.IsEnable()
+		}, time.Second), ShouldBeTrue)
+		So(waitFor(func() bool {
+			for _, ev := range l.GetTrace() {
+				if ev.Module == "b" && ev.Kind == TraceEventStop {
+					return true
+				}
+			}
+			return false
+		}, time.Second), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,146 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	TraceEventStart = "start"
+	TraceEventStop  = "stop"
+	// TraceEventWait is the time a module waits to be started after its
+	// dependencies are ready, e.g. in the main thread queue.
+	TraceEventWait = "wait"
+)
+
+const (
+	TraceFormatJSON   = "json"
+	TraceFormatChrome = "chrome"
+)
+
+type TraceEvent struct {
+	Module string
+	Kind   string
+	Begin  time.Time
+	End    time.Time
+	Error  string `json:",omitempty"`
+}
+
+type tracer struct {
+	lock   sync.Mutex
+	events []TraceEvent
+}
+
+func (t *tracer) add(module, kind string, begin, end time.Time, err error) {
+	ev := TraceEvent{
+		Module: module,
+		Kind:   kind,
+		Begin:  begin,
+		End:    end,
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+
+	t.lock.Lock()
+	t.events = append(t.events, ev)
+	t.lock.Unlock()
+}
+
+func (t *tracer) list() []TraceEvent {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	events := make([]TraceEvent, len(t.events))
+	copy(events, t.events)
+	return events
+}
+
+// GetTrace returns the recorded start, stop and dependency wait events of the
+// modules.
+func (l *Loader) GetTrace() []TraceEvent {
+	return l.tracer.list()
+}
+
+// ExportTrace encodes the recorded events in the format, which is either
+// TraceFormatJSON or TraceFormatChrome.
+func (l *Loader) ExportTrace(format string) ([]byte, error) {
+	events := l.tracer.list()
+	switch format {
+	case TraceFormatJSON:
+		return json.Marshal(events)
+	case TraceFormatChrome:
+		return marshalChromeTrace(events)
+	}
+	return nil, fmt.Errorf("unknown trace format %q", format)
+}
+
+type chromeTraceEvent struct {
+	Name      string            `json:"name"`
+	Category  string            `json:"cat"`
+	Phase     string            `json:"ph"`
+	Timestamp int64             `json:"ts"`
+	Duration  int64             `json:"dur"`
+	Pid       int               `json:"pid"`
+	Tid       int               `json:"tid"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+// marshalChromeTrace encodes the events as the trace event format of
+// chrome://tracing, each module is shown as a thread.
+// (https://github.com/catapult-project/catapult/tree/master/tracing)
+func marshalChromeTrace(events []TraceEvent) ([]byte, error) {
+	var origin time.Time
+	for _, ev := range events {
+		if origin.IsZero() || ev.Begin.Before(origin) {
+			origin = ev.Begin
+		}
+	}
+
+	tids := map[string]int{}
+	traceEvents := []chromeTraceEvent{}
+	for _, ev := range events {
+		tid, ok := tids[ev.Module]
+		if !ok {
+			tid = len(tids) + 1
+			tids[ev.Module] = tid
+			traceEvents = append(traceEvents, chromeTraceEvent{
+				Name:  "thread_name",
+				Phase: "M",
+				Pid:   1,
+				Tid:   tid,
+				Args:  map[string]string{"name": ev.Module},
+			})
+		}
+
+		var args map[string]string
+		if ev.Error != "" {
+			args = map[string]string{"error": ev.Error}
+		}
+		traceEvents = append(traceEvents, chromeTraceEvent{
+			Name:      ev.Module + " " + ev.Kind,
+			Category:  ev.Kind,
+			Phase:     "X",
+			Timestamp: int64(ev.Begin.Sub(origin) / time.Microsecond),
+			Duration:  int64(ev.End.Sub(ev.Begin) / time.Microsecond),
+			Pid:       1,
+			Tid:       tid,
+			Args:      args,
+		})
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"traceEvents":     traceEvents,
+		"displayTimeUnit": "ms",
+	})
+}
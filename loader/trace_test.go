@@ -0,0 +1,55 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	"bytes"
+	"errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func newTestTraceLoader() *Loader {
+	l := newTestLoader()
+	origin := time.Date(2017, 6, 1, 8, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time {
+		return origin.Add(time.Duration(ms) * time.Millisecond)
+	}
+	l.tracer.add("a", TraceEventStart, at(0), at(10), nil)
+	l.tracer.add("b", TraceEventWait, at(10), at(12), nil)
+	l.tracer.add("b", TraceEventStart, at(12), at(30), errors.New("broken"))
+	l.tracer.add("a", TraceEventStop, at(100), at(101), nil)
+	return l
+}
+
+func TestExportTrace(t *testing.T) {
+	Convey("Test exporting trace in the formats", t, func() {
+		l := newTestTraceLoader()
+		var infos = []struct {
+			format string
+			golden string
+		}{
+			{TraceFormatJSON, "testdata/trace.json"},
+			{TraceFormatChrome, "testdata/trace_chrome.json"},
+		}
+		for _, info := range infos {
+			data, err := l.ExportTrace(info.format)
+			So(err, ShouldBeNil)
+			golden, err := ioutil.ReadFile(info.golden)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, string(bytes.TrimSpace(golden)))
+		}
+
+		_, err := l.ExportTrace("xml")
+		So(err, ShouldNotBeNil)
+	})
+}
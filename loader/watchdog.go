@@ -0,0 +1,176 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// WatchdogConfig controls the module health watchdog. A module is restarted
+// at most MaxRestarts times within RestartWindow.
+type WatchdogConfig struct {
+	Interval      time.Duration
+	CheckTimeout  time.Duration
+	MaxRestarts   int
+	RestartWindow time.Duration
+}
+
+var DefaultWatchdogConfig = WatchdogConfig{
+	Interval:      30 * time.Second,
+	CheckTimeout:  5 * time.Second,
+	MaxRestarts:   3,
+	RestartWindow: 10 * time.Minute,
+}
+
+var errCheckHealthTimeout = errors.New("health check timeout")
+
+type watchdog struct {
+	loader   *Loader
+	config   WatchdogConfig
+	quit     chan struct{}
+	restarts map[string][]time.Time
+
+	handlersLock sync.Mutex
+	handlers     []func(name string, reason string, err error)
+}
+
+// StartWatchdog polls the enabled modules implementing HealthChecker and
+// restarts the unhealthy ones.
+func (l *Loader) StartWatchdog(config WatchdogConfig) {
+	l.stateLock.Lock()
+	defer l.stateLock.Unlock()
+
+	if l.watchdog.quit != nil {
+		return
+	}
+	l.watchdog.loader = l
+	l.watchdog.config = config
+	l.watchdog.quit = make(chan struct{})
+	l.watchdog.restarts = map[string][]time.Time{}
+	go l.watchdog.loop(l.watchdog.quit)
+}
+
+func (l *Loader) StopWatchdog() {
+	l.stateLock.Lock()
+	defer l.stateLock.Unlock()
+
+	if l.watchdog.quit == nil {
+		return
+	}
+	close(l.watchdog.quit)
+	l.watchdog.quit = nil
+}
+
+// ConnectModuleRestarted registers fn to be called after the watchdog
+// restarted a module, reason is why the module was unhealthy and err is the
+// error of the restart.
+func (l *Loader) ConnectModuleRestarted(fn func(name string, reason string, err error)) {
+	l.watchdog.handlersLock.Lock()
+	l.watchdog.handlers = append(l.watchdog.handlers, fn)
+	l.watchdog.handlersLock.Unlock()
+}
+
+func (w *watchdog) loop(quit chan struct{}) {
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-quit:
+			return
+		case <-ticker.C:
+			w.checkModules()
+		}
+	}
+}
+
+func (w *watchdog) checkModules() {
+	for _, module := range w.loader.List() {
+		checker, ok := module.(HealthChecker)
+		if !ok || !module.IsEnable() {
+			continue
+		}
+
+		err := w.checkHealth(checker)
+		if err == nil {
+			continue
+		}
+		w.loader.log.Warningf("module %s is unhealthy: %v", module.Name(), err)
+		w.restart(module, err)
+	}
+}
+
+func (w *watchdog) checkHealth(checker HealthChecker) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- checker.CheckHealth()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(w.config.CheckTimeout):
+		return errCheckHealthTimeout
+	}
+}
+
+// allowRestart checks whether the module has been restarted too many times
+// in the restart window.
+func (w *watchdog) allowRestart(name string, now time.Time) bool {
+	var recent []time.Time
+	for _, t := range w.restarts[name] {
+		if now.Sub(t) < w.config.RestartWindow {
+			recent = append(recent, t)
+		}
+	}
+	w.restarts[name] = recent
+	return len(recent) < w.config.MaxRestarts
+}
+
+func (w *watchdog) restart(module Module, reason error) {
+	name := module.Name()
+	now := time.Now()
+	if !w.allowRestart(name, now) {
+		w.loader.log.Warningf("module %s restarted %d times in %s, skip restarting",
+			name, w.config.MaxRestarts, w.config.RestartWindow)
+		return
+	}
+	if runOnMainThread(module) {
+		w.loader.log.Warningf("module %s must run on main thread, skip restarting", name)
+		return
+	}
+	w.restarts[name] = append(w.restarts[name], now)
+
+	// the loader lock is not held while stopping and starting the module,
+	// which may take long, Enable of the module is serialized by itself.
+	w.loader.log.Info("restart module", name)
+	err := w.loader.stopModule(module)
+	if err == nil {
+		err = w.loader.startModule(module).err
+		if err != nil {
+			// the module is stopped now, retry it as it failed to start
+			w.loader.setModuleFailed(module, err)
+		} else {
+			w.loader.clearFailure(name)
+		}
+	}
+	if err != nil {
+		w.loader.log.Warningf("restart module %s failed: %v", name, err)
+	}
+
+	w.handlersLock.Lock()
+	handlers := w.handlers
+	w.handlersLock.Unlock()
+	for _, fn := range handlers {
+		fn(name, reason.Error(), err)
+	}
+}
@@ -0,0 +1,172 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package loader
+
+import (
+	"errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+type fakeCheckerModule struct {
+	*fakeModule
+	check func() error
+}
+
+func (m *fakeCheckerModule) CheckHealth() error {
+	return m.check()
+}
+
+func newTestWatchdog(l *Loader, config WatchdogConfig) *watchdog {
+	w := &l.watchdog
+	w.loader = l
+	w.config = config
+	w.restarts = map[string][]time.Time{}
+	return w
+}
+
+type restartRecord struct {
+	name   string
+	reason string
+	err    error
+}
+
+func TestWatchdogRestartUnhealthyModule(t *testing.T) {
+	Convey("Test the watchdog restarts unhealthy modules", t, func() {
+		healthy := &fakeCheckerModule{fakeModule: newFakeModule("healthy"), check: func() error {
+			return nil
+		}}
+		unhealthy := &fakeCheckerModule{fakeModule: newFakeModule("unhealthy"), check: func() error {
+			return errors.New("connection lost")
+		}}
+		hanging := &fakeCheckerModule{fakeModule: newFakeModule("hanging"), check: func() error {
+			time.Sleep(time.Second)
+			return nil
+		}}
+
+		l := newTestLoader(healthy, unhealthy, hanging)
+		So(l.EnableModules([]string{"healthy", "unhealthy", "hanging"}, nil, EnableFlagNone), ShouldBeNil)
+		w := newTestWatchdog(l, WatchdogConfig{
+			CheckTimeout:  10 * time.Millisecond,
+			MaxRestarts:   3,
+			RestartWindow: time.Minute,
+		})
+		var restarted []restartRecord
+		l.ConnectModuleRestarted(func(name string, reason string, err error) {
+			restarted = append(restarted, restartRecord{name, reason, err})
+		})
+
+		w.checkModules()
+		So(restarted, ShouldResemble, []restartRecord{
+			{"hanging", errCheckHealthTimeout.Error(), nil},
+			{"unhealthy", "connection lost", nil},
+		})
+		starts, stops := healthy.counts()
+		So(starts, ShouldEqual, 1)
+		So(stops, ShouldEqual, 0)
+		starts, stops = unhealthy.counts()
+		So(starts, ShouldEqual, 2)
+		So(stops, ShouldEqual, 1)
+		So(unhealthy.IsEnable(), ShouldBeTrue)
+	})
+}
+
+func TestWatchdogRestartRateLimit(t *testing.T) {
+	Convey("Test the watchdog restarts a module at most MaxRestarts times in the window", t, func() {
+		m := newFakeModule("a")
+		l := newTestLoader(m)
+		So(l.EnableModules([]string{"a"}, nil, EnableFlagNone), ShouldBeNil)
+		w := newTestWatchdog(l, WatchdogConfig{
+			MaxRestarts:   2,
+			RestartWindow: time.Minute,
+		})
+
+		reason := errors.New("unhealthy")
+		for i := 0; i < 3; i++ {
+			w.restart(m, reason)
+		}
+		starts, _ := m.counts()
+		So(starts, ShouldEqual, 3)
+
+		// the restarts out of the window are not counted
+		for i := range w.restarts["a"] {
+			w.restarts["a"][i] = w.restarts["a"][i].Add(-time.Minute)
+		}
+		w.restart(m, reason)
+		starts, _ = m.counts()
+		So(starts, ShouldEqual, 4)
+	})
+
+	Convey("Test allowRestart", t, func() {
+		w := &watchdog{
+			config:   WatchdogConfig{MaxRestarts: 2, RestartWindow: time.Minute},
+			restarts: map[string][]time.Time{},
+		}
+		now := time.Now()
+		var infos = []struct {
+			restarts []time.Time
+			allow    bool
+		}{
+			{nil, true},
+			{[]time.Time{now.Add(-time.Second)}, true},
+			{[]time.Time{now.Add(-2 * time.Second), now.Add(-time.Second)}, false},
+			{[]time.Time{now.Add(-2 * time.Minute), now.Add(-time.Second)}, true},
+		}
+		for _, info := range infos {
+			w.restarts["a"] = info.restarts
+			So(w.allowRestart("a", now), ShouldEqual, info.allow)
+		}
+	})
+}
+
+func TestWatchdogRestartStartFailed(t *testing.T) {
+	Convey("Test a module failing to start after restarting is retried", t, func() {
+		m := newFakeModule("a")
+		l := newTestLoader(m)
+		So(l.EnableModules([]string{"a"}, nil, EnableFlagNone), ShouldBeNil)
+		l.SetRetryPolicy(RetryPolicy{
+			MaxRetries:  1,
+			Interval:    50 * time.Millisecond,
+			MaxInterval: 50 * time.Millisecond,
+		})
+		w := newTestWatchdog(l, WatchdogConfig{
+			MaxRestarts:   3,
+			RestartWindow: time.Minute,
+		})
+
+		startErr := errors.New("start failed")
+		m.start = func() error {
+			starts, _ := m.counts()
+			if starts == 2 {
+				return startErr
+			}
+			return nil
+		}
+		var restartErr error
+		l.ConnectModuleRestarted(func(name string, reason string, err error) {
+			restartErr = err
+		})
+
+		w.restart(m, errors.New("unhealthy"))
+		So(restartErr, ShouldEqual, startErr)
+		So(m.IsEnable(), ShouldBeFalse)
+		failure, ok := l.GetFailure("a")
+		So(ok, ShouldBeTrue)
+		So(failure.Reason, ShouldEqual, "start failed")
+
+		// the failure is cleared once the retry started the module
+		So(waitFor(func() bool {
+			_, ok := l.GetFailure("a")
+			return !ok
+		}, 2*time.Second), ShouldBeTrue)
+		So(m.IsEnable(), ShouldBeTrue)
+	})
+}
@@ -130,6 +130,11 @@ func isSecretKey(connectionData map[string]map[string]dbus.Variant, settingName,
 		if keyName == nm.NM_SETTING_VPN_SECRETS {
 			isSecret = true
 		}
+	case nm.NM_SETTING_WIREGUARD_SETTING_NAME:
+		switch keyName {
+		case nm.NM_SETTING_WIREGUARD_PRIVATE_KEY:
+			isSecret = true
+		}
 	}
 	return
 }
@@ -167,6 +172,9 @@ func fillSecretData(connectionData, secretsData map[string]map[string]dbus.Varia
 	case nm.NM_SETTING_VPN_SETTING_NAME:
 		keyValue, _ := keyValueIfc.(map[string]string)
 		setSettingVpnSecrets(secretsData, keyValue)
+	case nm.NM_SETTING_WIREGUARD_SETTING_NAME:
+		keyValue, _ := keyValueIfc.(string)
+		setSettingWireGuardPrivateKey(secretsData, keyValue)
 	default:
 		logger.Error("Unknown secretly setting name", settingName, ", please report it to linuxdeepin")
 	}
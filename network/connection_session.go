@@ -32,6 +32,8 @@ type ConnectionSession struct {
 	data             connectionData
 	dataLocker       sync.RWMutex
 	connectionExists bool
+	// the inline files of the imported vpn profile, written when saved
+	vpnInlineFiles vpnInlineFiles
 
 	ConnectionPath dbus.ObjectPath
 	Uuid           string
@@ -114,6 +116,16 @@ func newConnectionSessionByCreate(connectionType string, devPath dbus.ObjectPath
 		s.data = newVpnVpncConnectionData(id, s.Uuid)
 	case connectionVpnOpenvpn:
 		s.data = newVpnOpenvpnConnectionData(id, s.Uuid)
+	case connectionWireguard:
+		s.data = newWireguardConnectionData(id, s.Uuid)
+	case connectionBond:
+		s.data = newBondConnectionData(id, s.Uuid)
+	case connectionBridge:
+		s.data = newBridgeConnectionData(id, s.Uuid)
+	case connectionTeam:
+		s.data = newTeamConnectionData(id, s.Uuid)
+	case connectionVlan:
+		s.data = newVlanConnectionData(id, s.Uuid)
 	}
 
 	// disable vpn autoconnect default
@@ -128,6 +140,28 @@ func newConnectionSessionByCreate(connectionType string, devPath dbus.ObjectPath
 	return
 }
 
+func newConnectionSessionByImport(file string) (s *ConnectionSession, err error) {
+	data, inlineFiles, err := importVpnConnectionData(file)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+
+	s = doNewConnectionSession("/", getSettingConnectionUuid(data))
+	s.connectionExists = false
+	s.data = data
+	s.vpnInlineFiles = inlineFiles
+
+	// disable vpn autoconnect default
+	manager.config.addVpnConfig(s.Uuid)
+	logicSetSettingVkVpnAutoconnect(s.data, false)
+
+	fillSectionCache(s.data)
+	s.setProps()
+	logger.Debugf("newConnectionSessionByImport(): %#v", s.data)
+	return
+}
+
 func newConnectionSessionByOpen(uuid string, devPath dbus.ObjectPath) (s *ConnectionSession, err error) {
 	connectionPath, err := nmGetConnectionByUuid(uuid)
 	if err != nil {
@@ -280,6 +314,8 @@ func (s *ConnectionSession) getSecretsFromNM() {
 		// s.doGetSecretsFromNM(nm.NM_SETTING_CDMA_SETTING_NAME)
 	case connectionVpnL2tp, connectionVpnOpenconnect, connectionVpnPptp, connectionVpnVpnc, connectionVpnOpenvpn:
 		// ignore vpn secrets
+	case connectionWireguard:
+		s.doGetSecretsFromNM(nm.NM_SETTING_WIREGUARD_SETTING_NAME)
 	}
 }
 func (s *ConnectionSession) doGetSecretsFromNM(secretSection string) {
@@ -356,11 +392,20 @@ func (s *ConnectionSession) Save() (ok bool, err error) {
 			setSettingConnectionId(s.data, string(getSettingWirelessSsid(s.data)))
 		}
 
+		err = writeVpnInlineFiles(s.Uuid, s.vpnInlineFiles)
+		if err != nil {
+			logger.Error(err)
+			return false, err
+		}
+		var errAdd error
 		switch connectionType {
 		case connectionWired, connectionWireless, connectionWirelessAdhoc, connectionWirelessHotspot:
-			nmAddAndActivateConnection(s.data, s.devPath)
+			_, _, errAdd = nmAddAndActivateConnection(s.data, s.devPath)
 		default:
-			nmAddConnection(s.data)
+			_, errAdd = nmAddConnection(s.data)
+		}
+		if errAdd != nil && len(s.vpnInlineFiles) > 0 {
+			removeVpnInlineFiles(s.Uuid)
 		}
 	}
 
@@ -452,6 +497,11 @@ func (s *ConnectionSession) SetKey(section, key, valueJSON string) {
 }
 
 func (s *ConnectionSession) updateErrorsWhenSettingKey(section, key string, err error) {
+	if err == nil && section == nm.NM_SETTING_WIREGUARD_SETTING_NAME {
+		// wireguard keys are validated once they are set, so that the
+		// front-end could point out the wrong key immediately
+		err = checkSettingWireGuardKeyValue(s.data, key)
+	}
 	if err == nil {
 		// delete key error if exists
 		sectionErrors, ok := s.settingKeyErrors[section]
@@ -501,6 +551,31 @@ func (s *ConnectionSession) IsDefaultExpandedSection(vsection string) (bool, err
 		case nm.NM_SETTING_VS_VPN:
 			return true, nil
 		}
+	case connectionWireguard:
+		switch vsection {
+		case nm.NM_SETTING_VS_WIREGUARD:
+			return true, nil
+		}
+	case connectionBond:
+		switch vsection {
+		case nm.NM_SETTING_VS_BOND:
+			return true, nil
+		}
+	case connectionBridge:
+		switch vsection {
+		case nm.NM_SETTING_VS_BRIDGE:
+			return true, nil
+		}
+	case connectionTeam:
+		switch vsection {
+		case nm.NM_SETTING_VS_TEAM:
+			return true, nil
+		}
+	case connectionVlan:
+		switch vsection {
+		case nm.NM_SETTING_VS_VLAN:
+			return true, nil
+		}
 	default:
 		switch vsection {
 		case nm.NM_SETTING_VS_IPV4:
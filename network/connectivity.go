@@ -0,0 +1,268 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"pkg.deepin.io/dde/daemon/network/nm"
+	"pkg.deepin.io/lib/appinfo/desktopappinfo"
+	"pkg.deepin.io/lib/dbus"
+	"sync"
+	"time"
+)
+
+// The connectivity is checked by requesting an URL which returns an
+// empty response with status code 204, if the request is redirected
+// or some content returned, we are behind a captive portal. No URL is
+// requested unless one is configured, then only the connectivity state of
+// network-manager, checked by its own configured URI, is used.
+const (
+	connectivityCheckInterval = 5 * time.Minute
+	connectivityCheckTimeout  = 10 * time.Second
+
+	// max size of the response body to read, captive portals may
+	// return a full login page
+	connectivityCheckMaxBody = 4096
+)
+
+const (
+	dbusMimeDest = "com.deepin.daemon.Mime"
+	dbusMimePath = "/com/deepin/daemon/Mime"
+	dbusMimeIfc  = dbusMimeDest
+
+	mimeTypeHttp = "x-scheme-handler/http"
+)
+
+type connectivityChecker struct {
+	// uri is the URL to check, if empty, only the connectivity state
+	// of network-manager is used
+	uri    string
+	client *http.Client
+
+	mu           sync.Mutex
+	connectivity uint32
+	portalUrl    string
+	checkNow     chan struct{}
+	quit         chan struct{}
+	done         chan struct{}
+
+	// nmConnectivityGetter returns the connectivity state of
+	// network-manager.
+	nmConnectivityGetter func() uint32
+	// onChanged is called when the connectivity state is changed,
+	// the portal URL is only valid when state is portal.
+	onChanged func(connectivity uint32, portalUrl string)
+}
+
+func newConnectivityChecker(uri string, nmConnectivityGetter func() uint32,
+	onChanged func(connectivity uint32, portalUrl string)) *connectivityChecker {
+	return &connectivityChecker{
+		uri: uri,
+		client: &http.Client{
+			Timeout: connectivityCheckTimeout,
+			// do not follow redirects, which means a captive portal
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		connectivity:         nm.NM_CONNECTIVITY_UNKNOWN,
+		checkNow:             make(chan struct{}, 1),
+		nmConnectivityGetter: nmConnectivityGetter,
+		onChanged:            onChanged,
+	}
+}
+
+func (cc *connectivityChecker) start() {
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	cc.quit = quit
+	cc.done = done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(connectivityCheckInterval)
+		defer ticker.Stop()
+		cc.update()
+		for {
+			select {
+			case <-ticker.C:
+				cc.update()
+			case <-cc.checkNow:
+				cc.update()
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
+
+// destroyConnectivityChecker stop the checker and wait for the running
+// check to finish.
+func destroyConnectivityChecker(cc *connectivityChecker) {
+	if cc.quit == nil {
+		return
+	}
+	close(cc.quit)
+	<-cc.done
+	cc.quit = nil
+	cc.done = nil
+}
+
+// requestCheck ask the checker to check the connectivity as soon as
+// possible, the requests will be merged if a check is pending.
+func (cc *connectivityChecker) requestCheck() {
+	select {
+	case cc.checkNow <- struct{}{}:
+	default:
+	}
+}
+
+func (cc *connectivityChecker) getState() (connectivity uint32, portalUrl string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.connectivity, cc.portalUrl
+}
+
+func (cc *connectivityChecker) update() {
+	connectivity := cc.nmConnectivityGetter()
+	var portalUrl string
+	// there is no need to check if network-manager has no network
+	// connection at all
+	if len(cc.uri) > 0 && connectivity != nm.NM_CONNECTIVITY_NONE {
+		connectivity, portalUrl = cc.check()
+	}
+
+	cc.mu.Lock()
+	changed := connectivity != cc.connectivity || portalUrl != cc.portalUrl
+	cc.connectivity = connectivity
+	cc.portalUrl = portalUrl
+	cc.mu.Unlock()
+
+	if changed {
+		logger.Info("connectivity changed:", connectivity, portalUrl)
+		if cc.onChanged != nil {
+			cc.onChanged(connectivity, portalUrl)
+		}
+	}
+}
+
+// check request the URL and return the connectivity state, and the
+// captive portal login URL if exists.
+func (cc *connectivityChecker) check() (connectivity uint32, portalUrl string) {
+	resp, err := cc.client.Get(cc.uri)
+	if err != nil {
+		logger.Debug("connectivity check failed:", err)
+		return nm.NM_CONNECTIVITY_LIMITED, ""
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, connectivityCheckMaxBody))
+	if err != nil {
+		logger.Debug("connectivity check failed:", err)
+		return nm.NM_CONNECTIVITY_LIMITED, ""
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		connectivity = nm.NM_CONNECTIVITY_FULL
+	case resp.StatusCode == http.StatusOK && len(body) == 0:
+		// some proxies convert 204 to 200 with empty body
+		connectivity = nm.NM_CONNECTIVITY_FULL
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		connectivity = nm.NM_CONNECTIVITY_PORTAL
+		// a relative location is resolved against the check URL,
+		// only web pages are opened as the login page
+		if loc, err := resp.Location(); err == nil && isWebUrl(loc) {
+			portalUrl = loc.String()
+		}
+	case resp.StatusCode == http.StatusOK:
+		// the request is hijacked and the login page is returned
+		// directly, so open the check URL to reach it again
+		connectivity = nm.NM_CONNECTIVITY_PORTAL
+	default:
+		connectivity = nm.NM_CONNECTIVITY_LIMITED
+	}
+	if connectivity == nm.NM_CONNECTIVITY_PORTAL && len(portalUrl) == 0 {
+		portalUrl = cc.uri
+	}
+	return
+}
+
+func isWebUrl(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0
+}
+
+// openUrlInDefaultBrowser launch the default web browser which
+// queried through the mime module to open the URL, only http and
+// https URLs are allowed.
+func openUrlInDefaultBrowser(rawUrl string) (err error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return
+	}
+	if !isWebUrl(u) {
+		err = fmt.Errorf("not a web URL: %q", rawUrl)
+		return
+	}
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return
+	}
+	var appInfoJSON string
+	err = conn.Object(dbusMimeDest, dbusMimePath).Call(dbusMimeIfc+".GetDefaultApp", 0, mimeTypeHttp).Store(&appInfoJSON)
+	if err != nil {
+		return
+	}
+	var appInfo struct {
+		Id string
+	}
+	if err = json.Unmarshal([]byte(appInfoJSON), &appInfo); err != nil {
+		return
+	}
+	ai := desktopappinfo.NewDesktopAppInfo(appInfo.Id)
+	if ai == nil {
+		err = fmt.Errorf("invalid desktop id of default browser: %q", appInfo.Id)
+		return
+	}
+	return ai.Launch(0, []string{u.String()})
+}
+
+func (m *Manager) initConnectivityChecker() {
+	m.connectivityChecker = newConnectivityChecker(m.config.ConnectivityCheckUri,
+		nmGetConnectivity, m.onConnectivityChanged)
+	nmManager.Connectivity.ConnectChanged(func() {
+		if m.connectivityChecker != nil {
+			m.connectivityChecker.requestCheck()
+		}
+	})
+	m.connectivityChecker.start()
+}
+
+func (m *Manager) onConnectivityChanged(connectivity uint32, portalUrl string) {
+	oldConnectivity := m.Connectivity
+	m.setPropConnectivity(connectivity)
+	if connectivity == nm.NM_CONNECTIVITY_PORTAL && oldConnectivity != nm.NM_CONNECTIVITY_PORTAL {
+		notifyCaptivePortal(portalUrl)
+	}
+}
+
+// CheckConnectivity check the connectivity state immediately, the
+// result will be updated to property "Connectivity".
+func (m *Manager) CheckConnectivity() (err error) {
+	if m.connectivityChecker == nil {
+		err = fmt.Errorf("connectivity checker is not initialized")
+		return
+	}
+	m.connectivityChecker.requestCheck()
+	return
+}
@@ -0,0 +1,154 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	C "launchpad.net/gocheck"
+	"net/http"
+	"net/http/httptest"
+	"pkg.deepin.io/dde/daemon/network/nm"
+	"time"
+)
+
+func newTestConnectivityChecker(uri string, nmConnectivity uint32) *connectivityChecker {
+	return newConnectivityChecker(uri, func() uint32 { return nmConnectivity }, nil)
+}
+
+func (*testWrapper) TestConnectivityCheck(c *C.C) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/generate_204", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://portal.example.com/login", http.StatusFound)
+	})
+	mux.HandleFunc("/redirect-relative", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/login")
+		w.WriteHeader(http.StatusFound)
+	})
+	mux.HandleFunc("/redirect-file", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "file:///etc/passwd")
+		w.WriteHeader(http.StatusFound)
+	})
+	mux.HandleFunc("/login-page", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>login</html>"))
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var tests = []struct {
+		path         string
+		connectivity uint32
+		portalUrl    string
+	}{
+		{"/generate_204", nm.NM_CONNECTIVITY_FULL, ""},
+		{"/empty", nm.NM_CONNECTIVITY_FULL, ""},
+		{"/redirect", nm.NM_CONNECTIVITY_PORTAL, "http://portal.example.com/login"},
+		{"/redirect-relative", nm.NM_CONNECTIVITY_PORTAL, server.URL + "/login"},
+		{"/redirect-file", nm.NM_CONNECTIVITY_PORTAL, server.URL + "/redirect-file"},
+		{"/login-page", nm.NM_CONNECTIVITY_PORTAL, server.URL + "/login-page"},
+		{"/error", nm.NM_CONNECTIVITY_LIMITED, ""},
+	}
+	for _, t := range tests {
+		cc := newTestConnectivityChecker(server.URL+t.path, nm.NM_CONNECTIVITY_FULL)
+		connectivity, portalUrl := cc.check()
+		c.Check(connectivity, C.Equals, t.connectivity, C.Commentf("path: %s", t.path))
+		c.Check(portalUrl, C.Equals, t.portalUrl, C.Commentf("path: %s", t.path))
+	}
+}
+
+func (*testWrapper) TestConnectivityCheckUnreachable(c *C.C) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	uri := server.URL
+	server.Close()
+
+	cc := newTestConnectivityChecker(uri, nm.NM_CONNECTIVITY_FULL)
+	connectivity, portalUrl := cc.check()
+	c.Check(connectivity, C.Equals, uint32(nm.NM_CONNECTIVITY_LIMITED))
+	c.Check(portalUrl, C.Equals, "")
+}
+
+func (*testWrapper) TestConnectivityUpdate(c *C.C) {
+	requested := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	nmConnectivity := uint32(nm.NM_CONNECTIVITY_NONE)
+	changed := 0
+	cc := newConnectivityChecker(server.URL, func() uint32 { return nmConnectivity },
+		func(connectivity uint32, portalUrl string) { changed++ })
+
+	// no request if network-manager has no network connection
+	cc.update()
+	connectivity, _ := cc.getState()
+	c.Check(connectivity, C.Equals, uint32(nm.NM_CONNECTIVITY_NONE))
+	c.Check(requested, C.Equals, 0)
+	c.Check(changed, C.Equals, 1)
+
+	nmConnectivity = nm.NM_CONNECTIVITY_LIMITED
+	cc.update()
+	connectivity, _ = cc.getState()
+	c.Check(connectivity, C.Equals, uint32(nm.NM_CONNECTIVITY_FULL))
+	c.Check(requested, C.Equals, 1)
+	c.Check(changed, C.Equals, 2)
+
+	// state not changed
+	cc.update()
+	c.Check(requested, C.Equals, 2)
+	c.Check(changed, C.Equals, 2)
+
+	// only the state of network-manager is used if the uri is empty
+	cc.uri = ""
+	cc.update()
+	connectivity, _ = cc.getState()
+	c.Check(connectivity, C.Equals, uint32(nm.NM_CONNECTIVITY_LIMITED))
+	c.Check(requested, C.Equals, 2)
+	c.Check(changed, C.Equals, 3)
+}
+
+func (*testWrapper) TestConnectivityCheckerDestroy(c *C.C) {
+	checking := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(checking)
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	changed := make(chan uint32, 1)
+	cc := newConnectivityChecker(server.URL, func() uint32 { return nm.NM_CONNECTIVITY_FULL },
+		func(connectivity uint32, portalUrl string) { changed <- connectivity })
+	cc.start()
+	<-checking
+
+	destroyed := make(chan struct{})
+	go func() {
+		destroyConnectivityChecker(cc)
+		close(destroyed)
+	}()
+	select {
+	case <-destroyed:
+		c.Fatal("destroyed while checking")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	<-destroyed
+	c.Check(<-changed, C.Equals, uint32(nm.NM_CONNECTIVITY_FULL))
+}
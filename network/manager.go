@@ -10,6 +10,7 @@
 package network
 
 import (
+	"pkg.deepin.io/dde/daemon/network/nm"
 	"pkg.deepin.io/lib/dbus"
 	"sync"
 	"time"
@@ -30,6 +31,10 @@ type Manager struct {
 	// update by manager.go
 	State uint32 // global networking state
 
+	// update by connectivity.go, the values are the same with
+	// network-manager, such as nm.NM_CONNECTIVITY_PORTAL
+	Connectivity uint32
+
 	NetworkingEnabled bool `access:"readwrite"` // airplane mode for NetworkManager
 	VpnEnabled        bool `access:"readwrite"`
 
@@ -59,6 +64,21 @@ type Manager struct {
 	activeConnections     map[dbus.ObjectPath]*activeConnection
 	ActiveConnections     string // array of connections that activated and marshaled by json
 
+	// update by proxy_profile.go, the id of proxy profile in use
+	proxyProfilesLock  sync.Mutex
+	ActiveProxyProfile string
+
+	// update by vpn_rule.go
+	vpnRulesLock        sync.Mutex
+	vpnRuleMatchedId    string
+	VpnRules            string // array of vpn rules with matched state and marshaled by json
+	LastVpnRuleDecision string // the last action taken by vpn rules and marshaled by json
+	// the vpn rules are evaluated by a single worker, see
+	// requestUpdateVpnRules()
+	vpnRulesUpdate     chan struct{}
+	vpnRulesWorkerQuit chan struct{}
+	vpnRulesWorkerDone chan struct{}
+
 	// signals
 
 	// NeedSecrets send signal to front-end to pop-up password input
@@ -74,6 +94,9 @@ type Manager struct {
 	stateHandler  *stateHandler
 	dbusWatcher   *dbusWatcher
 	switchHandler *switchHandler
+	trafficStats  *trafficStats
+
+	connectivityChecker *connectivityChecker
 }
 
 func (m *Manager) GetDBusInfo() dbus.DBusInfo {
@@ -101,7 +124,9 @@ func initSlices() {
 }
 
 func NewManager() (m *Manager) {
-	m = &Manager{}
+	m = &Manager{
+		vpnRulesUpdate: make(chan struct{}, 1),
+	}
 	return
 }
 func DestroyManager(m *Manager) {
@@ -126,10 +151,15 @@ func (m *Manager) initManager() {
 	m.initDeviceManage()
 	m.initConnectionManage()
 	m.initActiveConnectionManage()
+	m.initTrafficStats()
+	m.initConnectivityChecker()
 
 	// update property "State"
 	nmManager.State.ConnectChanged(func() {
 		m.setPropState()
+		if m.connectivityChecker != nil {
+			m.connectivityChecker.requestCheck()
+		}
 	})
 	m.setPropState()
 
@@ -154,6 +184,15 @@ func (m *Manager) destroyManager() {
 	destroyAgent(m.agent)
 	destroyStateHandler(m.stateHandler)
 	destroyDbusWatcher(m.dbusWatcher)
+	if m.trafficStats != nil {
+		destroyTrafficStats(m.trafficStats)
+		m.trafficStats = nil
+	}
+	if m.connectivityChecker != nil {
+		destroyConnectivityChecker(m.connectivityChecker)
+		m.connectivityChecker = nil
+	}
+	m.stopVpnRulesWorker()
 	m.clearDevices()
 	m.clearAccessPoints()
 	m.clearConnections()
@@ -163,6 +202,11 @@ func (m *Manager) destroyManager() {
 	// reset dbus properties
 	m.setPropNetworkingEnabled(false)
 	m.setPropState()
+	m.setPropConnectivity(nm.NM_CONNECTIVITY_UNKNOWN)
+	m.setPropActiveProxyProfile("")
+	m.vpnRulesLock.Lock()
+	m.vpnRuleMatchedId = ""
+	m.vpnRulesLock.Unlock()
 }
 
 func watchNetworkManagerRestart(m *Manager) {
@@ -13,6 +13,7 @@ import (
 	"pkg.deepin.io/dde/daemon/network/nm"
 	"pkg.deepin.io/lib/dbus"
 	. "pkg.deepin.io/lib/gettext"
+	"time"
 )
 
 type activeConnection struct {
@@ -94,6 +95,15 @@ func (m *Manager) initActiveConnectionManage() {
 			m.doHandleVpnNotification(s.Path, state, reason)
 		}
 	})
+
+	// switch proxy profile when network location changed
+	nmManager.PrimaryConnection.ConnectChanged(func() {
+		m.updateProxyProfile()
+	})
+	m.updateProxyProfile()
+
+	m.startVpnRulesWorker()
+	m.requestUpdateVpnRules()
 }
 
 func (m *Manager) initActiveConnections() {
@@ -182,6 +192,10 @@ func (m *Manager) doUpdateActiveConnection(apath dbus.ObjectPath, props map[stri
 	// is current sequence, it also means that the active
 	// connection already exits
 	if stateChanged {
+		// account the traffic to the connections before they change
+		if m.trafficStats != nil {
+			m.trafficStats.flush(time.Now())
+		}
 		if isConnectionStateInDeactivating(aconn.State) {
 			logger.Infof("remove active connection %#v", aconn)
 			// vpn's active connection will be removed after giving a
@@ -196,6 +210,8 @@ func (m *Manager) doUpdateActiveConnection(apath dbus.ObjectPath, props map[stri
 			logger.Infof("add active connection %#v", aconn)
 			m.activeConnections[apath] = aconn
 		}
+		// evaluate vpn rules out of the lock
+		m.requestUpdateVpnRules()
 	}
 	m.setPropActiveConnections()
 }
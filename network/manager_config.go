@@ -14,11 +14,15 @@ import (
 	"pkg.deepin.io/dde/daemon/network/nm"
 	"pkg.deepin.io/lib/dbus"
 	"pkg.deepin.io/lib/utils"
+	"sync"
 )
 
 // config structure wrapper
 type config struct {
 	core utils.Config
+	// mu is held for every change of the config and while saving it,
+	// the config is changed from several goroutines
+	mu sync.RWMutex
 
 	WiredEnabled        bool
 	VpnEnabled          bool
@@ -27,6 +31,20 @@ type config struct {
 	LastWiredEnabled    bool
 	LastVpnEnabled      bool
 
+	// the URL to check connectivity and detect captive portal, if
+	// empty, only the connectivity state of network-manager is used
+	ConnectivityCheckUri string
+
+	// proxy profiles bound to network locations, and the one to use
+	// when no profile matches
+	ProxyProfiles       []*proxyProfile
+	DefaultProxyProfile string
+
+	// vpn rules evaluated when active connections changed, the
+	// wireless networks not in TrustedSsids are untrusted
+	VpnRules     []*vpnRule
+	TrustedSsids []string
+
 	Devices           map[string]*deviceConfig // config for each device
 	VpnConnections    map[string]*vpnConfig    // config for each vpn connection
 	MobileConnections map[string]*mobileConfig // config for each mobile connection
@@ -66,11 +84,16 @@ func newConfig() (c *config) {
 	c.LastWwanEnabled = true
 	c.LastWiredEnabled = c.WiredEnabled
 	c.LastVpnEnabled = c.VpnEnabled
+	c.ProxyProfiles = make([]*proxyProfile, 0)
+	c.VpnRules = make([]*vpnRule, 0)
+	c.TrustedSsids = make([]string, 0)
 	c.load()
 	c.clearSpareConfig()
 	return
 }
-func (c *config) save() {
+
+// saveLocked save the config with mu held.
+func (c *config) saveLocked() {
 	c.core.Save(c)
 }
 func (c *config) load() {
@@ -100,106 +123,142 @@ func newMobileConfig() (m *mobileConfig) {
 func (c *config) clearSpareConfig() {
 	// remove spare device and vpn config
 	devIds := nmGetDeviceIdentifiers()
+	vpnUuids := nmGetConnectionUuidsByType(nm.NM_SETTING_VPN_SETTING_NAME)
+	mobileUuids := nmGetConnectionUuidsByType(nm.NM_SETTING_GSM_SETTING_NAME, nm.NM_SETTING_CDMA_SETTING_NAME)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for id, _ := range c.Devices {
 		if !isStringInArray(id, devIds) {
-			c.removeDeviceConfig(id)
+			delete(c.Devices, id)
 		}
 	}
-	vpnUuids := nmGetConnectionUuidsByType(nm.NM_SETTING_VPN_SETTING_NAME)
 	for uuid, _ := range c.VpnConnections {
 		if !isStringInArray(uuid, vpnUuids) {
-			c.removeVpnConfig(uuid)
+			delete(c.VpnConnections, uuid)
 		}
 	}
-	mobileUuids := nmGetConnectionUuidsByType(nm.NM_SETTING_GSM_SETTING_NAME, nm.NM_SETTING_CDMA_SETTING_NAME)
 	for uuid, _ := range c.MobileConnections {
 		if !isStringInArray(uuid, mobileUuids) {
-			c.removeMobileConfig(uuid)
+			delete(c.MobileConnections, uuid)
 		}
 	}
+	c.saveLocked()
 }
 
 func (c *config) getLastGlobalSwithes() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.LastWirelessEnabled
 }
 func (c *config) getLastWirelessEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.LastWirelessEnabled
 }
 func (c *config) getLastWwanEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.LastWwanEnabled
 }
 func (c *config) getLastWiredEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.LastWiredEnabled
 }
 func (c *config) getLastVpnEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.LastVpnEnabled
 }
 
 func (c *config) setLastGlobalSwithes(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.LastWirelessEnabled = enabled
 	c.LastWwanEnabled = enabled
 	c.LastWiredEnabled = enabled
 	c.LastVpnEnabled = enabled
-	c.save()
+	c.saveLocked()
 }
 func (c *config) setLastWirelessEnabled(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.LastWirelessEnabled != enabled {
 		c.LastWirelessEnabled = enabled
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) setLastWwanEnabled(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.LastWwanEnabled != enabled {
 		c.LastWwanEnabled = enabled
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) setLastWiredEnabled(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.LastWiredEnabled != enabled {
 		c.LastWiredEnabled = enabled
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) setLastVpnEnabled(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.LastVpnEnabled != enabled {
 		c.LastVpnEnabled = enabled
-		c.save()
+		c.saveLocked()
 	}
 }
 
 func (c *config) getWiredEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.WiredEnabled
 }
 func (c *config) getVpnEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.VpnEnabled
 }
 
 func (c *config) setWiredEnabled(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.WiredEnabled != enabled {
 		c.WiredEnabled = enabled
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) setVpnEnabled(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.VpnEnabled != enabled {
 		c.VpnEnabled = enabled
-		c.save()
+		c.saveLocked()
 	}
 }
 
 // remove all configurations that related to target connection
 func (c *config) removeConnection(uuid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, devConfig := range c.Devices {
 		if devConfig.LastConnectionUuid == uuid {
 			devConfig.LastConnectionUuid = ""
 		}
 	}
-	c.removeVpnConfig(uuid)
-	c.save()
+	delete(c.VpnConnections, uuid)
+	c.saveLocked()
 }
 
 // deviceConfig related functions
 func (c *config) isDeviceConfigExists(devId string) (ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	_, ok = c.Devices[devId]
 	return
 }
@@ -211,12 +270,13 @@ func (c *config) getDeviceConfigForPath(devPath dbus.ObjectPath) (d *deviceConfi
 	return c.getDeviceConfig(devId)
 }
 func (c *config) getDeviceConfig(devId string) (d *deviceConfig, err error) {
-	if !c.isDeviceConfigExists(devId) {
+	c.mu.RLock()
+	d, ok := c.Devices[devId]
+	c.mu.RUnlock()
+	if !ok {
 		err = fmt.Errorf("device config for %s not exists", devId)
 		logger.Warning(err)
-		return
 	}
-	d, _ = c.Devices[devId]
 	return
 }
 func (c *config) addDeviceConfig(devPath dbus.ObjectPath) {
@@ -227,16 +287,20 @@ func (c *config) addDeviceConfig(devPath dbus.ObjectPath) {
 	if !c.isDeviceConfigExists(devId) {
 		devConfig := newDeviceConfig()
 		devConfig.LastConnectionUuid, _ = nmGetDeviceActiveConnectionUuid(devPath)
+		c.mu.Lock()
 		c.Devices[devId] = devConfig
-		c.save()
+		c.saveLocked()
+		c.mu.Unlock()
 	}
 }
 func (c *config) removeDeviceConfig(devId string) {
 	if !c.isDeviceConfigExists(devId) {
 		logger.Errorf("device config for %s not exists", devId)
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	delete(c.Devices, devId)
-	c.save()
+	c.saveLocked()
 }
 func (c *config) updateDeviceConfig(devPath dbus.ObjectPath) {
 	devConfig, err := c.getDeviceConfigForPath(devPath)
@@ -244,11 +308,15 @@ func (c *config) updateDeviceConfig(devPath dbus.ObjectPath) {
 		return
 	}
 	devState := nmGetDeviceState(devPath)
+	if !isDeviceStateInActivating(devState) {
+		return
+	}
+	uuid, _ := nmGetDeviceActiveConnectionUuid(devPath)
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if devConfig.Enabled {
-		if isDeviceStateInActivating(devState) {
-			devConfig.LastConnectionUuid, _ = nmGetDeviceActiveConnectionUuid(devPath)
-			c.save()
-		}
+		devConfig.LastConnectionUuid = uuid
+		c.saveLocked()
 	}
 }
 func (c *config) syncDeviceState(devPath dbus.ObjectPath) {
@@ -259,7 +327,10 @@ func (c *config) syncDeviceState(devPath dbus.ObjectPath) {
 	devState := nmGetDeviceState(devPath)
 	if isDeviceStateInActivating(devState) {
 		// sync device state
-		if !devConfig.Enabled {
+		c.mu.RLock()
+		enabled := devConfig.Enabled
+		c.mu.RUnlock()
+		if !enabled {
 			manager.doDisconnectDevice(devPath)
 		}
 	}
@@ -270,6 +341,8 @@ func (c *config) getDeviceEnabled(devPath dbus.ObjectPath) (enabled bool) {
 		enabled = true // return true as default
 		return
 	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	enabled = devConfig.Enabled
 	return
 }
@@ -278,18 +351,22 @@ func (c *config) setDeviceEnabled(devPath dbus.ObjectPath, enabled bool) {
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
 	devConfig.Enabled = enabled
+	c.saveLocked()
+	c.mu.Unlock()
 	dbus.Emit(manager, "DeviceEnabled", string(devPath), enabled)
-	c.save()
 }
 func (c *config) setDeviceLastConnectionUuid(devPath dbus.ObjectPath, uuid string) {
 	devConfig, err := c.getDeviceConfigForPath(devPath)
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if devConfig.LastConnectionUuid != uuid {
 		devConfig.LastConnectionUuid = uuid
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) setDeviceLastEnabled(devPath dbus.ObjectPath, enabled bool) {
@@ -297,16 +374,20 @@ func (c *config) setDeviceLastEnabled(devPath dbus.ObjectPath, enabled bool) {
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if devConfig.LastEnabled != enabled {
 		devConfig.LastEnabled = enabled
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) setAllDeviceLastEnabled(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, devConfig := range c.Devices {
 		devConfig.LastEnabled = enabled
 	}
-	c.save()
+	c.saveLocked()
 }
 
 func (c *config) saveDeviceState(devPath dbus.ObjectPath) {
@@ -314,9 +395,11 @@ func (c *config) saveDeviceState(devPath dbus.ObjectPath) {
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if devConfig.LastEnabled != devConfig.Enabled {
 		devConfig.LastEnabled = devConfig.Enabled
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) restoreDeviceState(devPath dbus.ObjectPath) {
@@ -324,37 +407,46 @@ func (c *config) restoreDeviceState(devPath dbus.ObjectPath) {
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if devConfig.Enabled != devConfig.LastEnabled {
 		devConfig.Enabled = devConfig.LastEnabled
-		c.save()
+		c.saveLocked()
 	}
 }
 
 // vpnConfig
 func (c *config) isVpnConfigExists(uuid string) (ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	_, ok = c.VpnConnections[uuid]
 	return
 }
 func (c *config) getVpnConfig(uuid string) (v *vpnConfig, err error) {
-	if !c.isVpnConfigExists(uuid) {
+	c.mu.RLock()
+	v, ok := c.VpnConnections[uuid]
+	c.mu.RUnlock()
+	if !ok {
 		err = fmt.Errorf("vpn config for %s not exists", uuid)
 		logger.Warning(err)
-		return
 	}
-	v, _ = c.VpnConnections[uuid]
 	return
 }
 func (c *config) addVpnConfig(uuid string) {
-	if !c.isVpnConfigExists(uuid) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.VpnConnections[uuid]; !ok {
 		vpnConfig := newVpnConfig()
 		c.VpnConnections[uuid] = vpnConfig
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) removeVpnConfig(uuid string) {
-	if c.isVpnConfigExists(uuid) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.VpnConnections[uuid]; ok {
 		delete(c.VpnConnections, uuid)
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) setVpnConnectionActivated(uuid string, activated bool) {
@@ -362,9 +454,11 @@ func (c *config) setVpnConnectionActivated(uuid string, activated bool) {
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if vpnConfig.activated != activated {
 		vpnConfig.activated = activated
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) isVpnConnectionAutoConnect(uuid string) bool {
@@ -372,6 +466,8 @@ func (c *config) isVpnConnectionAutoConnect(uuid string) bool {
 	if err != nil {
 		return false
 	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return vpnConfig.AutoConnect
 }
 func (c *config) setVpnConnectionAutoConnect(uuid string, autoConnect bool) {
@@ -379,9 +475,11 @@ func (c *config) setVpnConnectionAutoConnect(uuid string, autoConnect bool) {
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if vpnConfig.AutoConnect != autoConnect {
 		vpnConfig.AutoConnect = autoConnect
-		c.save()
+		c.saveLocked()
 	}
 }
 
@@ -392,29 +490,36 @@ func (c *config) ensureMobileConfigExists(uuid string) {
 	}
 }
 func (c *config) isMobileConfigExists(uuid string) (ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	_, ok = c.MobileConnections[uuid]
 	return
 }
 func (c *config) getMobileConfig(uuid string) (m *mobileConfig, err error) {
-	if !c.isMobileConfigExists(uuid) {
+	c.mu.RLock()
+	m, ok := c.MobileConnections[uuid]
+	c.mu.RUnlock()
+	if !ok {
 		err = fmt.Errorf("mobile config for %s not exists", uuid)
 		logger.Warning(err)
-		return
 	}
-	m, _ = c.MobileConnections[uuid]
 	return
 }
 func (c *config) addMobileConfig(uuid string) {
-	if !c.isMobileConfigExists(uuid) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.MobileConnections[uuid]; !ok {
 		mobileConfig := newMobileConfig()
 		c.MobileConnections[uuid] = mobileConfig
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) removeMobileConfig(uuid string) {
-	if c.isMobileConfigExists(uuid) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.MobileConnections[uuid]; ok {
 		delete(c.MobileConnections, uuid)
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) setMobileConnectionCountry(uuid, code string) {
@@ -422,9 +527,11 @@ func (c *config) setMobileConnectionCountry(uuid, code string) {
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if mobileConfig.Country != code {
 		mobileConfig.Country = code
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) getMobileConnectionCountry(uuid string) (code string) {
@@ -432,6 +539,8 @@ func (c *config) getMobileConnectionCountry(uuid string) (code string) {
 	if err != nil {
 		return
 	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return mobileConfig.Country
 }
 func (c *config) setMobileConnectionProvider(uuid, name string) {
@@ -439,9 +548,11 @@ func (c *config) setMobileConnectionProvider(uuid, name string) {
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if mobileConfig.Provider != name {
 		mobileConfig.Provider = name
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) getMobileConnectionProvider(uuid string) (name string) {
@@ -449,6 +560,8 @@ func (c *config) getMobileConnectionProvider(uuid string) (name string) {
 	if err != nil {
 		return
 	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return mobileConfig.Provider
 }
 func (c *config) setMobileConnectionPlan(uuid, value string) {
@@ -456,9 +569,11 @@ func (c *config) setMobileConnectionPlan(uuid, value string) {
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if mobileConfig.Plan != value {
 		mobileConfig.Plan = value
-		c.save()
+		c.saveLocked()
 	}
 }
 func (c *config) getMobileConnectionPlan(uuid string) (value string) {
@@ -466,5 +581,7 @@ func (c *config) getMobileConnectionPlan(uuid string) (value string) {
 	if err != nil {
 		return
 	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return mobileConfig.Plan
 }
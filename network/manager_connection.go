@@ -12,6 +12,7 @@ package network
 import (
 	nmdbus "dbus/org/freedesktop/networkmanager"
 	"fmt"
+	"io/ioutil"
 	"pkg.deepin.io/dde/daemon/network/nm"
 	"pkg.deepin.io/lib/dbus"
 	. "pkg.deepin.io/lib/gettext"
@@ -161,6 +162,8 @@ func (m *Manager) removeConnection(cpath dbus.ObjectPath) {
 	m.connectionsLock.Lock()
 	defer m.connectionsLock.Unlock()
 	secretDeleteAll(conn.Uuid)
+	// the inline files written when the vpn profile is imported
+	removeVpnInlineFiles(conn.Uuid)
 	m.connections[connType] = m.doRemoveConnection(m.connections[connType], i)
 	m.setPropConnections()
 }
@@ -329,6 +332,41 @@ func (m *Manager) EditConnection(uuid string, devPath dbus.ObjectPath) (session
 	return
 }
 
+// ImportConnection parse the VPN profile, such as .ovpn, Cisco .pcf
+// and strongswan ipsec.conf, and return a pre-filled
+// ConnectionSession's dbus object path if success.
+func (m *Manager) ImportConnection(file string) (session *ConnectionSession, err error) {
+	logger.Debug("ImportConnection", file)
+	session, err = newConnectionSessionByImport(toLocalPath(file))
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	m.addConnectionSession(session)
+	return
+}
+
+// ExportConnection write the VPN connection to target file as a
+// profile, which could be imported by ImportConnection() later.
+// Secrets will not be exported.
+func (m *Manager) ExportConnection(uuid, file string) (err error) {
+	logger.Debug("ExportConnection", uuid, file)
+	cpath, err := nmGetConnectionByUuid(uuid)
+	if err != nil {
+		return
+	}
+	data, err := nmGetConnectionData(cpath)
+	if err != nil {
+		return
+	}
+	content, err := exportVpnConnectionData(data)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	return ioutil.WriteFile(toLocalPath(file), content, 0600)
+}
+
 func (m *Manager) addConnectionSession(session *ConnectionSession) {
 	m.connectionSessionsLock.Lock()
 	defer m.connectionSessionsLock.Unlock()
@@ -83,14 +83,15 @@ func (m *Manager) newDevice(devPath dbus.ObjectPath) (dev *device, err error) {
 		return
 	}
 
-	// ignore virtual network interfaces
-	if isVirtualDeviceIfc(nmDev) {
+	// ignore virtual network interfaces, except the bond, bridge,
+	// team and vlan devices which are configured by user
+	devType := nmDev.DeviceType.Get()
+	if !isSoftwareDeviceType(devType) && isVirtualDeviceIfc(nmDev) {
 		err = fmt.Errorf("ignore virtual network interface which driver is %s %s", nmDev.Driver.Get(), devPath)
 		logger.Info(err)
 		return
 	}
 
-	devType := nmDev.DeviceType.Get()
 	if !isDeviceTypeValid(devType) {
 		err = fmt.Errorf("ignore invalid device type %d", devType)
 		logger.Info(err)
@@ -151,6 +152,8 @@ func (m *Manager) newDevice(devPath dbus.ObjectPath) (dev *device, err error) {
 				m.addAccessPoint(dev.Path, apPath)
 			}
 		}
+	case nm.NM_DEVICE_TYPE_BOND, nm.NM_DEVICE_TYPE_BRIDGE, nm.NM_DEVICE_TYPE_TEAM, nm.NM_DEVICE_TYPE_VLAN:
+		dev.HwAddress, _ = nmGeneralGetDeviceHwAddr(dev.Path)
 	case nm.NM_DEVICE_TYPE_MODEM:
 		if len(dev.id) == 0 {
 			// some times, modem device will not be identified
@@ -88,6 +88,30 @@ func (m *Manager) setPropState() {
 	dbus.NotifyChange(m, "State")
 }
 
+func (m *Manager) setPropConnectivity(value uint32) {
+	m.Connectivity = value
+	dbus.NotifyChange(m, "Connectivity")
+}
+
+func (m *Manager) setPropActiveProxyProfile(value string) {
+	m.ActiveProxyProfile = value
+	dbus.NotifyChange(m, "ActiveProxyProfile")
+}
+
+func (m *Manager) setPropVpnRules() {
+	infos := make([]vpnRuleInfo, 0, len(m.config.VpnRules))
+	for _, r := range m.config.VpnRules {
+		infos = append(infos, vpnRuleInfo{vpnRule: *r, Matched: r.matched})
+	}
+	m.VpnRules, _ = marshalJSON(infos)
+	dbus.NotifyChange(m, "VpnRules")
+}
+
+func (m *Manager) setPropLastVpnRuleDecision(decision *vpnRuleDecision) {
+	m.LastVpnRuleDecision, _ = marshalJSON(decision)
+	dbus.NotifyChange(m, "LastVpnRuleDecision")
+}
+
 func (m *Manager) setPropDevices() {
 	filteredDevices := make(map[string][]*device)
 	for key, devices := range m.devices {
@@ -369,7 +369,10 @@ func (sh *switchHandler) restoreVpnConnectionState(uuid string) (err error) {
 	if err != nil {
 		return
 	}
-	if vpnConfig.lastActivated || vpnConfig.AutoConnect {
+	sh.config.mu.RLock()
+	activate := vpnConfig.lastActivated || vpnConfig.AutoConnect
+	sh.config.mu.RUnlock()
+	if activate {
 		sh.activateVpnConnection(uuid)
 	} else {
 		err = manager.DeactivateConnection(uuid)
@@ -390,9 +393,11 @@ func (sh *switchHandler) deactivateVpnConnection(uuid string) (err error) {
 	if err != nil {
 		return
 	}
+	sh.config.mu.Lock()
 	vpnConfig.lastActivated = vpnConfig.activated
+	sh.config.saveLocked()
+	sh.config.mu.Unlock()
 	err = manager.DeactivateConnection(uuid)
-	sh.config.save()
 	return
 }
 
@@ -28,6 +28,11 @@ const (
 	NM_SETTING_VS_VPN_STRONGSWAN       = "vs-vpn-strongswan"
 	NM_SETTING_VS_VPN_VPNC             = "vs-vpn-vpnc"
 	NM_SETTING_VS_VPN_VPNC_ADVANCED    = "vs-vpn-vpnc-advanced"
+	NM_SETTING_VS_WIREGUARD            = "vs-wireguard"
+	NM_SETTING_VS_BOND                 = "vs-bond"
+	NM_SETTING_VS_BRIDGE               = "vs-bridge"
+	NM_SETTING_VS_TEAM                 = "vs-team"
+	NM_SETTING_VS_VLAN                 = "vs-vlan"
 	NM_SETTING_VS_IPV4                 = "vs-ipv4"
 	NM_SETTING_VS_IPV6                 = "vs-ipv6"
 )
@@ -36,6 +41,7 @@ const (
 const (
 	NM_SETTING_VK_CONNECTION_AUTOCONNECT                      = "vk-autoconnect"
 	NM_SETTING_VK_CONNECTION_NO_PERMISSION                    = "vk-no-permission"
+	NM_SETTING_VK_CONNECTION_MASTER                           = "vk-master"
 	NM_SETTING_VK_WIRED_ENABLE_MTU                            = "vk-enable-mtu"
 	NM_SETTING_VK_MOBILE_COUNTRY                              = "vk-mobile-country"
 	NM_SETTING_VK_MOBILE_PROVIDER                             = "vk-mobile-provider"
@@ -68,6 +74,13 @@ const (
 	NM_SETTING_VK_VPN_PPTP_ENABLE_LCP_ECHO                    = "vk-enable-lcp-echo"
 	NM_SETTING_VK_VPN_VPNC_KEY_ENCRYPTION_METHOD              = "vk-encryption-method"
 	NM_SETTING_VK_VPN_VPNC_KEY_DISABLE_DPD                    = "vk-disable-dpd"
+	NM_SETTING_VK_WIREGUARD_PEER_PUBLIC_KEY                   = "vk-peer-public-key"
+	NM_SETTING_VK_WIREGUARD_PEER_ENDPOINT                     = "vk-peer-endpoint"
+	NM_SETTING_VK_WIREGUARD_PEER_ALLOWED_IPS                  = "vk-peer-allowed-ips"
+	NM_SETTING_VK_WIREGUARD_PEER_PERSISTENT_KEEPALIVE         = "vk-peer-persistent-keepalive"
+	NM_SETTING_VK_BOND_MODE                                   = "vk-bond-mode"
+	NM_SETTING_VK_BOND_MIIMON                                 = "vk-bond-miimon"
+	NM_SETTING_VK_TEAM_RUNNER                                 = "vk-team-runner"
 	NM_SETTING_VK_IP4_CONFIG_ADDRESSES_ADDRESS                = "vk-addresses-address"
 	NM_SETTING_VK_IP4_CONFIG_ADDRESSES_MASK                   = "vk-addresses-mask"
 	NM_SETTING_VK_IP4_CONFIG_ADDRESSES_GATEWAY                = "vk-addresses-gateway"
@@ -1084,6 +1097,12 @@ const (
 	NM_WIMAX_NSP_NAME                               = "name"
 	NM_WIMAX_NSP_NETWORK_TYPE                       = "network-type"
 	NM_WIMAX_NSP_SIGNAL_QUALITY                     = "signal-quality"
+	NM_WIREGUARD_PEER_ATTR_ALLOWED_IPS              = "allowed-ips"
+	NM_WIREGUARD_PEER_ATTR_ENDPOINT                 = "endpoint"
+	NM_WIREGUARD_PEER_ATTR_PERSISTENT_KEEPALIVE     = "persistent-keepalive"
+	NM_WIREGUARD_PEER_ATTR_PRESHARED_KEY            = "preshared-key"
+	NM_WIREGUARD_PEER_ATTR_PRESHARED_KEY_FLAGS      = "preshared-key-flags"
+	NM_WIREGUARD_PEER_ATTR_PUBLIC_KEY               = "public-key"
 )
 
 // Setting Setting8021x
@@ -1513,6 +1532,18 @@ const (
 	NM_SETTING_WIRELESS_SECURITY_WEP_TX_KEYIDX       = "wep-tx-keyidx"
 )
 
+// Setting SettingWireGuard
+const NM_SETTING_WIREGUARD_SETTING_NAME = "wireguard"
+const (
+	NM_SETTING_WIREGUARD_FWMARK            = "fwmark"
+	NM_SETTING_WIREGUARD_LISTEN_PORT       = "listen-port"
+	NM_SETTING_WIREGUARD_MTU               = "mtu"
+	NM_SETTING_WIREGUARD_PEER_ROUTES       = "peer-routes"
+	NM_SETTING_WIREGUARD_PEERS             = "peers"
+	NM_SETTING_WIREGUARD_PRIVATE_KEY       = "private-key"
+	NM_SETTING_WIREGUARD_PRIVATE_KEY_FLAGS = "private-key-flags"
+)
+
 // Setting SettingVpnL2tp
 const NM_SETTING_ALIAS_VPN_L2TP_SETTING_NAME = "alias-vpn-l2tp"
 const (
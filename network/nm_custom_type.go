@@ -10,6 +10,7 @@
 package network
 
 import (
+	"net"
 	"pkg.deepin.io/dde/daemon/network/nm"
 	. "pkg.deepin.io/lib/gettext"
 	"pkg.deepin.io/lib/utils"
@@ -92,6 +93,11 @@ const (
 	connectionVpnStrongswan   = "vpn-strongswan"
 	connectionVpnPptp         = "vpn-pptp"
 	connectionVpnVpnc         = "vpn-vpnc"
+	connectionWireguard       = "wireguard"
+	connectionBond            = "bond"
+	connectionBridge          = "bridge"
+	connectionTeam            = "team"
+	connectionVlan            = "vlan"
 )
 
 // wrapper for custom connection types
@@ -121,6 +127,11 @@ var supportedConnectionTypes = []string{
 	connectionVpnPptp,
 	connectionVpnStrongswan,
 	connectionVpnVpnc,
+	connectionWireguard,
+	connectionBond,
+	connectionBridge,
+	connectionTeam,
+	connectionVlan,
 }
 
 func getCustomConnectionTypeForUuid(uuid string) (connType string) {
@@ -176,6 +187,16 @@ func getCustomConnectionType(data connectionData) (connType string) {
 		case nm.NM_DBUS_SERVICE_VPNC:
 			connType = connectionVpnVpnc
 		}
+	case nm.NM_SETTING_WIREGUARD_SETTING_NAME:
+		connType = connectionWireguard
+	case nm.NM_SETTING_BOND_SETTING_NAME:
+		connType = connectionBond
+	case nm.NM_SETTING_BRIDGE_SETTING_NAME:
+		connType = connectionBridge
+	case nm.NM_SETTING_TEAM_SETTING_NAME:
+		connType = connectionTeam
+	case nm.NM_SETTING_VLAN_SETTING_NAME:
+		connType = connectionVlan
 	}
 	if len(connType) == 0 {
 		connType = connectionUnknown
@@ -202,7 +223,7 @@ func isCreatedManuallyConnection(data connectionData) (isCreateManual bool) {
 		return true
 	}
 	switch getCustomConnectionType(data) {
-	case connectionPppoe:
+	case connectionPppoe, connectionWireguard, connectionBond, connectionBridge, connectionTeam, connectionVlan:
 		return true
 	}
 	return false
@@ -244,6 +265,16 @@ func genConnectionId(connType string) (id string) {
 		idPrefix = Tr("VPN StrongSwan")
 	case connectionVpnVpnc:
 		idPrefix = Tr("VPN VPNC")
+	case connectionWireguard:
+		idPrefix = Tr("WireGuard Connection")
+	case connectionBond:
+		idPrefix = Tr("Bond Connection")
+	case connectionBridge:
+		idPrefix = Tr("Bridge Connection")
+	case connectionTeam:
+		idPrefix = Tr("Team Connection")
+	case connectionVlan:
+		idPrefix = Tr("VLAN Connection")
 	}
 	allIds := nmGetConnectionIds()
 	for i := 1; ; i++ {
@@ -255,6 +286,18 @@ func genConnectionId(connType string) (id string) {
 	return
 }
 
+// generate interface name for virtual devices when creating a new
+// connection, such as "bond0"
+func genVirtualInterfaceName(prefix string) (name string) {
+	for i := 0; ; i++ {
+		name = prefix + strconv.Itoa(i)
+		if _, err := net.InterfaceByName(name); err != nil {
+			break
+		}
+	}
+	return
+}
+
 func isConnectionAlwaysAsk(data connectionData, settingName string) (ask bool) {
 	sectionData, ok := data[settingName]
 	if !ok {
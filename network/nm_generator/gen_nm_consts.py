@@ -32,6 +32,7 @@ dbus_type_name_map = {
     'aay': 'ktypeArrayArrayByte',     # array of byte array
     'a(ayuay)': 'ktypeIpv6Addresses', # array of legacy IPv6 address struct
     'a(ayuayu)': 'ktypeIpv6Routes',   # array of legacy IPv6 route struct
+    'aa{sv}': 'ktypeArrayDictStringVariant', # array of vardict, e.g. WireGuard peers
 }
 
 ns_map = {
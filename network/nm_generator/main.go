@@ -18,6 +18,7 @@ import (
 
 const (
 	nmConstsYamlFile             = "./nm_consts_gen.yml"
+	nmExtraSettingsYamlFile      = "./nm_extra_settings.yml"
 	nmConstsKeysOverrideYamlFile = "./nm_consts_keys_override.yml"
 	nmVpnAliasSettingsYamlFile   = "./nm_vpn_alias_settings.yml"
 	nmVirtualSettingYamlFile     = "./nm_virtual_sections.yml"
@@ -41,19 +42,23 @@ var funcMap = template.FuncMap{
 }
 
 var nmConsts nmConstsStruct
+var nmExtraConsts nmConstsStruct
 var nmOverrideKeys []nmSettingKey
 
 type nmConstsStruct struct {
-	NMEnums []struct {
-		EnumClass string `yaml:"EnumClass"`
-		Members   []struct {
-			Name  string      `yaml:"Name"`
-			Value interface{} `yaml:"Value"`
-		} `yaml:"Members"`
-	} `yaml:"NMEnums"`
+	NMEnums    []nmEnum    `yaml:"NMEnums"`
 	NMSettings []nmSetting `yaml:"NMSettings"`
 }
 
+type nmEnum struct {
+	EnumClass string         `yaml:"EnumClass"`
+	Members   []nmEnumMember `yaml:"Members"`
+}
+type nmEnumMember struct {
+	Name  string      `yaml:"Name"`
+	Value interface{} `yaml:"Value"`
+}
+
 type nmSetting struct {
 	SettingClass    string          `yaml:"SettingClass"`
 	Name            string          `yaml:"Name"`
@@ -136,6 +141,8 @@ func main() {
 	flag.Parse()
 
 	yamlUnmarshalFile(nmConstsYamlFile, &nmConsts)
+	yamlUnmarshalFile(nmExtraSettingsYamlFile, &nmExtraConsts)
+	mergeExtraConsts()
 	yamlUnmarshalFile(nmConstsKeysOverrideYamlFile, &nmOverrideKeys)
 	mergeOverrideKeys()
 
@@ -41,6 +41,55 @@ func writeOutputFile(file, content string) {
 	fmt.Println("GEN " + file)
 }
 
+// merge the extra settings and constants which missing in
+// nm_consts_gen.yml, the existing ones will be kept
+func mergeExtraConsts() {
+	for _, esetting := range nmExtraConsts.NMSettings {
+		if !isSettingExists(esetting.Name) {
+			nmConsts.NMSettings = append(nmConsts.NMSettings, esetting)
+		}
+	}
+	for _, eenum := range nmExtraConsts.NMEnums {
+		enum := getEnum(eenum.EnumClass)
+		if enum == nil {
+			nmConsts.NMEnums = append(nmConsts.NMEnums, eenum)
+			continue
+		}
+		for _, emember := range eenum.Members {
+			if !isEnumMemberExists(enum, emember.Name) {
+				enum.Members = append(enum.Members, emember)
+			}
+		}
+	}
+}
+
+func isSettingExists(name string) bool {
+	for _, setting := range nmConsts.NMSettings {
+		if setting.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func getEnum(enumClass string) *nmEnum {
+	for i := range nmConsts.NMEnums {
+		if nmConsts.NMEnums[i].EnumClass == enumClass {
+			return &nmConsts.NMEnums[i]
+		}
+	}
+	return nil
+}
+
+func isEnumMemberExists(enum *nmEnum, name string) bool {
+	for _, member := range enum.Members {
+		if member.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func mergeOverrideKeys() {
 	for _, okey := range nmOverrideKeys {
 		found := false
@@ -171,6 +220,8 @@ func getSettingKeyFixedDefaultValue(ktype, defaultValueYAML string) (fixedDefaul
 		fixedDefaultValue = fixedValue
 	case "ktypeIpv6Addresses", "ktypeIpv6Routes", "ktypeWrapperIpv6Addresses", "ktypeWrapperIpv6Routes":
 		// ignore the combined structure here and it will be filled in GetKeyDefaultValue
+	case "ktypeArrayDictStringVariant", "ktypeWrapperWireguardPeers":
+		// ignore the combined structure here and it will be filled in GetKeyDefaultValue
 	}
 	return
 }
@@ -223,6 +274,8 @@ func doGetKeyDefaultValue(fixedDefaultValue interface{}, name, ktype string) (go
 		gocode = `make(ipv6Addresses, 0)`
 	case "ktypeIpv6Routes", "ktypeWrapperIpv6Routes":
 		gocode = `make(ipv6Routes, 0)`
+	case "ktypeArrayDictStringVariant", "ktypeWrapperWireguardPeers":
+		gocode = `make(arrayDictStringVariant, 0)`
 	}
 	return
 }
@@ -268,6 +321,8 @@ func GetKeyTypeGoSyntax(ktype string) (goSyntax string) {
 		goSyntax = "ipv6Addresses"
 	case "ktypeIpv6Routes", "ktypeWrapperIpv6Routes":
 		goSyntax = "ipv6Routes"
+	case "ktypeArrayDictStringVariant", "ktypeWrapperWireguardPeers":
+		goSyntax = "arrayDictStringVariant"
 	}
 	return
 }
@@ -308,6 +363,8 @@ func GetKeyTypeGoIfcConverterFunc(ktype string) (converter string) {
 		converter = "interfaceToIpv6Addresses"
 	case "ktypeIpv6Routes", "ktypeWrapperIpv6Routes":
 		converter = "interfaceToIpv6Routes"
+	case "ktypeArrayDictStringVariant", "ktypeWrapperWireguardPeers":
+		converter = "interfaceToArrayDictStringVariant"
 	}
 	return
 }
@@ -348,6 +405,8 @@ func IfNeedCheckValueLength(ktype string) (need string) {
 		need = "t"
 	case "ktypeIpv6Routes":
 		need = "t"
+	case "ktypeArrayDictStringVariant":
+		need = "t"
 	case "ktypeWrapperString":
 		need = "t"
 	case "ktypeWrapperMacAddress":
@@ -364,6 +423,8 @@ func IfNeedCheckValueLength(ktype string) (need string) {
 		need = "t"
 	case "ktypeWrapperIpv6Routes":
 		need = "t"
+	case "ktypeWrapperWireguardPeers":
+		need = "t"
 	}
 	return
 }
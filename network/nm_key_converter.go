@@ -12,6 +12,8 @@ package network
 import (
 	"encoding/json"
 	"fmt"
+	"pkg.deepin.io/dde/daemon/network/nm"
+	"pkg.deepin.io/lib/dbus"
 )
 
 const (
@@ -48,6 +50,9 @@ func keyValueToJSON(v interface{}, t ktype) (jsonStr string, err error) {
 	case ktypeWrapperIpv6Routes:
 		tmpv := interfaceToIpv6Routes(v)
 		v = wrapIpv6Routes(tmpv)
+	case ktypeWrapperWireguardPeers:
+		tmpv := interfaceToArrayDictStringVariant(v)
+		v = wrapWireguardPeers(tmpv)
 	}
 
 	jsonStr, err = marshalJSON(v)
@@ -103,6 +108,8 @@ func jsonToKeyValue(jsonStr string, t ktype) (v interface{}, err error) {
 		v, err = jsonToKeyValueWrapperIpv6Addresses(jsonStr)
 	case ktypeWrapperIpv6Routes:
 		v, err = jsonToKeyValueWrapperIpv6Routes(jsonStr)
+	case ktypeWrapperWireguardPeers:
+		v, err = jsonToKeyValueWrapperWireguardPeers(jsonStr)
 	}
 	return
 }
@@ -246,6 +253,16 @@ func jsonToKeyValueWrapperIpv6Routes(jsonStr string) (v ipv6Routes, err error) {
 	v = unwrapIpv6Routes(wrapData)
 	return
 }
+func jsonToKeyValueWrapperWireguardPeers(jsonStr string) (v arrayDictStringVariant, err error) {
+	// wrap ktypeArrayDictStringVariant to [array of (string, string, array of string, uint32)]
+	var wrapData wireguardPeersWrapper
+	err = json.Unmarshal([]byte(jsonStr), &wrapData)
+	if err != nil {
+		return
+	}
+	v = unwrapWireguardPeers(wrapData)
+	return
+}
 
 // Convert dbus variant's value to other data type
 
@@ -405,6 +422,21 @@ func interfaceToDictStringString(v interface{}) (d map[string]string) {
 	return
 }
 
+func interfaceToArrayDictStringVariant(v interface{}) (d arrayDictStringVariant) {
+	if isInterfaceNil(v) {
+		return
+	}
+	switch tmpv := v.(type) {
+	case arrayDictStringVariant:
+		d = tmpv
+	case []map[string]dbus.Variant:
+		d = arrayDictStringVariant(tmpv)
+	default:
+		logger.Errorf("interfaceToArrayDictStringVariant() failed: %#v", v)
+	}
+	return
+}
+
 func interfaceToIpv6Addresses(v interface{}) (d ipv6Addresses) {
 	if isInterfaceNil(v) {
 		return
@@ -589,3 +621,42 @@ func unwrapIpv6Routes(wrapData ipv6RoutesWrapper) (data ipv6Routes) {
 	}
 	return
 }
+
+func wrapWireguardPeers(data arrayDictStringVariant) (wrapData wireguardPeersWrapper) {
+	for _, d := range data {
+		peer := wireguardPeerWrapper{}
+		if v, ok := d[nm.NM_WIREGUARD_PEER_ATTR_PUBLIC_KEY]; ok {
+			peer.PublicKey = interfaceToString(v.Value())
+		}
+		if v, ok := d[nm.NM_WIREGUARD_PEER_ATTR_ENDPOINT]; ok {
+			peer.Endpoint = interfaceToString(v.Value())
+		}
+		if v, ok := d[nm.NM_WIREGUARD_PEER_ATTR_ALLOWED_IPS]; ok {
+			peer.AllowedIps = interfaceToArrayString(v.Value())
+		}
+		if v, ok := d[nm.NM_WIREGUARD_PEER_ATTR_PERSISTENT_KEEPALIVE]; ok {
+			peer.PersistentKeepalive = interfaceToUint32(v.Value())
+		}
+		wrapData = append(wrapData, peer)
+	}
+	return
+}
+func unwrapWireguardPeers(wrapData wireguardPeersWrapper) (data arrayDictStringVariant) {
+	for _, d := range wrapData {
+		// optional attributes are omitted when empty, NetworkManager
+		// will use its own default values
+		peer := make(map[string]dbus.Variant)
+		peer[nm.NM_WIREGUARD_PEER_ATTR_PUBLIC_KEY] = dbus.MakeVariant(d.PublicKey)
+		if len(d.Endpoint) > 0 {
+			peer[nm.NM_WIREGUARD_PEER_ATTR_ENDPOINT] = dbus.MakeVariant(d.Endpoint)
+		}
+		if len(d.AllowedIps) > 0 {
+			peer[nm.NM_WIREGUARD_PEER_ATTR_ALLOWED_IPS] = dbus.MakeVariant(d.AllowedIps)
+		}
+		if d.PersistentKeepalive > 0 {
+			peer[nm.NM_WIREGUARD_PEER_ATTR_PERSISTENT_KEEPALIVE] = dbus.MakeVariant(d.PersistentKeepalive)
+		}
+		data = append(data, peer)
+	}
+	return
+}
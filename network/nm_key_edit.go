@@ -46,6 +46,10 @@ func isJSONValueMeansToDeleteKey(valueJSON string, t ktype) (doDelete bool) {
 		if valueJSON == `[{"Address":"","Prefix":0,"NextHop":"","Metric":0}]` {
 			doDelete = true
 		}
+	case ktypeWrapperWireguardPeers:
+		if valueJSON == `[{"PublicKey":"","Endpoint":"","AllowedIps":null,"PersistentKeepalive":0}]` {
+			doDelete = true
+		}
 	}
 	return
 }
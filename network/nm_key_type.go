@@ -10,6 +10,7 @@
 package network
 
 import (
+	"pkg.deepin.io/lib/dbus"
 	"sort"
 )
 
@@ -51,22 +52,24 @@ const (
 	ktypeArrayByte
 	ktypeArrayString
 	ktypeArrayUint32
-	ktypeArrayArrayByte   // [array of array of byte]
-	ktypeArrayArrayUint32 // [array of array of uint32]
-	ktypeDictStringString // [dict of (string::string)]
-	ktypeIpv6Addresses    // [array of (byte array, uint32, byte array)]
-	ktypeIpv6Routes       // [array of (byte array, uint32, byte array, uint32)]
+	ktypeArrayArrayByte         // [array of array of byte]
+	ktypeArrayArrayUint32       // [array of array of uint32]
+	ktypeDictStringString       // [dict of (string::string)]
+	ktypeIpv6Addresses          // [array of (byte array, uint32, byte array)]
+	ktypeIpv6Routes             // [array of (byte array, uint32, byte array, uint32)]
+	ktypeArrayDictStringVariant // [array of dict of (string::variant)]
 
 	// wrapper for special key type, used by json getter and setter,
 	// in other words, only works for front-end
-	ktypeWrapperString        // wrap ktypeArrayByte to [string]
-	ktypeWrapperMacAddress    // wrap ktypeArrayByte to [string]
-	ktypeWrapperIpv4Dns       // wrap ktypeArrayUint32 to [array of string]
-	ktypeWrapperIpv4Addresses // wrap ktypeArrayArrayUint32 to [array of (string, string, string)]
-	ktypeWrapperIpv4Routes    // wrap ktypeArrayArrayUint32 to [array of (string, string, string, uint32)]
-	ktypeWrapperIpv6Dns       // wrap ktypeArrayArrayByte to [array of string]
-	ktypeWrapperIpv6Addresses // wrap ktypeIpv6Addresses to [array of (string, uint32, string)]
-	ktypeWrapperIpv6Routes    // wrap ktypeIpv6Routes to [array of (string, uint32, string, uint32)]
+	ktypeWrapperString         // wrap ktypeArrayByte to [string]
+	ktypeWrapperMacAddress     // wrap ktypeArrayByte to [string]
+	ktypeWrapperIpv4Dns        // wrap ktypeArrayUint32 to [array of string]
+	ktypeWrapperIpv4Addresses  // wrap ktypeArrayArrayUint32 to [array of (string, string, string)]
+	ktypeWrapperIpv4Routes     // wrap ktypeArrayArrayUint32 to [array of (string, string, string, uint32)]
+	ktypeWrapperIpv6Dns        // wrap ktypeArrayArrayByte to [array of string]
+	ktypeWrapperIpv6Addresses  // wrap ktypeIpv6Addresses to [array of (string, uint32, string)]
+	ktypeWrapperIpv6Routes     // wrap ktypeIpv6Routes to [array of (string, uint32, string, uint32)]
+	ktypeWrapperWireguardPeers // wrap ktypeArrayDictStringVariant to [array of (string, string, array of string, uint32)]
 )
 
 func isWrapperKeyType(t ktype) bool {
@@ -87,6 +90,8 @@ func isWrapperKeyType(t ktype) bool {
 		return true
 	case ktypeWrapperIpv6Routes:
 		return true
+	case ktypeWrapperWireguardPeers:
+		return true
 	}
 	return false
 }
@@ -142,6 +147,18 @@ type ipv6Route struct {
 }
 type ipv6Routes []ipv6Route
 
+// arrayDictStringVariant is an array of vardict, e.g. WireGuard peers
+type arrayDictStringVariant []map[string]dbus.Variant
+
+// wireguardPeersWrapper
+type wireguardPeersWrapper []wireguardPeerWrapper
+type wireguardPeerWrapper struct {
+	PublicKey           string
+	Endpoint            string
+	AllowedIps          []string
+	PersistentKeepalive uint32
+}
+
 func getKtypeDesc(t ktype) (desc string) {
 	switch t {
 	default:
@@ -176,6 +193,8 @@ func getKtypeDesc(t ktype) (desc string) {
 		desc = "Ipv6Addresses, array of (byte array, uint32, byte array), encode by json"
 	case ktypeIpv6Routes:
 		desc = "ipv6Routes, array of (byte array, uint32, byte array, uint32), encode by json"
+	case ktypeArrayDictStringVariant:
+		desc = "ArrayDictStringVariant, array of dict of (string::variant)"
 	case ktypeWrapperString:
 		desc = "wrap ktypeArrayByte to [string]"
 	case ktypeWrapperMacAddress:
@@ -192,6 +211,8 @@ func getKtypeDesc(t ktype) (desc string) {
 		desc = "wrap ktypeIpv6Addresses to [array of (string, uint32, string)]"
 	case ktypeWrapperIpv6Routes:
 		desc = "wrap ktypeIpv6Routes to [array of (string, uint32, string, uint32)]"
+	case ktypeWrapperWireguardPeers:
+		desc = "wrap ktypeArrayDictStringVariant to [array of (string, string, array of string, uint32)]"
 	}
 	return
 }
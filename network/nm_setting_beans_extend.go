@@ -21,26 +21,6 @@ func checkSettingBluetoothValues(data connectionData) (err sectionErrors) {
 	return
 }
 
-func getSettingBondAvailableKeys(data connectionData) (keys []string) {
-	return
-}
-func getSettingBondAvailableValues(data connectionData, key string) (values []kvalue) {
-	return
-}
-func checkSettingBondValues(data connectionData) (err sectionErrors) {
-	return
-}
-
-func getSettingBridgeAvailableKeys(data connectionData) (keys []string) {
-	return
-}
-func getSettingBridgeAvailableValues(data connectionData, key string) (values []kvalue) {
-	return
-}
-func checkSettingBridgeValues(data connectionData) (err sectionErrors) {
-	return
-}
-
 func getSettingBridgePortAvailableKeys(data connectionData) (keys []string) {
 	return
 }
@@ -111,16 +91,6 @@ func checkSettingOlpcMeshValues(data connectionData) (err sectionErrors) {
 	return
 }
 
-func getSettingTeamAvailableKeys(data connectionData) (keys []string) {
-	return
-}
-func getSettingTeamAvailableValues(data connectionData, key string) (values []kvalue) {
-	return
-}
-func checkSettingTeamValues(data connectionData) (err sectionErrors) {
-	return
-}
-
 func getSettingTeamPortAvailableKeys(data connectionData) (keys []string) {
 	return
 }
@@ -141,16 +111,6 @@ func checkSettingTunValues(data connectionData) (err sectionErrors) {
 	return
 }
 
-func getSettingVlanAvailableKeys(data connectionData) (keys []string) {
-	return
-}
-func getSettingVlanAvailableValues(data connectionData, key string) (values []kvalue) {
-	return
-}
-func checkSettingVlanValues(data connectionData) (err sectionErrors) {
-	return
-}
-
 func getSettingVxlanAvailableKeys(data connectionData) (keys []string) {
 	return
 }
@@ -20,6 +20,8 @@ func initVirtualSections() {
 			&GeneralKeyInfo{Section: "connection", Key: "id", Name: Tr("Name"), WidgetType: "EditLineTextInput", AlwaysUpdate: true, UseValueRange: false, MinValue: 0, MaxValue: 0},
 			&GeneralKeyInfo{Section: "connection", Key: "vk-autoconnect", Name: Tr("Automatically connect"), WidgetType: "EditLineSwitchButton", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
 			&GeneralKeyInfo{Section: "connection", Key: "vk-no-permission", Name: Tr("For All Users"), WidgetType: "EditLineSwitchButton", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
+			&GeneralKeyInfo{Section: "connection", Key: "interface-name", Name: Tr("Interface Name"), WidgetType: "EditLineTextInput", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
+			&GeneralKeyInfo{Section: "connection", Key: "vk-master", Name: Tr("Master Connection"), WidgetType: "EditLineComboBox", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
 		},
 	}
 	virtualSections["vs-ethernet"] = VsectionInfo{
@@ -424,6 +426,58 @@ func initVirtualSections() {
 			&GeneralKeyInfo{Section: "alias-vpn-vpnc-advanced", Key: "vk-disable-dpd", Name: Tr("Disable Dead Peer Detection"), WidgetType: "EditLineSwitchButton", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
 		},
 	}
+	virtualSections["vs-wireguard"] = VsectionInfo{
+		VirtualSection:  "vs-wireguard",
+		relatedSections: []string{"wireguard"},
+		Name:            Tr("WireGuard"),
+		Keys: []*GeneralKeyInfo{
+			&GeneralKeyInfo{Section: "wireguard", Key: "private-key", Name: Tr("Private Key"), WidgetType: "EditLinePasswordInput", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
+			&GeneralKeyInfo{Section: "wireguard", Key: "listen-port", Name: Tr("Listen Port"), WidgetType: "EditLineSpinner", AlwaysUpdate: false, UseValueRange: true, MinValue: 0, MaxValue: 65535},
+			&GeneralKeyInfo{Section: "wireguard", Key: "vk-peer-public-key", Name: Tr("Peer Public Key"), WidgetType: "EditLineTextInput", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
+			&GeneralKeyInfo{Section: "wireguard", Key: "vk-peer-endpoint", Name: Tr("Peer Endpoint"), WidgetType: "EditLineTextInput", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
+			&GeneralKeyInfo{Section: "wireguard", Key: "vk-peer-allowed-ips", Name: Tr("Allowed IPs"), WidgetType: "EditLineTextInput", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
+			&GeneralKeyInfo{Section: "wireguard", Key: "vk-peer-persistent-keepalive", Name: Tr("Persistent Keepalive"), WidgetType: "EditLineSpinner", AlwaysUpdate: false, UseValueRange: true, MinValue: 0, MaxValue: 65535},
+		},
+	}
+	virtualSections["vs-bond"] = VsectionInfo{
+		VirtualSection:  "vs-bond",
+		relatedSections: []string{"bond"},
+		Name:            Tr("Bond"),
+		Keys: []*GeneralKeyInfo{
+			&GeneralKeyInfo{Section: "bond", Key: "vk-bond-mode", Name: Tr("Mode"), WidgetType: "EditLineComboBox", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
+			&GeneralKeyInfo{Section: "bond", Key: "vk-bond-miimon", Name: Tr("Link Monitoring Frequency"), WidgetType: "EditLineSpinner", AlwaysUpdate: false, UseValueRange: true, MinValue: 0, MaxValue: 10000},
+		},
+	}
+	virtualSections["vs-bridge"] = VsectionInfo{
+		VirtualSection:  "vs-bridge",
+		relatedSections: []string{"bridge"},
+		Name:            Tr("Bridge"),
+		Keys: []*GeneralKeyInfo{
+			&GeneralKeyInfo{Section: "bridge", Key: "stp", Name: Tr("Enable STP"), WidgetType: "EditLineSwitchButton", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
+			&GeneralKeyInfo{Section: "bridge", Key: "priority", Name: Tr("Priority"), WidgetType: "EditLineSpinner", AlwaysUpdate: false, UseValueRange: true, MinValue: 0, MaxValue: 65535},
+			&GeneralKeyInfo{Section: "bridge", Key: "forward-delay", Name: Tr("Forward Delay"), WidgetType: "EditLineSpinner", AlwaysUpdate: false, UseValueRange: true, MinValue: 2, MaxValue: 30},
+			&GeneralKeyInfo{Section: "bridge", Key: "hello-time", Name: Tr("Hello Time"), WidgetType: "EditLineSpinner", AlwaysUpdate: false, UseValueRange: true, MinValue: 1, MaxValue: 10},
+			&GeneralKeyInfo{Section: "bridge", Key: "max-age", Name: Tr("Max Age"), WidgetType: "EditLineSpinner", AlwaysUpdate: false, UseValueRange: true, MinValue: 6, MaxValue: 40},
+			&GeneralKeyInfo{Section: "bridge", Key: "ageing-time", Name: Tr("Aging Time"), WidgetType: "EditLineSpinner", AlwaysUpdate: false, UseValueRange: true, MinValue: 0, MaxValue: 1000000},
+		},
+	}
+	virtualSections["vs-team"] = VsectionInfo{
+		VirtualSection:  "vs-team",
+		relatedSections: []string{"team"},
+		Name:            Tr("Team"),
+		Keys: []*GeneralKeyInfo{
+			&GeneralKeyInfo{Section: "team", Key: "vk-team-runner", Name: Tr("Runner"), WidgetType: "EditLineComboBox", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
+		},
+	}
+	virtualSections["vs-vlan"] = VsectionInfo{
+		VirtualSection:  "vs-vlan",
+		relatedSections: []string{"vlan"},
+		Name:            Tr("VLAN"),
+		Keys: []*GeneralKeyInfo{
+			&GeneralKeyInfo{Section: "vlan", Key: "parent", Name: Tr("Parent Interface"), WidgetType: "EditLineComboBox", AlwaysUpdate: false, UseValueRange: false, MinValue: 0, MaxValue: 0},
+			&GeneralKeyInfo{Section: "vlan", Key: "id", Name: Tr("VLAN ID"), WidgetType: "EditLineSpinner", AlwaysUpdate: false, UseValueRange: true, MinValue: 0, MaxValue: 4094},
+		},
+	}
 	virtualSections["vs-ipv4"] = VsectionInfo{
 		VirtualSection:  "vs-ipv4",
 		relatedSections: []string{"ipv4"},
@@ -456,6 +510,7 @@ func initVirtualSections() {
 var virtualKeys = []vkeyInfo{
 	{value: "vk-autoconnect", ktype: ktypeBoolean, vkType: vkTypeWrapper, relatedSection: "connection", relatedKeys: []string{nm.NM_SETTING_CONNECTION_AUTOCONNECT}, childKey: false, optional: false},
 	{value: "vk-no-permission", ktype: ktypeBoolean, vkType: vkTypeWrapper, relatedSection: "connection", relatedKeys: []string{nm.NM_SETTING_CONNECTION_PERMISSIONS}, childKey: false, optional: false},
+	{value: "vk-master", ktype: ktypeString, vkType: vkTypeWrapper, relatedSection: "connection", relatedKeys: []string{nm.NM_SETTING_CONNECTION_MASTER, nm.NM_SETTING_CONNECTION_SLAVE_TYPE}, childKey: false, optional: false},
 	{value: "vk-enable-mtu", ktype: ktypeBoolean, vkType: vkTypeEnableWrapper, relatedSection: "802-3-ethernet", relatedKeys: []string{nm.NM_SETTING_WIRED_MTU}, childKey: false, optional: false},
 	{value: "vk-mobile-country", ktype: ktypeString, vkType: vkTypeController, relatedSection: "vs-mobile", relatedKeys: []string{}, childKey: false, optional: false},
 	{value: "vk-mobile-provider", ktype: ktypeString, vkType: vkTypeController, relatedSection: "vs-mobile", relatedKeys: []string{}, childKey: false, optional: false},
@@ -488,6 +543,13 @@ var virtualKeys = []vkeyInfo{
 	{value: "vk-enable-lcp-echo", ktype: ktypeBoolean, vkType: vkTypeWrapper, relatedSection: "alias-vpn-pptp-ppp", relatedKeys: []string{nm.NM_SETTING_VPN_PPTP_KEY_LCP_ECHO_FAILURE, nm.NM_SETTING_VPN_PPTP_KEY_LCP_ECHO_INTERVAL}, childKey: false, optional: false},
 	{value: "vk-encryption-method", ktype: ktypeString, vkType: vkTypeWrapper, relatedSection: "alias-vpn-vpnc-advanced", relatedKeys: []string{nm.NM_SETTING_VPN_VPNC_KEY_SINGLE_DES, nm.NM_SETTING_VPN_VPNC_KEY_NO_ENCRYPTION}, childKey: false, optional: false},
 	{value: "vk-disable-dpd", ktype: ktypeBoolean, vkType: vkTypeWrapper, relatedSection: "alias-vpn-vpnc-advanced", relatedKeys: []string{nm.NM_SETTING_VPN_VPNC_KEY_DPD_IDLE_TIMEOUT}, childKey: false, optional: false},
+	{value: "vk-peer-public-key", ktype: ktypeString, vkType: vkTypeWrapper, relatedSection: "wireguard", relatedKeys: []string{nm.NM_SETTING_WIREGUARD_PEERS}, childKey: true, optional: false},
+	{value: "vk-peer-endpoint", ktype: ktypeString, vkType: vkTypeWrapper, relatedSection: "wireguard", relatedKeys: []string{nm.NM_SETTING_WIREGUARD_PEERS}, childKey: true, optional: true},
+	{value: "vk-peer-allowed-ips", ktype: ktypeString, vkType: vkTypeWrapper, relatedSection: "wireguard", relatedKeys: []string{nm.NM_SETTING_WIREGUARD_PEERS}, childKey: true, optional: true},
+	{value: "vk-peer-persistent-keepalive", ktype: ktypeUint32, vkType: vkTypeWrapper, relatedSection: "wireguard", relatedKeys: []string{nm.NM_SETTING_WIREGUARD_PEERS}, childKey: true, optional: true},
+	{value: "vk-bond-mode", ktype: ktypeString, vkType: vkTypeWrapper, relatedSection: "bond", relatedKeys: []string{nm.NM_SETTING_BOND_OPTIONS}, childKey: true, optional: false},
+	{value: "vk-bond-miimon", ktype: ktypeUint32, vkType: vkTypeWrapper, relatedSection: "bond", relatedKeys: []string{nm.NM_SETTING_BOND_OPTIONS}, childKey: true, optional: true},
+	{value: "vk-team-runner", ktype: ktypeString, vkType: vkTypeWrapper, relatedSection: "team", relatedKeys: []string{nm.NM_SETTING_TEAM_CONFIG}, childKey: false, optional: false},
 	{value: "vk-addresses-address", ktype: ktypeString, vkType: vkTypeWrapper, relatedSection: "ipv4", relatedKeys: []string{nm.NM_SETTING_IP4_CONFIG_ADDRESSES}, childKey: true, optional: false},
 	{value: "vk-addresses-mask", ktype: ktypeString, vkType: vkTypeWrapper, relatedSection: "ipv4", relatedKeys: []string{nm.NM_SETTING_IP4_CONFIG_ADDRESSES}, childKey: true, optional: false},
 	{value: "vk-addresses-gateway", ktype: ktypeString, vkType: vkTypeWrapper, relatedSection: "ipv4", relatedKeys: []string{nm.NM_SETTING_IP4_CONFIG_ADDRESSES}, childKey: true, optional: true},
@@ -508,6 +570,9 @@ func generalGetVkeyJSON(data connectionData, section, key string) (valueJSON str
 	if section == "connection" && key == "vk-no-permission" {
 		return getSettingVkConnectionNoPermissionJSON(data)
 	}
+	if section == "connection" && key == "vk-master" {
+		return getSettingVkConnectionMasterJSON(data)
+	}
 	if section == "802-3-ethernet" && key == "vk-enable-mtu" {
 		return getSettingVkWiredEnableMtuJSON(data)
 	}
@@ -604,6 +669,27 @@ func generalGetVkeyJSON(data connectionData, section, key string) (valueJSON str
 	if section == "alias-vpn-vpnc-advanced" && key == "vk-disable-dpd" {
 		return getSettingVkVpnVpncKeyDisableDpdJSON(data)
 	}
+	if section == "wireguard" && key == "vk-peer-public-key" {
+		return getSettingVkWireguardPeerPublicKeyJSON(data)
+	}
+	if section == "wireguard" && key == "vk-peer-endpoint" {
+		return getSettingVkWireguardPeerEndpointJSON(data)
+	}
+	if section == "wireguard" && key == "vk-peer-allowed-ips" {
+		return getSettingVkWireguardPeerAllowedIpsJSON(data)
+	}
+	if section == "wireguard" && key == "vk-peer-persistent-keepalive" {
+		return getSettingVkWireguardPeerPersistentKeepaliveJSON(data)
+	}
+	if section == "bond" && key == "vk-bond-mode" {
+		return getSettingVkBondModeJSON(data)
+	}
+	if section == "bond" && key == "vk-bond-miimon" {
+		return getSettingVkBondMiimonJSON(data)
+	}
+	if section == "team" && key == "vk-team-runner" {
+		return getSettingVkTeamRunnerJSON(data)
+	}
 	if section == "ipv4" && key == "vk-addresses-address" {
 		return getSettingVkIp4ConfigAddressesAddressJSON(data)
 	}
@@ -654,6 +740,10 @@ func generalSetVkeyJSON(data connectionData, section, key string, valueJSON stri
 		err = logicSetSettingVkConnectionNoPermissionJSON(data, valueJSON)
 		return
 	}
+	if section == "connection" && key == "vk-master" {
+		err = logicSetSettingVkConnectionMasterJSON(data, valueJSON)
+		return
+	}
 	if section == "802-3-ethernet" && key == "vk-enable-mtu" {
 		err = logicSetSettingVkWiredEnableMtuJSON(data, valueJSON)
 		return
@@ -782,6 +872,34 @@ func generalSetVkeyJSON(data connectionData, section, key string, valueJSON stri
 		err = logicSetSettingVkVpnVpncKeyDisableDpdJSON(data, valueJSON)
 		return
 	}
+	if section == "wireguard" && key == "vk-peer-public-key" {
+		err = logicSetSettingVkWireguardPeerPublicKeyJSON(data, valueJSON)
+		return
+	}
+	if section == "wireguard" && key == "vk-peer-endpoint" {
+		err = logicSetSettingVkWireguardPeerEndpointJSON(data, valueJSON)
+		return
+	}
+	if section == "wireguard" && key == "vk-peer-allowed-ips" {
+		err = logicSetSettingVkWireguardPeerAllowedIpsJSON(data, valueJSON)
+		return
+	}
+	if section == "wireguard" && key == "vk-peer-persistent-keepalive" {
+		err = logicSetSettingVkWireguardPeerPersistentKeepaliveJSON(data, valueJSON)
+		return
+	}
+	if section == "bond" && key == "vk-bond-mode" {
+		err = logicSetSettingVkBondModeJSON(data, valueJSON)
+		return
+	}
+	if section == "bond" && key == "vk-bond-miimon" {
+		err = logicSetSettingVkBondMiimonJSON(data, valueJSON)
+		return
+	}
+	if section == "team" && key == "vk-team-runner" {
+		err = logicSetSettingVkTeamRunnerJSON(data, valueJSON)
+		return
+	}
 	if section == "ipv4" && key == "vk-addresses-address" {
 		err = logicSetSettingVkIp4ConfigAddressesAddressJSON(data, valueJSON)
 		return
@@ -835,6 +953,10 @@ func getSettingVkConnectionNoPermissionJSON(data connectionData) (valueJSON stri
 	valueJSON, _ = marshalJSON(getSettingVkConnectionNoPermission(data))
 	return
 }
+func getSettingVkConnectionMasterJSON(data connectionData) (valueJSON string) {
+	valueJSON, _ = marshalJSON(getSettingVkConnectionMaster(data))
+	return
+}
 func getSettingVkWiredEnableMtuJSON(data connectionData) (valueJSON string) {
 	valueJSON, _ = marshalJSON(getSettingVkWiredEnableMtu(data))
 	return
@@ -963,6 +1085,34 @@ func getSettingVkVpnVpncKeyDisableDpdJSON(data connectionData) (valueJSON string
 	valueJSON, _ = marshalJSON(getSettingVkVpnVpncKeyDisableDpd(data))
 	return
 }
+func getSettingVkWireguardPeerPublicKeyJSON(data connectionData) (valueJSON string) {
+	valueJSON, _ = marshalJSON(getSettingVkWireguardPeerPublicKey(data))
+	return
+}
+func getSettingVkWireguardPeerEndpointJSON(data connectionData) (valueJSON string) {
+	valueJSON, _ = marshalJSON(getSettingVkWireguardPeerEndpoint(data))
+	return
+}
+func getSettingVkWireguardPeerAllowedIpsJSON(data connectionData) (valueJSON string) {
+	valueJSON, _ = marshalJSON(getSettingVkWireguardPeerAllowedIps(data))
+	return
+}
+func getSettingVkWireguardPeerPersistentKeepaliveJSON(data connectionData) (valueJSON string) {
+	valueJSON, _ = marshalJSON(getSettingVkWireguardPeerPersistentKeepalive(data))
+	return
+}
+func getSettingVkBondModeJSON(data connectionData) (valueJSON string) {
+	valueJSON, _ = marshalJSON(getSettingVkBondMode(data))
+	return
+}
+func getSettingVkBondMiimonJSON(data connectionData) (valueJSON string) {
+	valueJSON, _ = marshalJSON(getSettingVkBondMiimon(data))
+	return
+}
+func getSettingVkTeamRunnerJSON(data connectionData) (valueJSON string) {
+	valueJSON, _ = marshalJSON(getSettingVkTeamRunner(data))
+	return
+}
 func getSettingVkIp4ConfigAddressesAddressJSON(data connectionData) (valueJSON string) {
 	valueJSON, _ = marshalJSON(getSettingVkIp4ConfigAddressesAddress(data))
 	return
@@ -1013,6 +1163,10 @@ func logicSetSettingVkConnectionNoPermissionJSON(data connectionData, valueJSON
 	value, _ := jsonToKeyValueBoolean(valueJSON)
 	return logicSetSettingVkConnectionNoPermission(data, value)
 }
+func logicSetSettingVkConnectionMasterJSON(data connectionData, valueJSON string) (err error) {
+	value, _ := jsonToKeyValueString(valueJSON)
+	return logicSetSettingVkConnectionMaster(data, value)
+}
 func logicSetSettingVkWiredEnableMtuJSON(data connectionData, valueJSON string) (err error) {
 	value, _ := jsonToKeyValueBoolean(valueJSON)
 	return logicSetSettingVkWiredEnableMtu(data, value)
@@ -1141,6 +1295,34 @@ func logicSetSettingVkVpnVpncKeyDisableDpdJSON(data connectionData, valueJSON st
 	value, _ := jsonToKeyValueBoolean(valueJSON)
 	return logicSetSettingVkVpnVpncKeyDisableDpd(data, value)
 }
+func logicSetSettingVkWireguardPeerPublicKeyJSON(data connectionData, valueJSON string) (err error) {
+	value, _ := jsonToKeyValueString(valueJSON)
+	return logicSetSettingVkWireguardPeerPublicKey(data, value)
+}
+func logicSetSettingVkWireguardPeerEndpointJSON(data connectionData, valueJSON string) (err error) {
+	value, _ := jsonToKeyValueString(valueJSON)
+	return logicSetSettingVkWireguardPeerEndpoint(data, value)
+}
+func logicSetSettingVkWireguardPeerAllowedIpsJSON(data connectionData, valueJSON string) (err error) {
+	value, _ := jsonToKeyValueString(valueJSON)
+	return logicSetSettingVkWireguardPeerAllowedIps(data, value)
+}
+func logicSetSettingVkWireguardPeerPersistentKeepaliveJSON(data connectionData, valueJSON string) (err error) {
+	value, _ := jsonToKeyValueUint32(valueJSON)
+	return logicSetSettingVkWireguardPeerPersistentKeepalive(data, value)
+}
+func logicSetSettingVkBondModeJSON(data connectionData, valueJSON string) (err error) {
+	value, _ := jsonToKeyValueString(valueJSON)
+	return logicSetSettingVkBondMode(data, value)
+}
+func logicSetSettingVkBondMiimonJSON(data connectionData, valueJSON string) (err error) {
+	value, _ := jsonToKeyValueUint32(valueJSON)
+	return logicSetSettingVkBondMiimon(data, value)
+}
+func logicSetSettingVkTeamRunnerJSON(data connectionData, valueJSON string) (err error) {
+	value, _ := jsonToKeyValueString(valueJSON)
+	return logicSetSettingVkTeamRunner(data, value)
+}
 func logicSetSettingVkIp4ConfigAddressesAddressJSON(data connectionData, valueJSON string) (err error) {
 	value, _ := jsonToKeyValueString(valueJSON)
 	return logicSetSettingVkIp4ConfigAddressesAddress(data, value)
@@ -1985,6 +2167,23 @@ func generalIsKeyShouldInSettingSection(section, key string) bool {
 		case "wep-tx-keyidx":
 			return true
 		}
+	case "wireguard":
+		switch key {
+		case "fwmark":
+			return true
+		case "listen-port":
+			return true
+		case "mtu":
+			return true
+		case "peer-routes":
+			return true
+		case "peers":
+			return true
+		case "private-key":
+			return true
+		case "private-key-flags":
+			return true
+		}
 	case "alias-vpn-l2tp":
 		switch key {
 		case "gateway":
@@ -3000,6 +3199,25 @@ func generalGetSettingKeyType(section, key string) (t ktype) {
 		case "wep-tx-keyidx":
 			t = ktypeUint32
 		}
+	case "wireguard":
+		switch key {
+		default:
+			t = ktypeUnknown
+		case "fwmark":
+			t = ktypeUint32
+		case "listen-port":
+			t = ktypeUint32
+		case "mtu":
+			t = ktypeUint32
+		case "peer-routes":
+			t = ktypeBoolean
+		case "peers":
+			t = ktypeWrapperWireguardPeers
+		case "private-key":
+			t = ktypeString
+		case "private-key-flags":
+			t = ktypeUint32
+		}
 	case "alias-vpn-l2tp":
 		switch key {
 		default:
@@ -3395,6 +3613,8 @@ func generalGetSettingAvailableKeys(data connectionData, section string) (keys [
 		keys = getSettingWirelessAvailableKeys(data)
 	case "802-11-wireless-security":
 		keys = getSettingWirelessSecurityAvailableKeys(data)
+	case "wireguard":
+		keys = getSettingWireGuardAvailableKeys(data)
 	case "alias-vpn-l2tp":
 		keys = getSettingVpnL2tpAvailableKeys(data)
 	case "alias-vpn-l2tp-ppp":
@@ -3494,6 +3714,8 @@ func generalGetSettingAvailableValues(data connectionData, section, key string)
 		values = getSettingWirelessAvailableValues(data, key)
 	case "802-11-wireless-security":
 		values = getSettingWirelessSecurityAvailableValues(data, key)
+	case "wireguard":
+		values = getSettingWireGuardAvailableValues(data, key)
 	case "alias-vpn-l2tp":
 		values = getSettingVpnL2tpAvailableValues(data, key)
 	case "alias-vpn-l2tp-ppp":
@@ -3592,6 +3814,8 @@ func generalCheckSettingValues(data connectionData, section string) (errs sectio
 		errs = checkSettingWirelessValues(data)
 	case "802-11-wireless-security":
 		errs = checkSettingWirelessSecurityValues(data)
+	case "wireguard":
+		errs = checkSettingWireGuardValues(data)
 	case "alias-vpn-l2tp":
 		errs = checkSettingVpnL2tpValues(data)
 	case "alias-vpn-l2tp-ppp":
@@ -4335,6 +4559,25 @@ func generalGetSettingDefaultValue(setting, key string) (defvalue interface{}) {
 		case "wep-tx-keyidx":
 			defvalue = uint32(0x0)
 		}
+	case "wireguard":
+		switch key {
+		default:
+			logger.Error("invalid key:", setting, key)
+		case "fwmark":
+			defvalue = uint32(0x0)
+		case "listen-port":
+			defvalue = uint32(0x0)
+		case "mtu":
+			defvalue = uint32(0x0)
+		case "peer-routes":
+			defvalue = true
+		case "peers":
+			defvalue = make(arrayDictStringVariant, 0)
+		case "private-key":
+			defvalue = ""
+		case "private-key-flags":
+			defvalue = uint32(0x0)
+		}
 	case "alias-vpn-l2tp":
 		switch key {
 		default:
@@ -5378,6 +5621,25 @@ func generalGetSettingKeyJSON(data connectionData, section, key string) (valueJS
 		case "wep-tx-keyidx":
 			valueJSON = getSettingWirelessSecurityWepTxKeyidxJSON(data)
 		}
+	case "wireguard":
+		switch key {
+		default:
+			logger.Error("getSettingKeyJSON: invalide key", section, key)
+		case "fwmark":
+			valueJSON = getSettingWireGuardFwmarkJSON(data)
+		case "listen-port":
+			valueJSON = getSettingWireGuardListenPortJSON(data)
+		case "mtu":
+			valueJSON = getSettingWireGuardMtuJSON(data)
+		case "peer-routes":
+			valueJSON = getSettingWireGuardPeerRoutesJSON(data)
+		case "peers":
+			valueJSON = getSettingWireGuardPeersJSON(data)
+		case "private-key":
+			valueJSON = getSettingWireGuardPrivateKeyJSON(data)
+		case "private-key-flags":
+			valueJSON = getSettingWireGuardPrivateKeyFlagsJSON(data)
+		}
 	case "alias-vpn-l2tp":
 		switch key {
 		default:
@@ -6452,6 +6714,26 @@ func generalSetSettingKeyJSON(data connectionData, section, key, valueJSON strin
 		case "wep-tx-keyidx":
 			err = setSettingWirelessSecurityWepTxKeyidxJSON(data, valueJSON)
 		}
+	case "wireguard":
+		switch key {
+		default:
+			err = fmt.Errorf("setSettingKeyJSON: invalide key %s %s", section, key)
+			logger.Error(err)
+		case "fwmark":
+			err = setSettingWireGuardFwmarkJSON(data, valueJSON)
+		case "listen-port":
+			err = setSettingWireGuardListenPortJSON(data, valueJSON)
+		case "mtu":
+			err = setSettingWireGuardMtuJSON(data, valueJSON)
+		case "peer-routes":
+			err = setSettingWireGuardPeerRoutesJSON(data, valueJSON)
+		case "peers":
+			err = setSettingWireGuardPeersJSON(data, valueJSON)
+		case "private-key":
+			err = setSettingWireGuardPrivateKeyJSON(data, valueJSON)
+		case "private-key-flags":
+			err = setSettingWireGuardPrivateKeyFlagsJSON(data, valueJSON)
+		}
 	case "alias-vpn-l2tp":
 		switch key {
 		default:
@@ -9035,6 +9317,58 @@ func ensureSettingWirelessSecurityWepTxKeyidxNoEmpty(data connectionData, errs s
 		rememberError(errs, "802-11-wireless-security", "wep-tx-keyidx", nmKeyErrorMissingValue)
 	}
 }
+func ensureSectionSettingWireGuardExists(data connectionData, errs sectionErrors, relatedKey string) {
+	if !isSettingExists(data, "wireguard") {
+		rememberError(errs, relatedKey, "wireguard", fmt.Sprintf(nmKeyErrorMissingSection, "wireguard"))
+	}
+	sectionData, _ := data["wireguard"]
+	if len(sectionData) == 0 {
+		rememberError(errs, relatedKey, "wireguard", fmt.Sprintf(nmKeyErrorEmptySection, "wireguard"))
+	}
+}
+func ensureSettingWireGuardFwmarkNoEmpty(data connectionData, errs sectionErrors) {
+	if !isSettingWireGuardFwmarkExists(data) {
+		rememberError(errs, "wireguard", "fwmark", nmKeyErrorMissingValue)
+	}
+}
+func ensureSettingWireGuardListenPortNoEmpty(data connectionData, errs sectionErrors) {
+	if !isSettingWireGuardListenPortExists(data) {
+		rememberError(errs, "wireguard", "listen-port", nmKeyErrorMissingValue)
+	}
+}
+func ensureSettingWireGuardMtuNoEmpty(data connectionData, errs sectionErrors) {
+	if !isSettingWireGuardMtuExists(data) {
+		rememberError(errs, "wireguard", "mtu", nmKeyErrorMissingValue)
+	}
+}
+func ensureSettingWireGuardPeerRoutesNoEmpty(data connectionData, errs sectionErrors) {
+	if !isSettingWireGuardPeerRoutesExists(data) {
+		rememberError(errs, "wireguard", "peer-routes", nmKeyErrorMissingValue)
+	}
+}
+func ensureSettingWireGuardPeersNoEmpty(data connectionData, errs sectionErrors) {
+	if !isSettingWireGuardPeersExists(data) {
+		rememberError(errs, "wireguard", "peers", nmKeyErrorMissingValue)
+	}
+	value := getSettingWireGuardPeers(data)
+	if len(value) == 0 {
+		rememberError(errs, "wireguard", "peers", nmKeyErrorEmptyValue)
+	}
+}
+func ensureSettingWireGuardPrivateKeyNoEmpty(data connectionData, errs sectionErrors) {
+	if !isSettingWireGuardPrivateKeyExists(data) {
+		rememberError(errs, "wireguard", "private-key", nmKeyErrorMissingValue)
+	}
+	value := getSettingWireGuardPrivateKey(data)
+	if len(value) == 0 {
+		rememberError(errs, "wireguard", "private-key", nmKeyErrorEmptyValue)
+	}
+}
+func ensureSettingWireGuardPrivateKeyFlagsNoEmpty(data connectionData, errs sectionErrors) {
+	if !isSettingWireGuardPrivateKeyFlagsExists(data) {
+		rememberError(errs, "wireguard", "private-key-flags", nmKeyErrorMissingValue)
+	}
+}
 func ensureSectionSettingVpnL2tpExists(data connectionData, errs sectionErrors, relatedKey string) {
 	if !isSettingExists(data, "alias-vpn-l2tp") {
 		rememberError(errs, relatedKey, "alias-vpn-l2tp", fmt.Sprintf(nmKeyErrorMissingSection, "alias-vpn-l2tp"))
@@ -10892,6 +11226,27 @@ func isSettingWirelessSecurityWepKey3Exists(data connectionData) bool {
 func isSettingWirelessSecurityWepTxKeyidxExists(data connectionData) bool {
 	return isSettingKeyExists(data, "802-11-wireless-security", "wep-tx-keyidx")
 }
+func isSettingWireGuardFwmarkExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "fwmark")
+}
+func isSettingWireGuardListenPortExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "listen-port")
+}
+func isSettingWireGuardMtuExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "mtu")
+}
+func isSettingWireGuardPeerRoutesExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "peer-routes")
+}
+func isSettingWireGuardPeersExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "peers")
+}
+func isSettingWireGuardPrivateKeyExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "private-key")
+}
+func isSettingWireGuardPrivateKeyFlagsExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "private-key-flags")
+}
 func isSettingVpnL2tpKeyGatewayExists(data connectionData) bool {
 	return isSettingKeyExists(data, "alias-vpn-l2tp", "gateway")
 }
@@ -12665,6 +13020,41 @@ func getSettingWirelessSecurityWepTxKeyidx(data connectionData) (value uint32) {
 	value = interfaceToUint32(ivalue)
 	return
 }
+func getSettingWireGuardFwmark(data connectionData) (value uint32) {
+	ivalue := getSettingKey(data, "wireguard", "fwmark")
+	value = interfaceToUint32(ivalue)
+	return
+}
+func getSettingWireGuardListenPort(data connectionData) (value uint32) {
+	ivalue := getSettingKey(data, "wireguard", "listen-port")
+	value = interfaceToUint32(ivalue)
+	return
+}
+func getSettingWireGuardMtu(data connectionData) (value uint32) {
+	ivalue := getSettingKey(data, "wireguard", "mtu")
+	value = interfaceToUint32(ivalue)
+	return
+}
+func getSettingWireGuardPeerRoutes(data connectionData) (value bool) {
+	ivalue := getSettingKey(data, "wireguard", "peer-routes")
+	value = interfaceToBoolean(ivalue)
+	return
+}
+func getSettingWireGuardPeers(data connectionData) (value arrayDictStringVariant) {
+	ivalue := getSettingKey(data, "wireguard", "peers")
+	value = interfaceToArrayDictStringVariant(ivalue)
+	return
+}
+func getSettingWireGuardPrivateKey(data connectionData) (value string) {
+	ivalue := getSettingKey(data, "wireguard", "private-key")
+	value = interfaceToString(ivalue)
+	return
+}
+func getSettingWireGuardPrivateKeyFlags(data connectionData) (value uint32) {
+	ivalue := getSettingKey(data, "wireguard", "private-key-flags")
+	value = interfaceToUint32(ivalue)
+	return
+}
 func getSettingVpnL2tpKeyGateway(data connectionData) (value string) {
 	ivalue := getSettingKey(data, "alias-vpn-l2tp", "gateway")
 	value = interfaceToString(ivalue)
@@ -14136,6 +14526,27 @@ func setSettingWirelessSecurityWepKey3(data connectionData, value string) {
 func setSettingWirelessSecurityWepTxKeyidx(data connectionData, value uint32) {
 	setSettingKey(data, "802-11-wireless-security", "wep-tx-keyidx", value)
 }
+func setSettingWireGuardFwmark(data connectionData, value uint32) {
+	setSettingKey(data, "wireguard", "fwmark", value)
+}
+func setSettingWireGuardListenPort(data connectionData, value uint32) {
+	setSettingKey(data, "wireguard", "listen-port", value)
+}
+func setSettingWireGuardMtu(data connectionData, value uint32) {
+	setSettingKey(data, "wireguard", "mtu", value)
+}
+func setSettingWireGuardPeerRoutes(data connectionData, value bool) {
+	setSettingKey(data, "wireguard", "peer-routes", value)
+}
+func setSettingWireGuardPeers(data connectionData, value arrayDictStringVariant) {
+	setSettingKey(data, "wireguard", "peers", value)
+}
+func setSettingWireGuardPrivateKey(data connectionData, value string) {
+	setSettingKey(data, "wireguard", "private-key", value)
+}
+func setSettingWireGuardPrivateKeyFlags(data connectionData, value uint32) {
+	setSettingKey(data, "wireguard", "private-key-flags", value)
+}
 func setSettingVpnL2tpKeyGateway(data connectionData, value string) {
 	setSettingKey(data, "alias-vpn-l2tp", "gateway", value)
 }
@@ -15631,6 +16042,34 @@ func getSettingWirelessSecurityWepTxKeyidxJSON(data connectionData) (valueJSON s
 	valueJSON = getSettingKeyJSON(data, "802-11-wireless-security", "wep-tx-keyidx", ktypeUint32)
 	return
 }
+func getSettingWireGuardFwmarkJSON(data connectionData) (valueJSON string) {
+	valueJSON = getSettingKeyJSON(data, "wireguard", "fwmark", ktypeUint32)
+	return
+}
+func getSettingWireGuardListenPortJSON(data connectionData) (valueJSON string) {
+	valueJSON = getSettingKeyJSON(data, "wireguard", "listen-port", ktypeUint32)
+	return
+}
+func getSettingWireGuardMtuJSON(data connectionData) (valueJSON string) {
+	valueJSON = getSettingKeyJSON(data, "wireguard", "mtu", ktypeUint32)
+	return
+}
+func getSettingWireGuardPeerRoutesJSON(data connectionData) (valueJSON string) {
+	valueJSON = getSettingKeyJSON(data, "wireguard", "peer-routes", ktypeBoolean)
+	return
+}
+func getSettingWireGuardPeersJSON(data connectionData) (valueJSON string) {
+	valueJSON = getSettingKeyJSON(data, "wireguard", "peers", ktypeWrapperWireguardPeers)
+	return
+}
+func getSettingWireGuardPrivateKeyJSON(data connectionData) (valueJSON string) {
+	valueJSON = getSettingKeyJSON(data, "wireguard", "private-key", ktypeString)
+	return
+}
+func getSettingWireGuardPrivateKeyFlagsJSON(data connectionData) (valueJSON string) {
+	valueJSON = getSettingKeyJSON(data, "wireguard", "private-key-flags", ktypeUint32)
+	return
+}
 func getSettingVpnL2tpKeyGatewayJSON(data connectionData) (valueJSON string) {
 	valueJSON = getSettingKeyJSON(data, "alias-vpn-l2tp", "gateway", ktypeString)
 	return
@@ -16975,6 +17414,27 @@ func setSettingWirelessSecurityWepKey3JSON(data connectionData, valueJSON string
 func setSettingWirelessSecurityWepTxKeyidxJSON(data connectionData, valueJSON string) (err error) {
 	return setSettingKeyJSON(data, "802-11-wireless-security", "wep-tx-keyidx", valueJSON, ktypeUint32)
 }
+func setSettingWireGuardFwmarkJSON(data connectionData, valueJSON string) (err error) {
+	return setSettingKeyJSON(data, "wireguard", "fwmark", valueJSON, ktypeUint32)
+}
+func setSettingWireGuardListenPortJSON(data connectionData, valueJSON string) (err error) {
+	return setSettingKeyJSON(data, "wireguard", "listen-port", valueJSON, ktypeUint32)
+}
+func setSettingWireGuardMtuJSON(data connectionData, valueJSON string) (err error) {
+	return setSettingKeyJSON(data, "wireguard", "mtu", valueJSON, ktypeUint32)
+}
+func setSettingWireGuardPeerRoutesJSON(data connectionData, valueJSON string) (err error) {
+	return setSettingKeyJSON(data, "wireguard", "peer-routes", valueJSON, ktypeBoolean)
+}
+func setSettingWireGuardPeersJSON(data connectionData, valueJSON string) (err error) {
+	return setSettingKeyJSON(data, "wireguard", "peers", valueJSON, ktypeWrapperWireguardPeers)
+}
+func setSettingWireGuardPrivateKeyJSON(data connectionData, valueJSON string) (err error) {
+	return setSettingKeyJSON(data, "wireguard", "private-key", valueJSON, ktypeString)
+}
+func setSettingWireGuardPrivateKeyFlagsJSON(data connectionData, valueJSON string) (err error) {
+	return setSettingKeyJSON(data, "wireguard", "private-key-flags", valueJSON, ktypeUint32)
+}
 func setSettingVpnL2tpKeyGatewayJSON(data connectionData, valueJSON string) (err error) {
 	return setSettingKeyJSON(data, "alias-vpn-l2tp", "gateway", valueJSON, ktypeString)
 }
@@ -18403,6 +18863,27 @@ func removeSettingWirelessSecurityWepKey3(data connectionData) {
 func removeSettingWirelessSecurityWepTxKeyidx(data connectionData) {
 	removeSettingKey(data, "802-11-wireless-security", "wep-tx-keyidx")
 }
+func removeSettingWireGuardFwmark(data connectionData) {
+	removeSettingKey(data, "wireguard", "fwmark")
+}
+func removeSettingWireGuardListenPort(data connectionData) {
+	removeSettingKey(data, "wireguard", "listen-port")
+}
+func removeSettingWireGuardMtu(data connectionData) {
+	removeSettingKey(data, "wireguard", "mtu")
+}
+func removeSettingWireGuardPeerRoutes(data connectionData) {
+	removeSettingKey(data, "wireguard", "peer-routes")
+}
+func removeSettingWireGuardPeers(data connectionData) {
+	removeSettingKey(data, "wireguard", "peers")
+}
+func removeSettingWireGuardPrivateKey(data connectionData) {
+	removeSettingKey(data, "wireguard", "private-key")
+}
+func removeSettingWireGuardPrivateKeyFlags(data connectionData) {
+	removeSettingKey(data, "wireguard", "private-key-flags")
+}
 func removeSettingVpnL2tpKeyGateway(data connectionData) {
 	removeSettingKey(data, "alias-vpn-l2tp", "gateway")
 }
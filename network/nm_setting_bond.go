@@ -0,0 +1,138 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"pkg.deepin.io/dde/daemon/network/nm"
+	"strconv"
+)
+
+// bonding modes, see the kernel document
+// Documentation/networking/bonding.txt for more details
+const (
+	bondModeBalanceRr    = "balance-rr"
+	bondModeActiveBackup = "active-backup"
+	bondModeBalanceXor   = "balance-xor"
+	bondModeBroadcast    = "broadcast"
+	bondMode8023ad       = "802.3ad"
+	bondModeBalanceTlb   = "balance-tlb"
+	bondModeBalanceAlb   = "balance-alb"
+)
+
+// the index of bonding mode is also a valid value of option "mode"
+var bondModes = []string{
+	bondModeBalanceRr,
+	bondModeActiveBackup,
+	bondModeBalanceXor,
+	bondModeBroadcast,
+	bondMode8023ad,
+	bondModeBalanceTlb,
+	bondModeBalanceAlb,
+}
+
+const bondDefaultMiimon = 100
+
+func newBondConnectionData(id, uuid string) (data connectionData) {
+	data = make(connectionData)
+
+	addSetting(data, nm.NM_SETTING_CONNECTION_SETTING_NAME)
+	setSettingConnectionId(data, id)
+	setSettingConnectionUuid(data, uuid)
+	setSettingConnectionType(data, nm.NM_SETTING_BOND_SETTING_NAME)
+	setSettingConnectionInterfaceName(data, genVirtualInterfaceName("bond"))
+
+	addSetting(data, nm.NM_SETTING_BOND_SETTING_NAME)
+	setSettingBondOptions(data, map[string]string{
+		nm.NM_SETTING_BOND_OPTION_MODE:   bondModeBalanceRr,
+		nm.NM_SETTING_BOND_OPTION_MIIMON: strconv.Itoa(bondDefaultMiimon),
+	})
+
+	initSettingSectionIpv4(data)
+	initSettingSectionIpv6(data)
+	return
+}
+
+// Get available keys
+func getSettingBondAvailableKeys(data connectionData) (keys []string) {
+	keys = appendAvailableKeys(data, keys, nm.NM_SETTING_BOND_SETTING_NAME, nm.NM_SETTING_BOND_OPTIONS)
+	return
+}
+
+// Get available values
+func getSettingBondAvailableValues(data connectionData, key string) (values []kvalue) {
+	return
+}
+
+// Check whether the values are correct
+func checkSettingBondValues(data connectionData) (errs sectionErrors) {
+	errs = make(map[string]string)
+	ensureSettingBondOptionsNoEmpty(data, errs)
+	if len(errs) > 0 {
+		return
+	}
+	mode := getSettingBondOptions(data)[nm.NM_SETTING_BOND_OPTION_MODE]
+	if len(mode) > 0 && len(toBondModeName(mode)) == 0 {
+		rememberError(errs, nm.NM_SETTING_BOND_SETTING_NAME, nm.NM_SETTING_BOND_OPTIONS, nmKeyErrorInvalidValue)
+	}
+	return
+}
+
+// toBondModeName convert the numeric bonding mode to its name, and
+// return empty string if the mode is invalid
+func toBondModeName(mode string) string {
+	if isStringInArray(mode, bondModes) {
+		return mode
+	}
+	if i, err := strconv.Atoi(mode); err == nil && i >= 0 && i < len(bondModes) {
+		return bondModes[i]
+	}
+	return ""
+}
+
+func setSettingBondOption(data connectionData, option, value string) {
+	// copy the options to avoid modifying the original map directly
+	options := make(map[string]string)
+	for k, v := range getSettingBondOptions(data) {
+		options[k] = v
+	}
+	if len(value) == 0 {
+		delete(options, option)
+	} else {
+		options[option] = value
+	}
+	setSettingBondOptions(data, options)
+}
+
+// Virtual key getter
+func getSettingVkBondMode(data connectionData) (value string) {
+	mode, ok := getSettingBondOptions(data)[nm.NM_SETTING_BOND_OPTION_MODE]
+	if !ok {
+		// "balance-rr" is the default mode for kernel bonding driver
+		return bondModeBalanceRr
+	}
+	if value = toBondModeName(mode); len(value) == 0 {
+		value = mode
+	}
+	return
+}
+func getSettingVkBondMiimon(data connectionData) (value uint32) {
+	miimon, _ := strconv.ParseUint(getSettingBondOptions(data)[nm.NM_SETTING_BOND_OPTION_MIIMON], 10, 32)
+	return uint32(miimon)
+}
+
+// Virtual key logic setter
+func logicSetSettingVkBondMode(data connectionData, value string) (err error) {
+	setSettingBondOption(data, nm.NM_SETTING_BOND_OPTION_MODE, value)
+	return
+}
+func logicSetSettingVkBondMiimon(data connectionData, value uint32) (err error) {
+	setSettingBondOption(data, nm.NM_SETTING_BOND_OPTION_MIIMON, strconv.FormatUint(uint64(value), 10))
+	return
+}
@@ -0,0 +1,60 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	C "launchpad.net/gocheck"
+	"pkg.deepin.io/dde/daemon/network/nm"
+)
+
+func (*testWrapper) TestBondVirtualKeys(c *C.C) {
+	data := make(connectionData)
+	addSetting(data, nm.NM_SETTING_BOND_SETTING_NAME)
+	c.Check(getSettingVkBondMode(data), C.Equals, bondModeBalanceRr)
+	c.Check(getSettingVkBondMiimon(data), C.Equals, uint32(0))
+
+	logicSetSettingVkBondMode(data, bondModeActiveBackup)
+	logicSetSettingVkBondMiimon(data, 100)
+	c.Check(getSettingBondOptions(data), C.DeepEquals, map[string]string{"mode": "active-backup", "miimon": "100"})
+	c.Check(len(checkSettingBondValues(data)), C.Equals, 0)
+
+	// numeric mode is also accepted by the bonding driver
+	setSettingBondOptions(data, map[string]string{"mode": "4"})
+	c.Check(getSettingVkBondMode(data), C.Equals, bondMode8023ad)
+	setSettingBondOptions(data, map[string]string{"mode": "7"})
+	c.Check(len(checkSettingBondValues(data)), C.Equals, 1)
+}
+
+func (*testWrapper) TestTeamVirtualKeys(c *C.C) {
+	data := make(connectionData)
+	addSetting(data, nm.NM_SETTING_TEAM_SETTING_NAME)
+	c.Check(getSettingVkTeamRunner(data), C.Equals, teamRunnerRoundrobin)
+
+	logicSetSettingVkTeamRunner(data, teamRunnerLacp)
+	c.Check(getSettingTeamConfig(data), C.Equals, `{"runner":{"name":"lacp"}}`)
+
+	// other fields of team config should be kept
+	setSettingTeamConfig(data, `{"device":"team0","runner":{"name":"activebackup","hwaddr_policy":"same_all"}}`)
+	logicSetSettingVkTeamRunner(data, teamRunnerLoadbalance)
+	c.Check(getSettingTeamConfig(data), C.Equals, `{"device":"team0","runner":{"hwaddr_policy":"same_all","name":"loadbalance"}}`)
+	c.Check(len(checkSettingTeamValues(data)), C.Equals, 0)
+
+	setSettingTeamConfig(data, `{"runner":{"name":"unknown"}}`)
+	c.Check(len(checkSettingTeamValues(data)), C.Equals, 1)
+}
+
+func (*testWrapper) TestInterfaceNameValid(c *C.C) {
+	c.Check(isInterfaceNameValid("bond0"), C.Equals, true)
+	c.Check(isInterfaceNameValid("eth0.100"), C.Equals, true)
+	c.Check(isInterfaceNameValid(""), C.Equals, false)
+	c.Check(isInterfaceNameValid("a-very-long-interface"), C.Equals, false)
+	c.Check(isInterfaceNameValid("br 0"), C.Equals, false)
+	c.Check(isInterfaceNameValid(".."), C.Equals, false)
+}
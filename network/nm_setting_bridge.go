@@ -0,0 +1,78 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"pkg.deepin.io/dde/daemon/network/nm"
+)
+
+func newBridgeConnectionData(id, uuid string) (data connectionData) {
+	data = make(connectionData)
+
+	addSetting(data, nm.NM_SETTING_CONNECTION_SETTING_NAME)
+	setSettingConnectionId(data, id)
+	setSettingConnectionUuid(data, uuid)
+	setSettingConnectionType(data, nm.NM_SETTING_BRIDGE_SETTING_NAME)
+	setSettingConnectionInterfaceName(data, genVirtualInterfaceName("br"))
+
+	// use the same default values with network-manager
+	addSetting(data, nm.NM_SETTING_BRIDGE_SETTING_NAME)
+	setSettingBridgeStp(data, true)
+	setSettingBridgePriority(data, 32768)
+	setSettingBridgeForwardDelay(data, 15)
+	setSettingBridgeHelloTime(data, 2)
+	setSettingBridgeMaxAge(data, 20)
+	setSettingBridgeAgeingTime(data, 300)
+
+	initSettingSectionIpv4(data)
+	initSettingSectionIpv6(data)
+	return
+}
+
+// Get available keys
+func getSettingBridgeAvailableKeys(data connectionData) (keys []string) {
+	keys = appendAvailableKeys(data, keys, nm.NM_SETTING_BRIDGE_SETTING_NAME, nm.NM_SETTING_BRIDGE_STP)
+	// the following keys only work when spanning tree protocol enabled
+	if getSettingBridgeStp(data) {
+		keys = appendAvailableKeys(data, keys, nm.NM_SETTING_BRIDGE_SETTING_NAME, nm.NM_SETTING_BRIDGE_PRIORITY)
+		keys = appendAvailableKeys(data, keys, nm.NM_SETTING_BRIDGE_SETTING_NAME, nm.NM_SETTING_BRIDGE_FORWARD_DELAY)
+		keys = appendAvailableKeys(data, keys, nm.NM_SETTING_BRIDGE_SETTING_NAME, nm.NM_SETTING_BRIDGE_HELLO_TIME)
+		keys = appendAvailableKeys(data, keys, nm.NM_SETTING_BRIDGE_SETTING_NAME, nm.NM_SETTING_BRIDGE_MAX_AGE)
+	}
+	keys = appendAvailableKeys(data, keys, nm.NM_SETTING_BRIDGE_SETTING_NAME, nm.NM_SETTING_BRIDGE_AGEING_TIME)
+	return
+}
+
+// Get available values
+func getSettingBridgeAvailableValues(data connectionData, key string) (values []kvalue) {
+	return
+}
+
+// Check whether the values are correct
+func checkSettingBridgeValues(data connectionData) (errs sectionErrors) {
+	errs = make(map[string]string)
+	if getSettingBridgeStp(data) {
+		checkSettingBridgeKeyRange(data, errs, nm.NM_SETTING_BRIDGE_PRIORITY, getSettingBridgePriority(data), 0, 65535)
+		checkSettingBridgeKeyRange(data, errs, nm.NM_SETTING_BRIDGE_FORWARD_DELAY, getSettingBridgeForwardDelay(data), 2, 30)
+		checkSettingBridgeKeyRange(data, errs, nm.NM_SETTING_BRIDGE_HELLO_TIME, getSettingBridgeHelloTime(data), 1, 10)
+		checkSettingBridgeKeyRange(data, errs, nm.NM_SETTING_BRIDGE_MAX_AGE, getSettingBridgeMaxAge(data), 6, 40)
+	}
+	checkSettingBridgeKeyRange(data, errs, nm.NM_SETTING_BRIDGE_AGEING_TIME, getSettingBridgeAgeingTime(data), 0, 1000000)
+	return
+}
+func checkSettingBridgeKeyRange(data connectionData, errs sectionErrors, key string, value, min, max uint32) {
+	if !isSettingKeyExists(data, nm.NM_SETTING_BRIDGE_SETTING_NAME, key) {
+		// network-manager will use the default value
+		return
+	}
+	if value < min || value > max {
+		rememberError(errs, nm.NM_SETTING_BRIDGE_SETTING_NAME, key, nmKeyErrorInvalidValue)
+	}
+}
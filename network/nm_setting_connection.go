@@ -10,8 +10,11 @@
 package network
 
 import (
+	"fmt"
 	"os/user"
 	"pkg.deepin.io/dde/daemon/network/nm"
+	. "pkg.deepin.io/lib/gettext"
+	"strings"
 )
 
 // Get available keys
@@ -20,10 +23,19 @@ func getSettingConnectionAvailableKeys(data connectionData) (keys []string) {
 
 	// auto-connect only available for target connection types
 	switch getSettingConnectionType(data) {
-	case nm.NM_SETTING_WIRED_SETTING_NAME, nm.NM_SETTING_WIRELESS_SETTING_NAME, nm.NM_SETTING_PPPOE_SETTING_NAME, nm.NM_SETTING_GSM_SETTING_NAME, nm.NM_SETTING_CDMA_SETTING_NAME, nm.NM_SETTING_VPN_SETTING_NAME:
+	case nm.NM_SETTING_WIRED_SETTING_NAME, nm.NM_SETTING_WIRELESS_SETTING_NAME, nm.NM_SETTING_PPPOE_SETTING_NAME, nm.NM_SETTING_GSM_SETTING_NAME, nm.NM_SETTING_CDMA_SETTING_NAME, nm.NM_SETTING_VPN_SETTING_NAME, nm.NM_SETTING_WIREGUARD_SETTING_NAME, nm.NM_SETTING_BOND_SETTING_NAME, nm.NM_SETTING_BRIDGE_SETTING_NAME, nm.NM_SETTING_TEAM_SETTING_NAME, nm.NM_SETTING_VLAN_SETTING_NAME:
 		keys = appendAvailableKeys(data, keys, nm.NM_SETTING_CONNECTION_SETTING_NAME, nm.NM_SETTING_CONNECTION_AUTOCONNECT)
 	}
 
+	switch getSettingConnectionType(data) {
+	case nm.NM_SETTING_BOND_SETTING_NAME, nm.NM_SETTING_BRIDGE_SETTING_NAME, nm.NM_SETTING_TEAM_SETTING_NAME, nm.NM_SETTING_VLAN_SETTING_NAME:
+		// virtual devices are created with the interface name
+		keys = appendAvailableKeys(data, keys, nm.NM_SETTING_CONNECTION_SETTING_NAME, nm.NM_SETTING_CONNECTION_INTERFACE_NAME)
+	case nm.NM_SETTING_WIRED_SETTING_NAME:
+		// wired connection could be enslaved to bond, bridge and team
+		keys = appendAvailableKeys(data, keys, nm.NM_SETTING_CONNECTION_SETTING_NAME, nm.NM_SETTING_CONNECTION_MASTER)
+	}
+
 	return
 }
 
@@ -32,6 +44,53 @@ func getSettingConnectionAvailableValues(data connectionData, key string) (value
 	return
 }
 
+// getAvailableMasterConnections return all bond, bridge and team
+// connections that other connections could be enslaved to
+func getAvailableMasterConnections() (values []kvalue) {
+	values = []kvalue{kvalue{"", Tr("None")}}
+	manager.connectionsLock.Lock()
+	defer manager.connectionsLock.Unlock()
+	for _, connType := range []string{connectionBond, connectionBridge, connectionTeam} {
+		for _, conn := range manager.connections[connType] {
+			values = append(values, kvalue{conn.Uuid, conn.Id})
+		}
+	}
+	return
+}
+
+// getSlaveTypeOfMaster return the value of slave-type for the
+// connections that enslaved to the target master connection
+func getSlaveTypeOfMaster(masterConnType string) (slaveType string) {
+	switch masterConnType {
+	case connectionBond:
+		slaveType = nm.NM_SETTING_BOND_SETTING_NAME
+	case connectionBridge:
+		slaveType = nm.NM_SETTING_BRIDGE_SETTING_NAME
+	case connectionTeam:
+		slaveType = nm.NM_SETTING_TEAM_SETTING_NAME
+	}
+	return
+}
+
+func isSlaveConnection(data connectionData) bool {
+	return len(getSettingConnectionMaster(data)) > 0
+}
+
+// isInterfaceNameValid check the interface name in the same way with
+// the kernel, see dev_valid_name() in net/core/dev.c
+func isInterfaceNameValid(name string) bool {
+	if len(name) == 0 || len(name) > 15 {
+		return false
+	}
+	if name == "." || name == ".." {
+		return false
+	}
+	if strings.ContainsAny(name, "/: \t\n") {
+		return false
+	}
+	return true
+}
+
 // Check whether the values are correct
 func checkSettingConnectionValues(data connectionData) (errs sectionErrors) {
 	errs = make(map[string]string)
@@ -48,6 +107,15 @@ func checkSettingConnectionValues(data connectionData) (errs sectionErrors) {
 		}
 	}
 
+	// check interface name
+	switch getSettingConnectionType(data) {
+	case nm.NM_SETTING_BOND_SETTING_NAME, nm.NM_SETTING_BRIDGE_SETTING_NAME, nm.NM_SETTING_TEAM_SETTING_NAME:
+		ensureSettingConnectionInterfaceNameNoEmpty(data, errs)
+	}
+	if isSettingConnectionInterfaceNameExists(data) && !isInterfaceNameValid(getSettingConnectionInterfaceName(data)) {
+		rememberError(errs, nm.NM_SETTING_CONNECTION_SETTING_NAME, nm.NM_SETTING_CONNECTION_INTERFACE_NAME, nmKeyErrorInvalidValue)
+	}
+
 	return
 }
 
@@ -90,3 +158,36 @@ func logicSetSettingVkConnectionAutoconnect(data connectionData, value bool) (er
 	}
 	return
 }
+
+func getSettingVkConnectionMaster(data connectionData) (value string) {
+	return getSettingConnectionMaster(data)
+}
+func logicSetSettingVkConnectionMaster(data connectionData, value string) (err error) {
+	if len(value) == 0 {
+		// release the slave connection, and the ip settings are
+		// necessary again
+		removeSettingConnectionMaster(data)
+		removeSettingConnectionSlaveType(data)
+		if !isSettingExists(data, nm.NM_SETTING_IP4_CONFIG_SETTING_NAME) {
+			initSettingSectionIpv4(data)
+		}
+		if !isSettingExists(data, nm.NM_SETTING_IP6_CONFIG_SETTING_NAME) {
+			initSettingSectionIpv6(data)
+		}
+		return
+	}
+
+	slaveType := getSlaveTypeOfMaster(getCustomConnectionTypeForUuid(value))
+	if len(slaveType) == 0 {
+		err = fmt.Errorf(nmKeyErrorInvalidValue)
+		return
+	}
+	setSettingConnectionMaster(data, value)
+	setSettingConnectionSlaveType(data, slaveType)
+
+	// ip settings of slave connection will be ignored by
+	// network-manager, the master connection takes over them
+	removeSetting(data, nm.NM_SETTING_IP4_CONFIG_SETTING_NAME)
+	removeSetting(data, nm.NM_SETTING_IP6_CONFIG_SETTING_NAME)
+	return
+}
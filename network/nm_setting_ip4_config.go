@@ -54,11 +54,18 @@ func getSettingIP4ConfigAvailableKeys(data connectionData) (keys []string) {
 func getSettingIP4ConfigAvailableValues(data connectionData, key string) (values []kvalue) {
 	switch key {
 	case nm.NM_SETTING_IP_CONFIG_METHOD:
-		if getSettingConnectionType(data) == nm.NM_SETTING_VPN_SETTING_NAME {
+		switch getSettingConnectionType(data) {
+		case nm.NM_SETTING_VPN_SETTING_NAME:
 			values = []kvalue{
 				availableValuesIp4ConfigMethod[nm.NM_SETTING_IP4_CONFIG_METHOD_AUTO],
 			}
-		} else {
+		case nm.NM_SETTING_WIREGUARD_SETTING_NAME:
+			// wireguard tunnel address must be configured manually
+			values = []kvalue{
+				availableValuesIp4ConfigMethod[nm.NM_SETTING_IP4_CONFIG_METHOD_MANUAL],
+				availableValuesIp4ConfigMethod[nm.NM_SETTING_IP4_CONFIG_METHOD_DISABLED],
+			}
+		default:
 			values = []kvalue{
 				availableValuesIp4ConfigMethod[nm.NM_SETTING_IP4_CONFIG_METHOD_AUTO],
 				availableValuesIp4ConfigMethod[nm.NM_SETTING_IP4_CONFIG_METHOD_MANUAL],
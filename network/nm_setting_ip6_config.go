@@ -65,12 +65,18 @@ func getSettingIP6ConfigAvailableValues(data connectionData, key string) (values
 		// 	nm.NM_SETTING_IP6_CONFIG_METHOD_MANUAL,
 		// 	// nm.NM_SETTING_IP6_CONFIG_METHOD_SHARED,// ignore
 		// }
-		if getSettingConnectionType(data) == nm.NM_SETTING_VPN_SETTING_NAME {
+		switch getSettingConnectionType(data) {
+		case nm.NM_SETTING_VPN_SETTING_NAME:
 			values = []kvalue{
 				availableValuesIp6ConfigMethod[nm.NM_SETTING_IP6_CONFIG_METHOD_AUTO],
 				availableValuesIp6ConfigMethod[nm.NM_SETTING_IP6_CONFIG_METHOD_IGNORE],
 			}
-		} else {
+		case nm.NM_SETTING_WIREGUARD_SETTING_NAME:
+			values = []kvalue{
+				availableValuesIp6ConfigMethod[nm.NM_SETTING_IP6_CONFIG_METHOD_MANUAL],
+				availableValuesIp6ConfigMethod[nm.NM_SETTING_IP6_CONFIG_METHOD_IGNORE],
+			}
+		default:
 			values = []kvalue{
 				availableValuesIp6ConfigMethod[nm.NM_SETTING_IP6_CONFIG_METHOD_AUTO],
 				availableValuesIp6ConfigMethod[nm.NM_SETTING_IP6_CONFIG_METHOD_MANUAL],
@@ -0,0 +1,132 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"encoding/json"
+	"pkg.deepin.io/dde/daemon/network/nm"
+)
+
+// team runners, see teamd.conf(5) for more details
+const (
+	teamRunnerBroadcast    = "broadcast"
+	teamRunnerRoundrobin   = "roundrobin"
+	teamRunnerActivebackup = "activebackup"
+	teamRunnerLoadbalance  = "loadbalance"
+	teamRunnerLacp         = "lacp"
+)
+
+var teamRunners = []string{
+	teamRunnerBroadcast,
+	teamRunnerRoundrobin,
+	teamRunnerActivebackup,
+	teamRunnerLoadbalance,
+	teamRunnerLacp,
+}
+
+func newTeamConnectionData(id, uuid string) (data connectionData) {
+	data = make(connectionData)
+
+	addSetting(data, nm.NM_SETTING_CONNECTION_SETTING_NAME)
+	setSettingConnectionId(data, id)
+	setSettingConnectionUuid(data, uuid)
+	setSettingConnectionType(data, nm.NM_SETTING_TEAM_SETTING_NAME)
+	setSettingConnectionInterfaceName(data, genVirtualInterfaceName("team"))
+
+	addSetting(data, nm.NM_SETTING_TEAM_SETTING_NAME)
+	logicSetSettingVkTeamRunner(data, teamRunnerRoundrobin)
+
+	initSettingSectionIpv4(data)
+	initSettingSectionIpv6(data)
+	return
+}
+
+// Get available keys
+func getSettingTeamAvailableKeys(data connectionData) (keys []string) {
+	keys = appendAvailableKeys(data, keys, nm.NM_SETTING_TEAM_SETTING_NAME, nm.NM_SETTING_TEAM_CONFIG)
+	return
+}
+
+// Get available values
+func getSettingTeamAvailableValues(data connectionData, key string) (values []kvalue) {
+	return
+}
+
+// Check whether the values are correct
+func checkSettingTeamValues(data connectionData) (errs sectionErrors) {
+	errs = make(map[string]string)
+	if !isSettingTeamConfigExists(data) {
+		// teamd will use the default runner
+		return
+	}
+	config, err := unmarshalTeamConfig(getSettingTeamConfig(data))
+	if err != nil {
+		rememberError(errs, nm.NM_SETTING_TEAM_SETTING_NAME, nm.NM_SETTING_TEAM_CONFIG, nmKeyErrorInvalidValue)
+		return
+	}
+	if runner := getTeamConfigRunner(config); len(runner) > 0 && !isStringInArray(runner, teamRunners) {
+		rememberError(errs, nm.NM_SETTING_TEAM_SETTING_NAME, nm.NM_SETTING_TEAM_CONFIG, nmKeyErrorInvalidValue)
+	}
+	return
+}
+
+// The team config is a JSON string which is passed to teamd
+// directly, only the runner name is edited here and other fields
+// will be kept as they are, e.g. {"runner": {"name": "lacp"}}
+func unmarshalTeamConfig(configJSON string) (config map[string]interface{}, err error) {
+	config = make(map[string]interface{})
+	if len(configJSON) == 0 {
+		return
+	}
+	err = json.Unmarshal([]byte(configJSON), &config)
+	return
+}
+func getTeamConfigRunner(config map[string]interface{}) (runner string) {
+	if runnerConfig, ok := config["runner"].(map[string]interface{}); ok {
+		runner, _ = runnerConfig["name"].(string)
+	}
+	return
+}
+
+// Virtual key getter
+func getSettingVkTeamRunner(data connectionData) (value string) {
+	config, err := unmarshalTeamConfig(getSettingTeamConfig(data))
+	if err != nil {
+		logger.Warning("invalid team config:", err)
+	}
+	value = getTeamConfigRunner(config)
+	if len(value) == 0 {
+		// "roundrobin" is the default runner for teamd
+		value = teamRunnerRoundrobin
+	}
+	return
+}
+
+// Virtual key logic setter
+func logicSetSettingVkTeamRunner(data connectionData, value string) (err error) {
+	config, err := unmarshalTeamConfig(getSettingTeamConfig(data))
+	if err != nil {
+		logger.Warning("invalid team config, override it:", err)
+		config = make(map[string]interface{})
+		err = nil
+	}
+	runnerConfig, ok := config["runner"].(map[string]interface{})
+	if !ok {
+		runnerConfig = make(map[string]interface{})
+		config["runner"] = runnerConfig
+	}
+	runnerConfig["name"] = value
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return
+	}
+	setSettingTeamConfig(data, string(configJSON))
+	return
+}
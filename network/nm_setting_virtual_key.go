@@ -166,6 +166,35 @@ func generalGetSettingVkeyAvailableValues(data connectionData, section, key stri
 				kvalue{connectionVpnVpnc, Tr("VPNC")},
 			}
 		}
+	case nm.NM_SETTING_CONNECTION_SETTING_NAME:
+		switch key {
+		case nm.NM_SETTING_VK_CONNECTION_MASTER:
+			values = getAvailableMasterConnections()
+		}
+	case nm.NM_SETTING_BOND_SETTING_NAME:
+		switch key {
+		case nm.NM_SETTING_VK_BOND_MODE:
+			values = []kvalue{
+				kvalue{bondModeBalanceRr, Tr("Round-robin")},
+				kvalue{bondModeActiveBackup, Tr("Active backup")},
+				kvalue{bondModeBalanceXor, Tr("XOR")},
+				kvalue{bondModeBroadcast, Tr("Broadcast")},
+				kvalue{bondMode8023ad, Tr("802.3ad")},
+				kvalue{bondModeBalanceTlb, Tr("Adaptive transmit load balancing")},
+				kvalue{bondModeBalanceAlb, Tr("Adaptive load balancing")},
+			}
+		}
+	case nm.NM_SETTING_TEAM_SETTING_NAME:
+		switch key {
+		case nm.NM_SETTING_VK_TEAM_RUNNER:
+			values = []kvalue{
+				kvalue{teamRunnerBroadcast, Tr("Broadcast")},
+				kvalue{teamRunnerRoundrobin, Tr("Round-robin")},
+				kvalue{teamRunnerActivebackup, Tr("Active backup")},
+				kvalue{teamRunnerLoadbalance, Tr("Load balance")},
+				kvalue{teamRunnerLacp, Tr("LACP")},
+			}
+		}
 	case nm.NM_SETTING_802_1X_SETTING_NAME:
 		switch key {
 		case nm.NM_SETTING_VK_802_1X_EAP:
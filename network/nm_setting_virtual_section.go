@@ -100,8 +100,10 @@ func doGetRelatedVsections(data connectionData, keepAll bool) (vsections []strin
 			nm.NM_SETTING_VS_GENERAL,
 			nm.NM_SETTING_VS_ETHERNET,
 			nm.NM_SETTING_VS_SECURITY,
-			nm.NM_SETTING_VS_IPV4,
-			nm.NM_SETTING_VS_IPV6,
+		}
+		// ip settings are managed by the master connection for slaves
+		if keepAll || !isSlaveConnection(data) {
+			vsections = append(vsections, nm.NM_SETTING_VS_IPV4, nm.NM_SETTING_VS_IPV6)
 		}
 	case connectionWireless:
 		vsections = []string{
@@ -198,6 +200,41 @@ func doGetRelatedVsections(data connectionData, keepAll bool) (vsections []strin
 			nm.NM_SETTING_VS_PPP,
 			nm.NM_SETTING_VS_IPV4,
 		}
+	case connectionWireguard:
+		vsections = []string{
+			nm.NM_SETTING_VS_GENERAL,
+			nm.NM_SETTING_VS_WIREGUARD,
+			nm.NM_SETTING_VS_IPV4,
+			nm.NM_SETTING_VS_IPV6,
+		}
+	case connectionBond:
+		vsections = []string{
+			nm.NM_SETTING_VS_GENERAL,
+			nm.NM_SETTING_VS_BOND,
+			nm.NM_SETTING_VS_IPV4,
+			nm.NM_SETTING_VS_IPV6,
+		}
+	case connectionBridge:
+		vsections = []string{
+			nm.NM_SETTING_VS_GENERAL,
+			nm.NM_SETTING_VS_BRIDGE,
+			nm.NM_SETTING_VS_IPV4,
+			nm.NM_SETTING_VS_IPV6,
+		}
+	case connectionTeam:
+		vsections = []string{
+			nm.NM_SETTING_VS_GENERAL,
+			nm.NM_SETTING_VS_TEAM,
+			nm.NM_SETTING_VS_IPV4,
+			nm.NM_SETTING_VS_IPV6,
+		}
+	case connectionVlan:
+		vsections = []string{
+			nm.NM_SETTING_VS_GENERAL,
+			nm.NM_SETTING_VS_VLAN,
+			nm.NM_SETTING_VS_IPV4,
+			nm.NM_SETTING_VS_IPV6,
+		}
 	}
 	return
 }
@@ -261,6 +298,16 @@ func doGetRelatedSectionsOfVsection(data connectionData, vsection string, keepAl
 		sections = []string{nm.NM_SETTING_PPPOE_SETTING_NAME}
 	case nm.NM_SETTING_VS_PPP:
 		sections = []string{nm.NM_SETTING_PPP_SETTING_NAME}
+	case nm.NM_SETTING_VS_WIREGUARD:
+		sections = []string{nm.NM_SETTING_WIREGUARD_SETTING_NAME}
+	case nm.NM_SETTING_VS_BOND:
+		sections = []string{nm.NM_SETTING_BOND_SETTING_NAME}
+	case nm.NM_SETTING_VS_BRIDGE:
+		sections = []string{nm.NM_SETTING_BRIDGE_SETTING_NAME}
+	case nm.NM_SETTING_VS_TEAM:
+		sections = []string{nm.NM_SETTING_TEAM_SETTING_NAME}
+	case nm.NM_SETTING_VS_VLAN:
+		sections = []string{nm.NM_SETTING_VLAN_SETTING_NAME}
 	case nm.NM_SETTING_VS_VPN:
 		switch connectionType {
 		case connectionVpnL2tp:
@@ -448,6 +495,31 @@ func isVsectionExpandedDefault(data connectionData, vsection string) (expanded b
 		case nm.NM_SETTING_VS_VPN:
 			expanded = true
 		}
+	case connectionWireguard:
+		switch vsection {
+		case nm.NM_SETTING_VS_WIREGUARD:
+			expanded = true
+		}
+	case connectionBond:
+		switch vsection {
+		case nm.NM_SETTING_VS_BOND:
+			expanded = true
+		}
+	case connectionBridge:
+		switch vsection {
+		case nm.NM_SETTING_VS_BRIDGE:
+			expanded = true
+		}
+	case connectionTeam:
+		switch vsection {
+		case nm.NM_SETTING_VS_TEAM:
+			expanded = true
+		}
+	case connectionVlan:
+		switch vsection {
+		case nm.NM_SETTING_VS_VLAN:
+			expanded = true
+		}
 	default:
 		logger.Error("unknown custom connection type", connectionType)
 	}
@@ -0,0 +1,66 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"pkg.deepin.io/dde/daemon/network/nm"
+)
+
+// 0 and 4095 are reserved by IEEE 802.1Q, but 0 is still accepted by
+// the kernel for priority tagged frames
+const vlanMaxId = 4094
+
+func newVlanConnectionData(id, uuid string) (data connectionData) {
+	data = make(connectionData)
+
+	addSetting(data, nm.NM_SETTING_CONNECTION_SETTING_NAME)
+	setSettingConnectionId(data, id)
+	setSettingConnectionUuid(data, uuid)
+	setSettingConnectionType(data, nm.NM_SETTING_VLAN_SETTING_NAME)
+
+	// the interface name will be generated by network-manager through
+	// the parent interface and vlan id if not specified
+	addSetting(data, nm.NM_SETTING_VLAN_SETTING_NAME)
+	setSettingVlanId(data, 1)
+
+	initSettingSectionIpv4(data)
+	initSettingSectionIpv6(data)
+	return
+}
+
+// Get available keys
+func getSettingVlanAvailableKeys(data connectionData) (keys []string) {
+	keys = appendAvailableKeys(data, keys, nm.NM_SETTING_VLAN_SETTING_NAME, nm.NM_SETTING_VLAN_PARENT)
+	keys = appendAvailableKeys(data, keys, nm.NM_SETTING_VLAN_SETTING_NAME, nm.NM_SETTING_VLAN_ID)
+	return
+}
+
+// Get available values
+func getSettingVlanAvailableValues(data connectionData, key string) (values []kvalue) {
+	switch key {
+	case nm.NM_SETTING_VLAN_PARENT:
+		// get all wired devices interface name
+		for iface, hwAddr := range nmGeneralGetAllDeviceHwAddr(nm.NM_DEVICE_TYPE_ETHERNET) {
+			values = append(values, kvalue{iface, iface + " (" + hwAddr + ")"})
+		}
+		sortKvalues(values)
+	}
+	return
+}
+
+// Check whether the values are correct
+func checkSettingVlanValues(data connectionData) (errs sectionErrors) {
+	errs = make(map[string]string)
+	ensureSettingVlanParentNoEmpty(data, errs)
+	if getSettingVlanId(data) > vlanMaxId {
+		rememberError(errs, nm.NM_SETTING_VLAN_SETTING_NAME, nm.NM_SETTING_VLAN_ID, nmKeyErrorInvalidValue)
+	}
+	return
+}
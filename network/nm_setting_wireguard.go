@@ -0,0 +1,239 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"pkg.deepin.io/dde/daemon/network/nm"
+	"pkg.deepin.io/lib/dbus"
+	"strconv"
+	"strings"
+)
+
+// length of the decoded curve25519 keys used by wireguard
+const wireguardKeyLen = 32
+
+func newWireguardConnectionData(id, uuid string) (data connectionData) {
+	data = make(connectionData)
+
+	addSetting(data, nm.NM_SETTING_CONNECTION_SETTING_NAME)
+	setSettingConnectionId(data, id)
+	setSettingConnectionUuid(data, uuid)
+	setSettingConnectionType(data, nm.NM_SETTING_WIREGUARD_SETTING_NAME)
+	setSettingConnectionAutoconnect(data, false)
+
+	addSetting(data, nm.NM_SETTING_WIREGUARD_SETTING_NAME)
+	setSettingWireGuardPrivateKeyFlags(data, nm.NM_SETTING_SECRET_FLAG_NONE)
+
+	addSetting(data, nm.NM_SETTING_IP4_CONFIG_SETTING_NAME)
+	setSettingIP4ConfigMethod(data, nm.NM_SETTING_IP4_CONFIG_METHOD_MANUAL)
+	addSetting(data, nm.NM_SETTING_IP6_CONFIG_SETTING_NAME)
+	setSettingIP6ConfigMethod(data, nm.NM_SETTING_IP6_CONFIG_METHOD_IGNORE)
+	return
+}
+
+// Get available keys
+func getSettingWireGuardAvailableKeys(data connectionData) (keys []string) {
+	if isSettingRequireSecret(getSettingWireGuardPrivateKeyFlags(data)) {
+		keys = appendAvailableKeys(data, keys, nm.NM_SETTING_WIREGUARD_SETTING_NAME, nm.NM_SETTING_WIREGUARD_PRIVATE_KEY)
+	}
+	keys = appendAvailableKeys(data, keys, nm.NM_SETTING_WIREGUARD_SETTING_NAME, nm.NM_SETTING_WIREGUARD_LISTEN_PORT)
+	keys = appendAvailableKeys(data, keys, nm.NM_SETTING_WIREGUARD_SETTING_NAME, nm.NM_SETTING_WIREGUARD_PEERS)
+	return
+}
+
+// Get available values
+func getSettingWireGuardAvailableValues(data connectionData, key string) (values []kvalue) {
+	return
+}
+
+// Check whether the values are correct
+func checkSettingWireGuardValues(data connectionData) (errs sectionErrors) {
+	errs = make(map[string]string)
+	if isSettingRequireSecret(getSettingWireGuardPrivateKeyFlags(data)) {
+		ensureSettingWireGuardPrivateKeyNoEmpty(data, errs)
+	}
+	ensureSettingWireGuardPeersNoEmpty(data, errs)
+	for _, key := range []string{
+		nm.NM_SETTING_WIREGUARD_PRIVATE_KEY,
+		nm.NM_SETTING_WIREGUARD_LISTEN_PORT,
+		nm.NM_SETTING_VK_WIREGUARD_PEER_PUBLIC_KEY,
+		nm.NM_SETTING_VK_WIREGUARD_PEER_ENDPOINT,
+		nm.NM_SETTING_VK_WIREGUARD_PEER_ALLOWED_IPS,
+	} {
+		if _, ok := errs[key]; ok {
+			continue
+		}
+		if err := checkSettingWireGuardKeyValue(data, key); err != nil {
+			rememberError(errs, nm.NM_SETTING_WIREGUARD_SETTING_NAME, key, err.Error())
+		}
+	}
+	return
+}
+
+// checkSettingWireGuardKeyValue check the value of target key, it is
+// called each time a key of wireguard section is set, empty values are
+// ignored here and will be reported by checkSettingWireGuardValues()
+func checkSettingWireGuardKeyValue(data connectionData, key string) (err error) {
+	switch key {
+	case nm.NM_SETTING_WIREGUARD_PRIVATE_KEY:
+		value := getSettingWireGuardPrivateKey(data)
+		if len(value) > 0 && !isWireguardKeyValid(value) {
+			err = fmt.Errorf(nmKeyErrorInvalidValue)
+		}
+	case nm.NM_SETTING_WIREGUARD_LISTEN_PORT:
+		if getSettingWireGuardListenPort(data) > 65535 {
+			err = fmt.Errorf(nmKeyErrorInvalidValue)
+		}
+	case nm.NM_SETTING_VK_WIREGUARD_PEER_PUBLIC_KEY:
+		value := getSettingVkWireguardPeerPublicKey(data)
+		if len(value) > 0 && !isWireguardKeyValid(value) {
+			err = fmt.Errorf(nmKeyErrorInvalidValue)
+		} else if len(value) == 0 && !isSettingWireGuardPeersEmpty(data) {
+			err = fmt.Errorf(nmKeyErrorEmptyValue)
+		}
+	case nm.NM_SETTING_VK_WIREGUARD_PEER_ENDPOINT:
+		value := getSettingVkWireguardPeerEndpoint(data)
+		if len(value) > 0 && !isWireguardEndpointValid(value) {
+			err = fmt.Errorf(nmKeyErrorInvalidValue)
+		}
+	case nm.NM_SETTING_VK_WIREGUARD_PEER_ALLOWED_IPS:
+		value := getSettingVkWireguardPeerAllowedIps(data)
+		if len(value) > 0 && !isWireguardAllowedIpsValid(value) {
+			err = fmt.Errorf(nmKeyErrorInvalidValue)
+		}
+	}
+	return
+}
+
+// isWireguardKeyValid check if the key is a base64 encoded 32 bytes
+// key, which is the format used by the wg(8) tool
+func isWireguardKeyValid(key string) bool {
+	buf, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return false
+	}
+	return len(buf) == wireguardKeyLen
+}
+
+// isWireguardEndpointValid check if the endpoint is in the format of
+// "host:port", and ipv6 address should be wrapped with brackets
+func isWireguardEndpointValid(endpoint string) bool {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil || len(host) == 0 {
+		return false
+	}
+	portNum, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || portNum == 0 {
+		return false
+	}
+	return true
+}
+
+// isWireguardAllowedIpsValid check if all the comma separated items are
+// ip addresses or subnets in CIDR notation
+func isWireguardAllowedIpsValid(allowedIps string) bool {
+	for _, ip := range splitWireguardAllowedIps(allowedIps) {
+		if _, _, err := net.ParseCIDR(ip); err == nil {
+			continue
+		}
+		if net.ParseIP(ip) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func splitWireguardAllowedIps(allowedIps string) (ips []string) {
+	for _, ip := range strings.Split(allowedIps, ",") {
+		ip = strings.TrimSpace(ip)
+		if len(ip) > 0 {
+			ips = append(ips, ip)
+		}
+	}
+	return
+}
+
+// Virtual key utility, only the first peer could be edited through
+// virtual keys, and others will be kept as they are
+func isSettingWireGuardPeersEmpty(data connectionData) bool {
+	return len(getSettingWireGuardPeers(data)) == 0
+}
+func getOrNewSettingWireGuardPeers(data connectionData) (peers arrayDictStringVariant) {
+	if !isSettingWireGuardPeersEmpty(data) {
+		peers = getSettingWireGuardPeers(data)
+	} else {
+		peers = make(arrayDictStringVariant, 1)
+		peers[0] = make(map[string]dbus.Variant)
+	}
+	return
+}
+func getSettingWireGuardFirstPeerAttr(data connectionData, attr string) (value interface{}) {
+	if isSettingWireGuardPeersEmpty(data) {
+		return
+	}
+	variant, ok := getSettingWireGuardPeers(data)[0][attr]
+	if !ok {
+		return
+	}
+	return variant.Value()
+}
+func setSettingWireGuardFirstPeerAttr(data connectionData, attr string, value interface{}, empty bool) {
+	peers := getOrNewSettingWireGuardPeers(data)
+	if empty {
+		delete(peers[0], attr)
+	} else {
+		peers[0][attr] = dbus.MakeVariant(value)
+	}
+	if len(peers) == 1 && len(peers[0]) == 0 {
+		removeSettingWireGuardPeers(data)
+	} else {
+		setSettingWireGuardPeers(data, peers)
+	}
+}
+
+// Virtual key getter
+func getSettingVkWireguardPeerPublicKey(data connectionData) (value string) {
+	return interfaceToString(getSettingWireGuardFirstPeerAttr(data, nm.NM_WIREGUARD_PEER_ATTR_PUBLIC_KEY))
+}
+func getSettingVkWireguardPeerEndpoint(data connectionData) (value string) {
+	return interfaceToString(getSettingWireGuardFirstPeerAttr(data, nm.NM_WIREGUARD_PEER_ATTR_ENDPOINT))
+}
+func getSettingVkWireguardPeerAllowedIps(data connectionData) (value string) {
+	ips := interfaceToArrayString(getSettingWireGuardFirstPeerAttr(data, nm.NM_WIREGUARD_PEER_ATTR_ALLOWED_IPS))
+	return strings.Join(ips, ",")
+}
+func getSettingVkWireguardPeerPersistentKeepalive(data connectionData) (value uint32) {
+	return interfaceToUint32(getSettingWireGuardFirstPeerAttr(data, nm.NM_WIREGUARD_PEER_ATTR_PERSISTENT_KEEPALIVE))
+}
+
+// Virtual key logic setter, the values will be checked in
+// checkSettingWireGuardKeyValue()
+func logicSetSettingVkWireguardPeerPublicKey(data connectionData, value string) (err error) {
+	value = strings.TrimSpace(value)
+	setSettingWireGuardFirstPeerAttr(data, nm.NM_WIREGUARD_PEER_ATTR_PUBLIC_KEY, value, len(value) == 0)
+	return
+}
+func logicSetSettingVkWireguardPeerEndpoint(data connectionData, value string) (err error) {
+	value = strings.TrimSpace(value)
+	setSettingWireGuardFirstPeerAttr(data, nm.NM_WIREGUARD_PEER_ATTR_ENDPOINT, value, len(value) == 0)
+	return
+}
+func logicSetSettingVkWireguardPeerAllowedIps(data connectionData, value string) (err error) {
+	ips := splitWireguardAllowedIps(value)
+	setSettingWireGuardFirstPeerAttr(data, nm.NM_WIREGUARD_PEER_ATTR_ALLOWED_IPS, ips, len(ips) == 0)
+	return
+}
+func logicSetSettingVkWireguardPeerPersistentKeepalive(data connectionData, value uint32) (err error) {
+	setSettingWireGuardFirstPeerAttr(data, nm.NM_WIREGUARD_PEER_ATTR_PERSISTENT_KEEPALIVE, value, value == 0)
+	return
+}
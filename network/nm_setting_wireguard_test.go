@@ -0,0 +1,77 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	C "launchpad.net/gocheck"
+	"pkg.deepin.io/dde/daemon/network/nm"
+)
+
+const testWireguardKey = "yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk="
+
+func (*testWrapper) TestWireguardValidators(c *C.C) {
+	c.Check(isWireguardKeyValid(testWireguardKey), C.Equals, true)
+	c.Check(isWireguardKeyValid("yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3f"), C.Equals, false)
+	c.Check(isWireguardKeyValid("not a key"), C.Equals, false)
+
+	c.Check(isWireguardEndpointValid("vpn.example.com:51820"), C.Equals, true)
+	c.Check(isWireguardEndpointValid("[fd00::1]:51820"), C.Equals, true)
+	c.Check(isWireguardEndpointValid("192.168.1.1"), C.Equals, false)
+	c.Check(isWireguardEndpointValid("192.168.1.1:0"), C.Equals, false)
+	c.Check(isWireguardEndpointValid("192.168.1.1:70000"), C.Equals, false)
+
+	c.Check(isWireguardAllowedIpsValid("0.0.0.0/0, ::/0"), C.Equals, true)
+	c.Check(isWireguardAllowedIpsValid("10.0.0.2"), C.Equals, true)
+	c.Check(isWireguardAllowedIpsValid("10.0.0.0/33"), C.Equals, false)
+}
+
+func (*testWrapper) TestWireguardPeersWrapper(c *C.C) {
+	wrapData := wireguardPeersWrapper{
+		{PublicKey: testWireguardKey, Endpoint: "vpn.example.com:51820", AllowedIps: []string{"0.0.0.0/0"}, PersistentKeepalive: 25},
+		{PublicKey: testWireguardKey},
+	}
+	data := unwrapWireguardPeers(wrapData)
+	c.Check(len(data), C.Equals, 2)
+	c.Check(len(data[1]), C.Equals, 1)
+	c.Check(wrapWireguardPeers(data), C.DeepEquals, wrapData)
+
+	valueJSON, err := keyValueToJSON(data, ktypeWrapperWireguardPeers)
+	c.Check(err, C.IsNil)
+	value, err := jsonToKeyValue(valueJSON, ktypeWrapperWireguardPeers)
+	c.Check(err, C.IsNil)
+	c.Check(wrapWireguardPeers(interfaceToArrayDictStringVariant(value)), C.DeepEquals, wrapData)
+}
+
+func (*testWrapper) TestWireguardPeerVirtualKeys(c *C.C) {
+	data := make(connectionData)
+	addSetting(data, nm.NM_SETTING_WIREGUARD_SETTING_NAME)
+
+	logicSetSettingVkWireguardPeerEndpoint(data, "vpn.example.com:51820")
+	c.Check(checkSettingWireGuardKeyValue(data, nm.NM_SETTING_VK_WIREGUARD_PEER_PUBLIC_KEY), C.NotNil)
+
+	logicSetSettingVkWireguardPeerPublicKey(data, testWireguardKey)
+	logicSetSettingVkWireguardPeerAllowedIps(data, "10.0.0.0/24, 192.168.0.0/16")
+	logicSetSettingVkWireguardPeerPersistentKeepalive(data, 25)
+	c.Check(getSettingVkWireguardPeerPublicKey(data), C.Equals, testWireguardKey)
+	c.Check(getSettingVkWireguardPeerEndpoint(data), C.Equals, "vpn.example.com:51820")
+	c.Check(getSettingVkWireguardPeerAllowedIps(data), C.Equals, "10.0.0.0/24,192.168.0.0/16")
+	c.Check(getSettingVkWireguardPeerPersistentKeepalive(data), C.Equals, uint32(25))
+	c.Check(checkSettingWireGuardKeyValue(data, nm.NM_SETTING_VK_WIREGUARD_PEER_PUBLIC_KEY), C.IsNil)
+
+	logicSetSettingVkWireguardPeerAllowedIps(data, "10.0.0.0/24, bad")
+	c.Check(checkSettingWireGuardKeyValue(data, nm.NM_SETTING_VK_WIREGUARD_PEER_ALLOWED_IPS), C.NotNil)
+
+	// clear all attributes will remove the peer
+	logicSetSettingVkWireguardPeerPublicKey(data, "")
+	logicSetSettingVkWireguardPeerEndpoint(data, "")
+	logicSetSettingVkWireguardPeerAllowedIps(data, "")
+	logicSetSettingVkWireguardPeerPersistentKeepalive(data, 0)
+	c.Check(isSettingWireGuardPeersExists(data), C.Equals, false)
+}
@@ -0,0 +1,372 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"fmt"
+	"pkg.deepin.io/lib/utils"
+	"reflect"
+)
+
+// proxyProfile is a set of proxy settings which could be bound to
+// network locations, it will be applied to the system proxy
+// gsettings automatically when the primary connection changed.
+type proxyProfile struct {
+	Id          string
+	Name        string
+	Method      string // "none", "manual" or "auto"
+	AutoUrl     string // PAC file URL for "auto" method
+	IgnoreHosts []string
+
+	// proxy servers for "manual" method
+	Http  proxyServer
+	Https proxyServer
+	Ftp   proxyServer
+	Socks proxyServer
+
+	// the profile matches if the primary connection is one of the
+	// connection uuids, or is a wireless connection with one of the
+	// ssids
+	Uuids []string
+	Ssids []string
+}
+
+type proxyServer struct {
+	Host string
+	Port int32
+}
+
+func checkProxyProfile(p *proxyProfile) (err error) {
+	if len(p.Name) == 0 {
+		err = fmt.Errorf("proxy profile name is empty")
+		return
+	}
+	if err = checkProxyMethod(p.Method); err != nil {
+		return
+	}
+	if p.Method == proxyModeAuto && len(p.AutoUrl) == 0 {
+		err = fmt.Errorf("autoconfig-url of proxy profile %s is empty", p.Name)
+		return
+	}
+	for _, server := range []proxyServer{p.Http, p.Https, p.Ftp, p.Socks} {
+		if server.Port < 0 || server.Port > 65535 {
+			err = fmt.Errorf("invalid proxy port %d in profile %s", server.Port, p.Name)
+			return
+		}
+	}
+	return
+}
+
+// matchProxyProfile find the profile for the network location, the
+// profile bound to connection uuid takes precedence over the one
+// bound to ssid, and profiles added earlier take precedence.
+func matchProxyProfile(profiles []*proxyProfile, uuid, ssid string) *proxyProfile {
+	if len(uuid) > 0 {
+		for _, p := range profiles {
+			if isStringInArray(uuid, p.Uuids) {
+				return p
+			}
+		}
+	}
+	if len(ssid) > 0 {
+		for _, p := range profiles {
+			if isStringInArray(ssid, p.Ssids) {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+func findProxyProfile(profiles []*proxyProfile, id string) (index int, p *proxyProfile) {
+	for i, profile := range profiles {
+		if profile.Id == id {
+			return i, profile
+		}
+	}
+	return -1, nil
+}
+
+// proxyProfileNone is applied when profiles exist but none of them
+// matches and no default profile is set, so the proxy of the previous
+// network location is not kept.
+var proxyProfileNone = &proxyProfile{Method: proxyModeNone}
+
+// selectProxyProfile returns the profile matching the network location,
+// or the default profile, or proxyProfileNone. It returns nil if there is
+// no profile at all, then the system proxy is managed by the user.
+func selectProxyProfile(profiles []*proxyProfile, defaultId, uuid, ssid string) *proxyProfile {
+	p := matchProxyProfile(profiles, uuid, ssid)
+	if p == nil {
+		_, p = findProxyProfile(profiles, defaultId)
+	}
+	if p == nil && len(profiles) > 0 {
+		p = proxyProfileNone
+	}
+	return p
+}
+
+// isSameProxy returns true if applying b over a changes nothing the
+// applications use, the settings not used by the method are ignored.
+func isSameProxy(a, b *proxyProfile) bool {
+	if a.Method != b.Method {
+		return false
+	}
+	switch a.Method {
+	case proxyModeNone:
+		return true
+	case proxyModeAuto:
+		if a.AutoUrl != b.AutoUrl {
+			return false
+		}
+	case proxyModeManual:
+		if a.Http != b.Http || a.Https != b.Https || a.Ftp != b.Ftp || a.Socks != b.Socks {
+			return false
+		}
+	}
+	if len(a.IgnoreHosts) == 0 && len(b.IgnoreHosts) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.IgnoreHosts, b.IgnoreHosts)
+}
+
+// getSystemProxy read the system proxy gsettings as a profile.
+func getSystemProxy() (p *proxyProfile, err error) {
+	p = &proxyProfile{
+		Method:      proxySettings.GetString(gkeyProxyMode),
+		AutoUrl:     proxySettings.GetString(gkeyProxyAuto),
+		IgnoreHosts: proxySettings.GetStrv(gkeyProxyIgnoreHosts),
+	}
+	servers := map[string]*proxyServer{
+		proxyTypeHttp:  &p.Http,
+		proxyTypeHttps: &p.Https,
+		proxyTypeFtp:   &p.Ftp,
+		proxyTypeSocks: &p.Socks,
+	}
+	for proxyType, server := range servers {
+		childSettings, err := getProxyChildSettings(proxyType)
+		if err != nil {
+			return nil, err
+		}
+		server.Host = childSettings.GetString(gkeyProxyHost)
+		server.Port = childSettings.GetInt(gkeyProxyPort)
+	}
+	return
+}
+
+// applyProxyProfile write the profile to system proxy gsettings.
+func applyProxyProfile(p *proxyProfile) (err error) {
+	ignoreHosts := p.IgnoreHosts
+	if ignoreHosts == nil {
+		ignoreHosts = []string{}
+	}
+	ok := proxySettings.SetString(gkeyProxyAuto, p.AutoUrl)
+	ok = ok && proxySettings.SetStrv(gkeyProxyIgnoreHosts, ignoreHosts)
+	servers := map[string]proxyServer{
+		proxyTypeHttp:  p.Http,
+		proxyTypeHttps: p.Https,
+		proxyTypeFtp:   p.Ftp,
+		proxyTypeSocks: p.Socks,
+	}
+	for proxyType, server := range servers {
+		childSettings, err := getProxyChildSettings(proxyType)
+		if err != nil {
+			return err
+		}
+		ok = ok && childSettings.SetString(gkeyProxyHost, server.Host)
+		ok = ok && childSettings.SetInt(gkeyProxyPort, server.Port)
+	}
+	// set the method at last, so the applications watching it will
+	// get the complete settings
+	ok = ok && proxySettings.SetString(gkeyProxyMode, p.Method)
+	if !ok {
+		err = fmt.Errorf("apply proxy profile %s through gsettings failed", p.Name)
+		logger.Error(err)
+	}
+	return
+}
+
+// switchProxyProfile apply the profile if it changes the system proxy,
+// and notify the user only in that case. It should be called with
+// proxyProfilesLock held.
+func (m *Manager) switchProxyProfile(p *proxyProfile) (err error) {
+	current, err := getSystemProxy()
+	if err != nil {
+		return
+	}
+	changed := !isSameProxy(current, p)
+	if changed {
+		if err = applyProxyProfile(p); err != nil {
+			return
+		}
+	}
+	if m.ActiveProxyProfile != p.Id {
+		m.setPropActiveProxyProfile(p.Id)
+	}
+	if !changed {
+		return
+	}
+	if p == proxyProfileNone {
+		notifyProxyDisabled()
+	} else {
+		notifyProxyProfileApplied(p.Name, p.Method)
+	}
+	return
+}
+
+// updateProxyProfile apply the profile matching current primary
+// connection, or the default profile if nothing matches, or disable
+// the proxy if neither exists. If no profile is configured at all, the
+// system proxy will be kept as it is, unless it was set by a removed
+// profile.
+func (m *Manager) updateProxyProfile() {
+	uuid, ssid := nmGetPrimaryConnectionUuidAndSsid()
+
+	m.proxyProfilesLock.Lock()
+	defer m.proxyProfilesLock.Unlock()
+	p := selectProxyProfile(m.config.ProxyProfiles, m.config.DefaultProxyProfile, uuid, ssid)
+	if p == nil && len(m.ActiveProxyProfile) > 0 {
+		p = proxyProfileNone
+	}
+	if p == nil {
+		return
+	}
+	logger.Debugf("use proxy profile %q for connection %s %q", p.Name, uuid, ssid)
+	m.switchProxyProfile(p)
+}
+
+// GetProxyProfiles get all proxy profiles which marshaled by json.
+func (m *Manager) GetProxyProfiles() (profilesJSON string, err error) {
+	m.proxyProfilesLock.Lock()
+	defer m.proxyProfilesLock.Unlock()
+	profilesJSON, err = marshalJSON(m.config.ProxyProfiles)
+	return
+}
+
+// AddProxyProfile add a new proxy profile and return its id, the
+// field "Id" in profileJSON will be ignored.
+func (m *Manager) AddProxyProfile(profileJSON string) (id string, err error) {
+	p := &proxyProfile{}
+	if err = unmarshalJSON(profileJSON, p); err != nil {
+		return
+	}
+	if err = checkProxyProfile(p); err != nil {
+		return
+	}
+	p.Id = utils.GenUuid()
+
+	m.proxyProfilesLock.Lock()
+	m.config.mu.Lock()
+	m.config.ProxyProfiles = append(m.config.ProxyProfiles, p)
+	m.config.saveLocked()
+	m.config.mu.Unlock()
+	m.proxyProfilesLock.Unlock()
+
+	id = p.Id
+	m.updateProxyProfile()
+	return
+}
+
+// UpdateProxyProfile replace the proxy profile which has the same id
+// with profileJSON.
+func (m *Manager) UpdateProxyProfile(profileJSON string) (err error) {
+	p := &proxyProfile{}
+	if err = unmarshalJSON(profileJSON, p); err != nil {
+		return
+	}
+	if err = checkProxyProfile(p); err != nil {
+		return
+	}
+
+	m.proxyProfilesLock.Lock()
+	i, _ := findProxyProfile(m.config.ProxyProfiles, p.Id)
+	if i < 0 {
+		m.proxyProfilesLock.Unlock()
+		err = fmt.Errorf("proxy profile %s not exists", p.Id)
+		return
+	}
+	m.config.mu.Lock()
+	m.config.ProxyProfiles[i] = p
+	m.config.saveLocked()
+	m.config.mu.Unlock()
+	m.proxyProfilesLock.Unlock()
+
+	// re-apply the profile if it is in use
+	m.updateProxyProfile()
+	return
+}
+
+// RemoveProxyProfile remove the proxy profile, if the profile is in
+// use, the profile for the network location is applied again.
+func (m *Manager) RemoveProxyProfile(id string) (err error) {
+	m.proxyProfilesLock.Lock()
+	i, _ := findProxyProfile(m.config.ProxyProfiles, id)
+	if i < 0 {
+		m.proxyProfilesLock.Unlock()
+		err = fmt.Errorf("proxy profile %s not exists", id)
+		return
+	}
+	m.config.mu.Lock()
+	m.config.ProxyProfiles = append(m.config.ProxyProfiles[:i], m.config.ProxyProfiles[i+1:]...)
+	if m.config.DefaultProxyProfile == id {
+		m.config.DefaultProxyProfile = ""
+	}
+	m.config.saveLocked()
+	m.config.mu.Unlock()
+	m.proxyProfilesLock.Unlock()
+
+	m.updateProxyProfile()
+	return
+}
+
+// GetDefaultProxyProfile get the id of the proxy profile which will
+// be applied when no profile matches current network location.
+func (m *Manager) GetDefaultProxyProfile() (id string, err error) {
+	m.proxyProfilesLock.Lock()
+	defer m.proxyProfilesLock.Unlock()
+	id = m.config.DefaultProxyProfile
+	return
+}
+
+// SetDefaultProxyProfile set the proxy profile which will be applied
+// when no profile matches current network location, an empty id
+// means disabling the proxy.
+func (m *Manager) SetDefaultProxyProfile(id string) (err error) {
+	m.proxyProfilesLock.Lock()
+	if len(id) > 0 {
+		if i, _ := findProxyProfile(m.config.ProxyProfiles, id); i < 0 {
+			m.proxyProfilesLock.Unlock()
+			err = fmt.Errorf("proxy profile %s not exists", id)
+			return
+		}
+	}
+	m.config.mu.Lock()
+	m.config.DefaultProxyProfile = id
+	m.config.saveLocked()
+	m.config.mu.Unlock()
+	m.proxyProfilesLock.Unlock()
+
+	m.updateProxyProfile()
+	return
+}
+
+// ApplyProxyProfile apply the proxy profile immediately, it will be
+// replaced when the primary connection changed again.
+func (m *Manager) ApplyProxyProfile(id string) (err error) {
+	m.proxyProfilesLock.Lock()
+	defer m.proxyProfilesLock.Unlock()
+	_, p := findProxyProfile(m.config.ProxyProfiles, id)
+	if p == nil {
+		err = fmt.Errorf("proxy profile %s not exists", id)
+		return
+	}
+	err = m.switchProxyProfile(p)
+	return
+}
@@ -0,0 +1,78 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	C "launchpad.net/gocheck"
+)
+
+func (*testWrapper) TestMatchProxyProfile(c *C.C) {
+	office := &proxyProfile{Id: "office", Uuids: []string{"uuid-office-wired"}, Ssids: []string{"Office"}}
+	home := &proxyProfile{Id: "home", Ssids: []string{"Home", "Office"}}
+	guest := &proxyProfile{Id: "guest", Uuids: []string{"uuid-guest"}}
+	profiles := []*proxyProfile{office, home, guest}
+
+	c.Check(matchProxyProfile(profiles, "uuid-office-wired", ""), C.Equals, office)
+	c.Check(matchProxyProfile(profiles, "uuid-home", "Home"), C.Equals, home)
+	// profiles added earlier take precedence
+	c.Check(matchProxyProfile(profiles, "uuid-office", "Office"), C.Equals, office)
+	// uuid takes precedence over ssid
+	c.Check(matchProxyProfile(profiles, "uuid-guest", "Home"), C.Equals, guest)
+	c.Check(matchProxyProfile(profiles, "uuid-unknown", "Unknown"), C.IsNil)
+	c.Check(matchProxyProfile(profiles, "", ""), C.IsNil)
+	c.Check(matchProxyProfile(nil, "uuid-office-wired", "Office"), C.IsNil)
+}
+
+func (*testWrapper) TestCheckProxyProfile(c *C.C) {
+	c.Check(checkProxyProfile(&proxyProfile{Name: "home", Method: proxyModeNone}), C.IsNil)
+	c.Check(checkProxyProfile(&proxyProfile{Name: "office", Method: proxyModeManual,
+		Http: proxyServer{Host: "proxy.example.com", Port: 3128}}), C.IsNil)
+	c.Check(checkProxyProfile(&proxyProfile{Name: "office", Method: proxyModeAuto,
+		AutoUrl: "http://proxy.example.com/proxy.pac"}), C.IsNil)
+
+	c.Check(checkProxyProfile(&proxyProfile{Method: proxyModeNone}), C.NotNil)
+	c.Check(checkProxyProfile(&proxyProfile{Name: "office", Method: "invalid"}), C.NotNil)
+	c.Check(checkProxyProfile(&proxyProfile{Name: "office", Method: proxyModeAuto}), C.NotNil)
+	c.Check(checkProxyProfile(&proxyProfile{Name: "office", Method: proxyModeManual,
+		Socks: proxyServer{Host: "proxy.example.com", Port: 65536}}), C.NotNil)
+}
+
+func (*testWrapper) TestSelectProxyProfile(c *C.C) {
+	office := &proxyProfile{Id: "office", Ssids: []string{"Office"}}
+	home := &proxyProfile{Id: "home", Ssids: []string{"Home"}}
+	profiles := []*proxyProfile{office, home}
+
+	c.Check(selectProxyProfile(profiles, "home", "", "Office"), C.Equals, office)
+	c.Check(selectProxyProfile(profiles, "home", "", "Cafe"), C.Equals, home)
+	// fall back to no proxy instead of keeping the previous one
+	c.Check(selectProxyProfile(profiles, "", "", "Cafe"), C.Equals, proxyProfileNone)
+	c.Check(selectProxyProfile(profiles, "removed", "", "Cafe"), C.Equals, proxyProfileNone)
+	c.Check(selectProxyProfile(nil, "", "", "Cafe"), C.IsNil)
+}
+
+func (*testWrapper) TestIsSameProxy(c *C.C) {
+	http := proxyServer{Host: "proxy.example.com", Port: 3128}
+	none := &proxyProfile{Method: proxyModeNone}
+	manual := &proxyProfile{Method: proxyModeManual, Http: http}
+	auto := &proxyProfile{Method: proxyModeAuto, AutoUrl: "http://proxy.example.com/proxy.pac"}
+
+	c.Check(isSameProxy(none, proxyProfileNone), C.Equals, true)
+	// the settings not used by the method are ignored
+	c.Check(isSameProxy(&proxyProfile{Method: proxyModeNone, Http: http}, none), C.Equals, true)
+	c.Check(isSameProxy(&proxyProfile{Method: proxyModeManual, Http: http, AutoUrl: auto.AutoUrl,
+		IgnoreHosts: []string{}}, manual), C.Equals, true)
+
+	c.Check(isSameProxy(none, manual), C.Equals, false)
+	c.Check(isSameProxy(manual, &proxyProfile{Method: proxyModeManual,
+		Http: proxyServer{Host: "proxy.example.com", Port: 8080}}), C.Equals, false)
+	c.Check(isSameProxy(auto, &proxyProfile{Method: proxyModeAuto}), C.Equals, false)
+	c.Check(isSameProxy(manual, &proxyProfile{Method: proxyModeManual, Http: http,
+		IgnoreHosts: []string{"localhost"}}), C.Equals, false)
+}
@@ -0,0 +1,534 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"pkg.deepin.io/lib/dbus"
+	"pkg.deepin.io/lib/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Traffic statistics are sampled from the RX/TX byte counters of the
+// network interfaces in sysfs, and the deltas are accounted to the
+// connection which is active on the interface at sampling time.
+const (
+	trafficSampleInterval = 10 * time.Second
+	trafficSaveInterval   = 5 * time.Minute
+
+	// how many daily and monthly records to keep for each connection
+	trafficKeepDays   = 62
+	trafficKeepMonths = 24
+
+	trafficDayLayout   = "2006-01-02"
+	trafficMonthLayout = "2006-01"
+)
+
+var sysClassNetDir = "/sys/class/net"
+
+type trafficRecord struct {
+	Rx uint64
+	Tx uint64
+}
+
+func (r *trafficRecord) total() uint64 {
+	return r.Rx + r.Tx
+}
+
+type connectionTraffic struct {
+	Daily   map[string]*trafficRecord // key is the day, e.g. "2017-05-01"
+	Monthly map[string]*trafficRecord // key is the month, e.g. "2017-05"
+
+	// DataCap is the monthly data limit in bytes, 0 means no limit.
+	DataCap uint64
+	// DataCapNotified is the month in which the data cap alert
+	// was shown, to avoid notifying again in the same month.
+	DataCapNotified string
+}
+
+func newConnectionTraffic() *connectionTraffic {
+	return &connectionTraffic{
+		Daily:   make(map[string]*trafficRecord),
+		Monthly: make(map[string]*trafficRecord),
+	}
+}
+
+func (ct *connectionTraffic) add(t time.Time, rx, tx uint64) {
+	day := t.Format(trafficDayLayout)
+	month := t.Format(trafficMonthLayout)
+	for _, item := range []struct {
+		records map[string]*trafficRecord
+		key     string
+	}{{ct.Daily, day}, {ct.Monthly, month}} {
+		r, ok := item.records[item.key]
+		if !ok {
+			r = &trafficRecord{}
+			item.records[item.key] = r
+		}
+		r.Rx += rx
+		r.Tx += tx
+	}
+}
+
+func (ct *connectionTraffic) getDay(t time.Time) trafficRecord {
+	if r, ok := ct.Daily[t.Format(trafficDayLayout)]; ok {
+		return *r
+	}
+	return trafficRecord{}
+}
+
+func (ct *connectionTraffic) getMonth(t time.Time) trafficRecord {
+	if r, ok := ct.Monthly[t.Format(trafficMonthLayout)]; ok {
+		return *r
+	}
+	return trafficRecord{}
+}
+
+// prune removes the records that are too old to be kept.
+func (ct *connectionTraffic) prune(t time.Time) {
+	oldestDay := t.AddDate(0, 0, -trafficKeepDays+1).Format(trafficDayLayout)
+	for key := range ct.Daily {
+		if key < oldestDay {
+			delete(ct.Daily, key)
+		}
+	}
+	oldestMonth := t.AddDate(0, -trafficKeepMonths+1, 0).Format(trafficMonthLayout)
+	for key := range ct.Monthly {
+		if key < oldestMonth {
+			delete(ct.Monthly, key)
+		}
+	}
+}
+
+// isDataCapExceeded check if the data cap of current month is
+// reached and the user has not been notified yet.
+func (ct *connectionTraffic) isDataCapExceeded(t time.Time) bool {
+	if ct.DataCap == 0 {
+		return false
+	}
+	if ct.DataCapNotified == t.Format(trafficMonthLayout) {
+		return false
+	}
+	month := ct.getMonth(t)
+	return month.total() >= ct.DataCap
+}
+
+// trafficStore is the persistent store of traffic statistics for
+// each connection uuid.
+type trafficStore struct {
+	core utils.Config
+
+	Connections map[string]*connectionTraffic
+}
+
+func newTrafficStore() *trafficStore {
+	s := &trafficStore{}
+	s.core.SetConfigName("network-traffic")
+	logger.Info("traffic statistics file:", s.core.GetConfigFile())
+	s.Connections = make(map[string]*connectionTraffic)
+	s.load()
+	return s
+}
+
+func (s *trafficStore) load() {
+	s.core.Load(s)
+	if s.Connections == nil {
+		s.Connections = make(map[string]*connectionTraffic)
+	}
+	for _, ct := range s.Connections {
+		if ct.Daily == nil {
+			ct.Daily = make(map[string]*trafficRecord)
+		}
+		if ct.Monthly == nil {
+			ct.Monthly = make(map[string]*trafficRecord)
+		}
+	}
+}
+
+func (s *trafficStore) save() {
+	s.core.Save(s)
+}
+
+func (s *trafficStore) get(uuid string) *connectionTraffic {
+	ct, ok := s.Connections[uuid]
+	if !ok {
+		ct = newConnectionTraffic()
+		s.Connections[uuid] = ct
+	}
+	return ct
+}
+
+// trafficCounter is the last RX/TX counter value read from an
+// interface and the connection it belongs to.
+type trafficCounter struct {
+	uuid string
+	rx   uint64
+	tx   uint64
+}
+
+// calcTrafficDelta returns the bytes transferred between two counter
+// values, if the counter is reset, e.g. the interface is recreated,
+// the current value is used.
+func calcTrafficDelta(last, cur uint64) uint64 {
+	if cur < last {
+		return cur
+	}
+	return cur - last
+}
+
+func readInterfaceCounter(iface, name string) (uint64, error) {
+	file := filepath.Join(sysClassNetDir, iface, "statistics", name)
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+func readInterfaceTraffic(iface string) (rx, tx uint64, err error) {
+	rx, err = readInterfaceCounter(iface, "rx_bytes")
+	if err != nil {
+		return
+	}
+	tx, err = readInterfaceCounter(iface, "tx_bytes")
+	return
+}
+
+type trafficStats struct {
+	mu       sync.Mutex
+	store    *trafficStore
+	counters map[string]*trafficCounter // key is interface name
+	lastSave time.Time
+	quit     chan struct{}
+
+	// interfacesGetter returns the interface name to connection
+	// uuid map for all the active connections.
+	interfacesGetter func() map[string]string
+	// connectionIdGetter returns the display name of connection.
+	connectionIdGetter func(uuid string) string
+}
+
+func newTrafficStats(interfacesGetter func() map[string]string,
+	connectionIdGetter func(uuid string) string) *trafficStats {
+	return &trafficStats{
+		store:              newTrafficStore(),
+		counters:           make(map[string]*trafficCounter),
+		lastSave:           time.Now(),
+		interfacesGetter:   interfacesGetter,
+		connectionIdGetter: connectionIdGetter,
+	}
+}
+
+func (ts *trafficStats) start() {
+	quit := make(chan struct{})
+	ts.quit = quit
+	go func() {
+		ticker := time.NewTicker(trafficSampleInterval)
+		defer ticker.Stop()
+		ts.sample(time.Now())
+		for {
+			select {
+			case <-ticker.C:
+				ts.sample(time.Now())
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
+
+func destroyTrafficStats(ts *trafficStats) {
+	if ts.quit != nil {
+		close(ts.quit)
+		ts.quit = nil
+	}
+	ts.mu.Lock()
+	ts.store.save()
+	ts.mu.Unlock()
+}
+
+func (ts *trafficStats) sample(t time.Time) {
+	interfaces := ts.interfacesGetter()
+	exceeded := make(map[string]uint64)
+
+	ts.mu.Lock()
+	for iface, uuid := range interfaces {
+		rx, tx, err := readInterfaceTraffic(iface)
+		if err != nil {
+			logger.Debug("read interface traffic failed:", err)
+			continue
+		}
+		if ts.update(t, iface, uuid, rx, tx) {
+			exceeded[uuid] = ts.store.get(uuid).DataCap
+		}
+	}
+	// forget interfaces that no longer belong to any active connection
+	for iface := range ts.counters {
+		if _, ok := interfaces[iface]; !ok {
+			delete(ts.counters, iface)
+		}
+	}
+	if t.Sub(ts.lastSave) >= trafficSaveInterval {
+		for _, ct := range ts.store.Connections {
+			ct.prune(t)
+		}
+		ts.store.save()
+		ts.lastSave = t
+	}
+	ts.mu.Unlock()
+
+	ts.notifyDataCapReached(exceeded)
+}
+
+// flush samples the interfaces for the connections they belonged to at
+// the last sample, it is called before the active connections change,
+// so the traffic since the last sample is not lost.
+func (ts *trafficStats) flush(t time.Time) {
+	exceeded := make(map[string]uint64)
+
+	ts.mu.Lock()
+	for iface, counter := range ts.counters {
+		rx, tx, err := readInterfaceTraffic(iface)
+		if err != nil {
+			logger.Debug("read interface traffic failed:", err)
+			continue
+		}
+		if ts.update(t, iface, counter.uuid, rx, tx) {
+			exceeded[counter.uuid] = ts.store.get(counter.uuid).DataCap
+		}
+	}
+	ts.mu.Unlock()
+
+	ts.notifyDataCapReached(exceeded)
+}
+
+func (ts *trafficStats) notifyDataCapReached(exceeded map[string]uint64) {
+	for uuid, dataCap := range exceeded {
+		notifyDataCapReached(ts.connectionIdGetter(uuid), formatTrafficBytes(dataCap))
+	}
+}
+
+// update accounts the counter values of an interface to the
+// connection, and returns true if the data cap of the connection
+// is just reached. Caller must hold the lock.
+func (ts *trafficStats) update(t time.Time, iface, uuid string, rx, tx uint64) bool {
+	counter, ok := ts.counters[iface]
+	if !ok || counter.uuid != uuid {
+		// first sample of the connection on this interface, just
+		// remember the base values
+		ts.counters[iface] = &trafficCounter{uuid: uuid, rx: rx, tx: tx}
+		return false
+	}
+
+	ct := ts.store.get(uuid)
+	ct.add(t, calcTrafficDelta(counter.rx, rx), calcTrafficDelta(counter.tx, tx))
+	counter.rx = rx
+	counter.tx = tx
+
+	if ct.isDataCapExceeded(t) {
+		ct.DataCapNotified = t.Format(trafficMonthLayout)
+		return true
+	}
+	return false
+}
+
+type trafficDayInfo struct {
+	Day string
+	Rx  uint64
+	Tx  uint64
+}
+
+type trafficInfo struct {
+	Uuid    string
+	Today   trafficRecord
+	Month   trafficRecord
+	DataCap uint64
+	Daily   []trafficDayInfo
+	Monthly []trafficDayInfo
+}
+
+func sortedTrafficRecords(records map[string]*trafficRecord) []trafficDayInfo {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	infos := make([]trafficDayInfo, 0, len(keys))
+	for _, key := range keys {
+		r := records[key]
+		infos = append(infos, trafficDayInfo{Day: key, Rx: r.Rx, Tx: r.Tx})
+	}
+	return infos
+}
+
+func (ts *trafficStats) getInfo(uuid string, t time.Time) *trafficInfo {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	info := &trafficInfo{Uuid: uuid}
+	ct, ok := ts.store.Connections[uuid]
+	if !ok {
+		info.Daily = []trafficDayInfo{}
+		info.Monthly = []trafficDayInfo{}
+		return info
+	}
+	info.Today = ct.getDay(t)
+	info.Month = ct.getMonth(t)
+	info.DataCap = ct.DataCap
+	info.Daily = sortedTrafficRecords(ct.Daily)
+	info.Monthly = sortedTrafficRecords(ct.Monthly)
+	return info
+}
+
+func (ts *trafficStats) setDataCap(uuid string, dataCap uint64) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ct := ts.store.get(uuid)
+	if ct.DataCap != dataCap {
+		ct.DataCap = dataCap
+		// notify again if the new limit is reached
+		ct.DataCapNotified = ""
+	}
+	ts.store.save()
+}
+
+func (ts *trafficStats) reset(uuid string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ct, ok := ts.store.Connections[uuid]
+	if !ok {
+		return
+	}
+	ct.Daily = make(map[string]*trafficRecord)
+	ct.Monthly = make(map[string]*trafficRecord)
+	ct.DataCapNotified = ""
+	ts.store.save()
+}
+
+func (ts *trafficStats) removeConnections(uuids []string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, uuid := range uuids {
+		delete(ts.store.Connections, uuid)
+	}
+	ts.store.save()
+}
+
+// getActiveInterfaces returns the ip interface name to connection
+// uuid map for the active connections. VPN connections are skipped,
+// for the traffic of them is counted by the underlying connection.
+func (m *Manager) getActiveInterfaces() map[string]string {
+	m.activeConnectionsLock.Lock()
+	var devConns = make(map[dbus.ObjectPath]string)
+	for _, aconn := range m.activeConnections {
+		if aconn.Vpn || !isConnectionStateActivated(aconn.State) {
+			continue
+		}
+		for _, devPath := range aconn.Devices {
+			devConns[devPath] = aconn.Uuid
+		}
+	}
+	m.activeConnectionsLock.Unlock()
+
+	interfaces := make(map[string]string)
+	for devPath, uuid := range devConns {
+		iface := nmGetDeviceIpInterface(devPath)
+		if len(iface) == 0 {
+			iface = nmGetDeviceInterface(devPath)
+		}
+		if len(iface) != 0 {
+			interfaces[iface] = uuid
+		}
+	}
+	return interfaces
+}
+
+func (m *Manager) getConnectionIdByUuid(uuid string) string {
+	if cpath, err := nmGetConnectionByUuid(uuid); err == nil {
+		return nmGetConnectionId(cpath)
+	}
+	return uuid
+}
+
+func (m *Manager) initTrafficStats() {
+	m.trafficStats = newTrafficStats(m.getActiveInterfaces, m.getConnectionIdByUuid)
+
+	// remove statistics of connections that no longer exist
+	var spareUuids []string
+	uuids := nmGetConnectionUuids()
+	for uuid := range m.trafficStats.store.Connections {
+		if !isStringInArray(uuid, uuids) {
+			spareUuids = append(spareUuids, uuid)
+		}
+	}
+	if len(spareUuids) > 0 {
+		m.trafficStats.removeConnections(spareUuids)
+	}
+
+	m.trafficStats.start()
+}
+
+// GetTrafficStats returns the traffic statistics of a connection
+// which marshaled by json, including the bytes received and sent of
+// today and current month, the data cap, and the daily and monthly
+// history.
+func (m *Manager) GetTrafficStats(uuid string) (statsJSON string, err error) {
+	if m.trafficStats == nil {
+		err = fmt.Errorf("traffic statistics is not initialized")
+		return
+	}
+	statsJSON, err = marshalJSON(m.trafficStats.getInfo(uuid, time.Now()))
+	return
+}
+
+// SetTrafficDataCap set the monthly data limit in bytes of a
+// connection, a notification will be shown once the limit is
+// reached, 0 means no limit.
+func (m *Manager) SetTrafficDataCap(uuid string, dataCap uint64) (err error) {
+	if m.trafficStats == nil {
+		err = fmt.Errorf("traffic statistics is not initialized")
+		return
+	}
+	if _, err = nmGetConnectionByUuid(uuid); err != nil {
+		return
+	}
+	m.trafficStats.setDataCap(uuid, dataCap)
+	return
+}
+
+// ResetTrafficStats clear the traffic statistics of a connection.
+func (m *Manager) ResetTrafficStats(uuid string) (err error) {
+	if m.trafficStats == nil {
+		err = fmt.Errorf("traffic statistics is not initialized")
+		return
+	}
+	m.trafficStats.reset(uuid)
+	return
+}
+
+func formatTrafficBytes(n uint64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(n)
+	i := 0
+	for value >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d %s", n, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
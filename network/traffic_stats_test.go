@@ -0,0 +1,115 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	C "launchpad.net/gocheck"
+	"time"
+)
+
+func newTestTrafficStats() *trafficStats {
+	return &trafficStats{
+		store:    &trafficStore{Connections: make(map[string]*connectionTraffic)},
+		counters: make(map[string]*trafficCounter),
+	}
+}
+
+func (*testWrapper) TestCalcTrafficDelta(c *C.C) {
+	c.Check(calcTrafficDelta(100, 150), C.Equals, uint64(50))
+	c.Check(calcTrafficDelta(100, 100), C.Equals, uint64(0))
+	// counter reset
+	c.Check(calcTrafficDelta(100, 30), C.Equals, uint64(30))
+}
+
+func (*testWrapper) TestReadInterfaceTraffic(c *C.C) {
+	oldDir := sysClassNetDir
+	sysClassNetDir = "testdata/traffic"
+	defer func() { sysClassNetDir = oldDir }()
+
+	rx, tx, err := readInterfaceTraffic("eth0")
+	c.Check(err, C.IsNil)
+	c.Check(rx, C.Equals, uint64(1234))
+	c.Check(tx, C.Equals, uint64(5678))
+
+	_, _, err = readInterfaceTraffic("eth1")
+	c.Check(err, C.NotNil)
+}
+
+func (*testWrapper) TestConnectionTrafficAdd(c *C.C) {
+	ct := newConnectionTraffic()
+	day1 := time.Date(2017, 5, 31, 23, 0, 0, 0, time.Local)
+	day2 := time.Date(2017, 6, 1, 1, 0, 0, 0, time.Local)
+	ct.add(day1, 10, 20)
+	ct.add(day1, 1, 2)
+	ct.add(day2, 100, 200)
+
+	c.Check(ct.getDay(day1), C.Equals, trafficRecord{Rx: 11, Tx: 22})
+	c.Check(ct.getDay(day2), C.Equals, trafficRecord{Rx: 100, Tx: 200})
+	c.Check(ct.getMonth(day1), C.Equals, trafficRecord{Rx: 11, Tx: 22})
+	c.Check(ct.getMonth(day2), C.Equals, trafficRecord{Rx: 100, Tx: 200})
+	c.Check(ct.getMonth(day2.AddDate(0, 1, 0)), C.Equals, trafficRecord{})
+}
+
+func (*testWrapper) TestConnectionTrafficPrune(c *C.C) {
+	ct := newConnectionTraffic()
+	now := time.Date(2017, 6, 15, 12, 0, 0, 0, time.Local)
+	ct.add(now, 1, 1)
+	ct.add(now.AddDate(0, 0, -trafficKeepDays), 1, 1)
+	ct.add(now.AddDate(-3, 0, 0), 1, 1)
+	ct.prune(now)
+
+	c.Check(len(ct.Daily), C.Equals, 1)
+	c.Check(len(ct.Monthly), C.Equals, 2)
+	_, ok := ct.Monthly["2014-06"]
+	c.Check(ok, C.Equals, false)
+}
+
+func (*testWrapper) TestTrafficStatsUpdate(c *C.C) {
+	ts := newTestTrafficStats()
+	uuid := "8e2f9aa2-42b8-47d5-b040-ae82c53fa1f2"
+	now := time.Date(2017, 6, 15, 12, 0, 0, 0, time.Local)
+
+	// the first sample only records the base values
+	c.Check(ts.update(now, "wwan0", uuid, 1000, 500), C.Equals, false)
+	c.Check(ts.store.Connections[uuid], C.IsNil)
+
+	ts.store.get(uuid).DataCap = 1000
+	c.Check(ts.update(now, "wwan0", uuid, 1400, 600), C.Equals, false)
+	c.Check(ts.store.get(uuid).getMonth(now), C.Equals, trafficRecord{Rx: 400, Tx: 100})
+
+	// data cap reached, only notify once in a month
+	c.Check(ts.update(now, "wwan0", uuid, 1900, 600), C.Equals, true)
+	c.Check(ts.update(now, "wwan0", uuid, 2000, 600), C.Equals, false)
+	c.Check(ts.update(now.AddDate(0, 1, 0), "wwan0", uuid, 3000, 600), C.Equals, true)
+
+	// another connection activated on the same interface
+	other := "1b0c7a0e-4f2b-4bd4-9a51-4d0c8f5a2a01"
+	c.Check(ts.update(now, "wwan0", other, 5000, 5000), C.Equals, false)
+	c.Check(ts.store.Connections[other], C.IsNil)
+}
+
+func (*testWrapper) TestTrafficStatsFlush(c *C.C) {
+	oldDir := sysClassNetDir
+	sysClassNetDir = "testdata/traffic"
+	defer func() { sysClassNetDir = oldDir }()
+
+	ts := newTestTrafficStats()
+	uuid := "8e2f9aa2-42b8-47d5-b040-ae82c53fa1f2"
+	now := time.Date(2017, 6, 15, 12, 0, 0, 0, time.Local)
+	ts.counters["eth0"] = &trafficCounter{uuid: uuid, rx: 1000, tx: 5000}
+	// the interface is gone
+	ts.counters["eth1"] = &trafficCounter{uuid: uuid, rx: 1000, tx: 5000}
+
+	// the traffic since the last sample is accounted to the connection
+	// the interface belonged to
+	ts.flush(now)
+	c.Check(ts.store.get(uuid).getMonth(now), C.Equals, trafficRecord{Rx: 234, Tx: 678})
+	c.Check(ts.counters["eth0"], C.DeepEquals, &trafficCounter{uuid: uuid, rx: 1234, tx: 5678})
+}
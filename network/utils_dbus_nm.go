@@ -53,7 +53,7 @@ func isNmDeviceObjectExists(devPath dbus.ObjectPath) bool {
 
 func isDeviceTypeValid(devType uint32) bool {
 	switch devType {
-	case nm.NM_DEVICE_TYPE_GENERIC, nm.NM_DEVICE_TYPE_UNKNOWN, nm.NM_DEVICE_TYPE_BT, nm.NM_DEVICE_TYPE_TEAM, nm.NM_DEVICE_TYPE_TUN, nm.NM_DEVICE_TYPE_IP_TUNNEL, nm.NM_DEVICE_TYPE_MACVLAN, nm.NM_DEVICE_TYPE_VXLAN, nm.NM_DEVICE_TYPE_VETH:
+	case nm.NM_DEVICE_TYPE_GENERIC, nm.NM_DEVICE_TYPE_UNKNOWN, nm.NM_DEVICE_TYPE_BT, nm.NM_DEVICE_TYPE_TUN, nm.NM_DEVICE_TYPE_IP_TUNNEL, nm.NM_DEVICE_TYPE_MACVLAN, nm.NM_DEVICE_TYPE_VXLAN, nm.NM_DEVICE_TYPE_VETH:
 		return false
 	}
 	return true
@@ -166,6 +166,16 @@ func isSettingRequireSecret(flag uint32) bool {
 	return false
 }
 
+// isSoftwareDeviceType check if the device is created by
+// network-manager through bond, bridge, team or vlan connections
+func isSoftwareDeviceType(devType uint32) bool {
+	switch devType {
+	case nm.NM_DEVICE_TYPE_BOND, nm.NM_DEVICE_TYPE_BRIDGE, nm.NM_DEVICE_TYPE_TEAM, nm.NM_DEVICE_TYPE_VLAN:
+		return true
+	}
+	return false
+}
+
 func isVirtualDeviceIfc(dev *nmdbus.Device) bool {
 	switch dev.Driver.Get() {
 	case "dummy", "veth", "vboxnet", "vmnet", "vmxnet", "vmxnet2", "vmxnet3":
@@ -752,6 +762,17 @@ func nmGetDeviceInterface(devPath dbus.ObjectPath) (devInterface string) {
 	return
 }
 
+func nmGetDeviceIpInterface(devPath dbus.ObjectPath) (ipInterface string) {
+	dev, err := nmNewDevice(devPath)
+	if err != nil {
+		return
+	}
+	defer nmdbus.DestroyDevice(dev)
+
+	ipInterface = dev.IpInterface.Get()
+	return
+}
+
 func nmGetDeviceModemCapabilities(devPath dbus.ObjectPath) (capabilities uint32) {
 	devModem, err := nmNewDeviceModem(devPath)
 	if err != nil {
@@ -871,6 +892,11 @@ func nmGetManagerState() (state uint32) {
 	return
 }
 
+func nmGetConnectivity() (connectivity uint32) {
+	connectivity = nmManager.Connectivity.Get()
+	return
+}
+
 func nmGetActiveConnectionByUuid(uuid string) (apaths []dbus.ObjectPath, err error) {
 	for _, apath := range nmGetActiveConnections() {
 		if aconn, tmperr := nmNewActiveConnection(apath); tmperr == nil {
@@ -1390,6 +1416,36 @@ func nmGetPrimaryConnection() (cpath dbus.ObjectPath) {
 	return
 }
 
+// nmGetPrimaryConnectionUuidAndSsid get the connection uuid of the
+// primary active connection, and the ssid if it is a wireless
+// connection.
+func nmGetPrimaryConnectionUuidAndSsid() (uuid, ssid string) {
+	apath := nmGetPrimaryConnection()
+	if !isNmObjectPathValid(apath) {
+		return
+	}
+	aconn, err := nmNewActiveConnection(apath)
+	if err != nil {
+		return
+	}
+	defer nmDestroyActiveConnection(aconn)
+
+	uuid = aconn.Uuid.Get()
+	if aconn.Type.Get() != nm.NM_SETTING_WIRELESS_SETTING_NAME {
+		return
+	}
+	cpath, err := nmGetConnectionByUuid(uuid)
+	if err != nil {
+		return
+	}
+	data, err := nmGetConnectionData(cpath)
+	if err != nil {
+		return
+	}
+	ssid = string(getSettingWirelessSsid(data))
+	return
+}
+
 func nmGetNetworkState() uint32 {
 	return nmManager.State.Get()
 }
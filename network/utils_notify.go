@@ -11,9 +11,11 @@ package network
 
 import (
 	"dbus/org/freedesktop/notifications"
+	"fmt"
 	"pkg.deepin.io/dde/daemon/network/nm"
 	"pkg.deepin.io/lib/dbus"
 	. "pkg.deepin.io/lib/gettext"
+	"sync"
 	"time"
 )
 
@@ -78,6 +80,45 @@ func notify(icon, summary, body string) {
 	return
 }
 
+// notifyWithAction show a notification with a button, and the
+// callback will be called once the button clicked
+func notifyWithAction(icon, summary, body, actionText string, callback func()) {
+	const actionKey = "default"
+	notifier, err := notifications.NewNotifier(dbusNotifyDest, dbusNotifyPath)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	logger.Info("notify with action", icon, summary, body)
+	go func() {
+		var id uint32
+		var idLock sync.Mutex
+		// hold the lock to ignore signals until the id is returned
+		idLock.Lock()
+		notifier.ConnectActionInvoked(func(actionId uint32, key string) {
+			idLock.Lock()
+			defer idLock.Unlock()
+			if actionId == id && key == actionKey {
+				go callback()
+			}
+		})
+		notifier.ConnectNotificationClosed(func(closedId uint32, reason uint32) {
+			idLock.Lock()
+			defer idLock.Unlock()
+			if closedId == id {
+				go notifications.DestroyNotifier(notifier)
+			}
+		})
+		var notifyErr error
+		id, notifyErr = notifier.Notify("Network", 0, icon, summary, body, []string{actionKey, actionText}, nil, -1)
+		idLock.Unlock()
+		if notifyErr != nil {
+			logger.Error(notifyErr)
+			notifications.DestroyNotifier(notifier)
+		}
+	}()
+}
+
 func notifyNetworkOffline() {
 	notify(notifyIconNetworkOffline, Tr("Disconnected"), Tr("You are now offline."))
 }
@@ -104,6 +145,13 @@ func notifyProxyEnabled() {
 func notifyProxyDisabled() {
 	notify(notifyIconProxyDisabled, Tr("Network"), Tr("System proxy has been cancelled."))
 }
+func notifyProxyProfileApplied(name, method string) {
+	icon := notifyIconProxyEnabled
+	if method == proxyModeNone {
+		icon = notifyIconProxyDisabled
+	}
+	notify(icon, Tr("Network"), fmt.Sprintf(Tr("Proxy profile %q has been applied."), name))
+}
 
 func notifyVpnConnected(id string) {
 	notify(notifyIconVpnConnected, Tr("Connected"), id)
@@ -203,3 +251,18 @@ func notifyDeviceRemoved(devPath dbus.ObjectPath) {
 	msg := deviceErrorTable[nm.NM_DEVICE_STATE_REASON_REMOVED]
 	notify(icon, Tr("Disconnected"), msg)
 }
+
+func notifyCaptivePortal(portalUrl string) {
+	notifyWithAction(notifyIconNetworkConnected, Tr("Network"),
+		Tr("The network requires login, click to open the login page."),
+		Tr("Log in"), func() {
+			if err := openUrlInDefaultBrowser(portalUrl); err != nil {
+				logger.Warning("open captive portal login page failed:", err)
+			}
+		})
+}
+
+func notifyDataCapReached(id, dataCap string) {
+	notify(notifyIconNetworkConnected, Tr("Network"),
+		fmt.Sprintf(Tr("The data usage of %q has reached the limit of %s for this month."), id, dataCap))
+}
@@ -0,0 +1,212 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"pkg.deepin.io/dde/daemon/network/nm"
+	"strings"
+)
+
+// exportVpnConnectionData convert the connection data to VPN profile
+// which could be imported by importVpnConnectionData(), secrets will
+// not be exported
+func exportVpnConnectionData(data connectionData) (content []byte, err error) {
+	var buf bytes.Buffer
+	switch getCustomConnectionType(data) {
+	case connectionVpnOpenvpn:
+		exportVpnOpenvpnProfile(data, &buf)
+	case connectionVpnVpnc:
+		exportVpnVpncProfile(data, &buf)
+	case connectionVpnStrongswan:
+		exportVpnStrongswanProfile(data, &buf)
+	default:
+		err = fmt.Errorf("connection type is out of support: %s", getCustomConnectionType(data))
+		return
+	}
+	content = buf.Bytes()
+	return
+}
+
+// write the file content as inline block if possible, so the profile
+// could be distributed as a single file
+func exportVpnOpenvpnFile(buf *bytes.Buffer, name, file string) {
+	if len(file) == 0 {
+		return
+	}
+	content, err := ioutil.ReadFile(toLocalPath(file))
+	if err != nil {
+		logger.Warning("read openvpn file failed, keep the path only:", err)
+		fmt.Fprintf(buf, "%s %q\n", name, toLocalPath(file))
+		return
+	}
+	fmt.Fprintf(buf, "<%s>\n%s", name, content)
+	if !bytes.HasSuffix(content, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(buf, "</%s>\n", name)
+}
+
+func exportVpnOpenvpnProfile(data connectionData, buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "# %s\n", getSettingConnectionId(data))
+	buf.WriteString("client\n")
+	if getSettingVpnOpenvpnKeyTapDev(data) {
+		buf.WriteString("dev tap\n")
+	} else {
+		buf.WriteString("dev tun\n")
+	}
+	if getSettingVpnOpenvpnKeyProtoTcp(data) {
+		buf.WriteString("proto tcp\n")
+	} else {
+		buf.WriteString("proto udp\n")
+	}
+	for _, remote := range strings.Split(getSettingVpnOpenvpnKeyRemote(data), ",") {
+		remote = strings.TrimSpace(remote)
+		if len(remote) == 0 {
+			continue
+		}
+		fmt.Fprintf(buf, "remote %s\n", strings.Join(strings.SplitN(remote, ":", 3), " "))
+	}
+	if isSettingVpnOpenvpnKeyPortExists(data) {
+		fmt.Fprintf(buf, "port %d\n", getSettingVpnOpenvpnKeyPort(data))
+	}
+	if getSettingVpnOpenvpnKeyRemoteRandom(data) {
+		buf.WriteString("remote-random\n")
+	}
+	buf.WriteString("nobind\n")
+
+	connType := getSettingVpnOpenvpnKeyConnectionType(data)
+	switch connType {
+	case nm.NM_OPENVPN_CONTYPE_PASSWORD, nm.NM_OPENVPN_CONTYPE_PASSWORD_TLS:
+		buf.WriteString("auth-user-pass\n")
+	}
+	switch connType {
+	case nm.NM_OPENVPN_CONTYPE_STATIC_KEY:
+		if isSettingVpnOpenvpnKeyStaticKeyDirectionExists(data) {
+			fmt.Fprintf(buf, "key-direction %d\n", getSettingVpnOpenvpnKeyStaticKeyDirection(data))
+		}
+		exportVpnOpenvpnFile(buf, "secret", getSettingVpnOpenvpnKeyStaticKey(data))
+		if isSettingVpnOpenvpnKeyLocalIpExists(data) && isSettingVpnOpenvpnKeyRemoteIpExists(data) {
+			fmt.Fprintf(buf, "ifconfig %s %s\n", getSettingVpnOpenvpnKeyLocalIp(data), getSettingVpnOpenvpnKeyRemoteIp(data))
+		}
+	default:
+		exportVpnOpenvpnFile(buf, "ca", getSettingVpnOpenvpnKeyCa(data))
+		exportVpnOpenvpnFile(buf, "cert", getSettingVpnOpenvpnKeyCert(data))
+		exportVpnOpenvpnFile(buf, "key", getSettingVpnOpenvpnKeyKey(data))
+	}
+	if isSettingVpnOpenvpnKeyTaExists(data) {
+		if isSettingVpnOpenvpnKeyTaDirExists(data) {
+			fmt.Fprintf(buf, "key-direction %d\n", getSettingVpnOpenvpnKeyTaDir(data))
+		}
+		exportVpnOpenvpnFile(buf, "tls-auth", getSettingVpnOpenvpnKeyTa(data))
+	}
+
+	if v := getSettingVpnOpenvpnKeyCipher(data); len(v) > 0 {
+		fmt.Fprintf(buf, "cipher %s\n", v)
+	}
+	if v := getSettingVpnOpenvpnKeyAuth(data); len(v) > 0 {
+		fmt.Fprintf(buf, "auth %s\n", v)
+	}
+	if getSettingVpnOpenvpnKeyCompLzo(data) {
+		buf.WriteString("comp-lzo\n")
+	}
+	if isSettingVpnOpenvpnKeyTunnelMtuExists(data) {
+		fmt.Fprintf(buf, "tun-mtu %d\n", getSettingVpnOpenvpnKeyTunnelMtu(data))
+	}
+	if isSettingVpnOpenvpnKeyFragmentSizeExists(data) {
+		fmt.Fprintf(buf, "fragment %d\n", getSettingVpnOpenvpnKeyFragmentSize(data))
+	}
+	if getSettingVpnOpenvpnKeyMssfix(data) {
+		buf.WriteString("mssfix\n")
+	}
+	if isSettingVpnOpenvpnKeyRenegSecondsExists(data) {
+		fmt.Fprintf(buf, "reneg-sec %d\n", getSettingVpnOpenvpnKeyRenegSeconds(data))
+	}
+	if v := getSettingVpnOpenvpnKeyRemoteCertTls(data); len(v) > 0 {
+		fmt.Fprintf(buf, "remote-cert-tls %s\n", v)
+	}
+	if v := getSettingVpnOpenvpnKeyTlsRemote(data); len(v) > 0 {
+		fmt.Fprintf(buf, "tls-remote %q\n", v)
+	}
+
+	switch getSettingVpnOpenvpnKeyProxyType(data) {
+	case "httpect":
+		fmt.Fprintf(buf, "http-proxy %s %d\n", getSettingVpnOpenvpnKeyProxyServer(data), getSettingVpnOpenvpnKeyProxyPort(data))
+		if getSettingVpnOpenvpnKeyProxyRetry(data) {
+			buf.WriteString("http-proxy-retry\n")
+		}
+	case "socksct":
+		fmt.Fprintf(buf, "socks-proxy %s %d\n", getSettingVpnOpenvpnKeyProxyServer(data), getSettingVpnOpenvpnKeyProxyPort(data))
+		if getSettingVpnOpenvpnKeyProxyRetry(data) {
+			buf.WriteString("socks-proxy-retry\n")
+		}
+	}
+}
+
+func exportVpnVpncProfile(data connectionData, buf *bytes.Buffer) {
+	buf.WriteString("[main]\n")
+	fmt.Fprintf(buf, "Description=%s\n", getSettingConnectionId(data))
+	fmt.Fprintf(buf, "Host=%s\n", getSettingVpnVpncKeyGateway(data))
+	buf.WriteString("AuthType=1\n")
+	fmt.Fprintf(buf, "GroupName=%s\n", getSettingVpnVpncKeyId(data))
+	fmt.Fprintf(buf, "Username=%s\n", getSettingVpnVpncKeyXauthUser(data))
+	fmt.Fprintf(buf, "NTDomain=%s\n", getSettingVpnVpncKeyDomain(data))
+	switch getSettingVpnVpncKeyNatTraversalMode(data) {
+	case nm.NM_VPNC_NATT_MODE_NONE:
+		buf.WriteString("EnableNat=0\n")
+	case nm.NM_VPNC_NATT_MODE_CISCO:
+		buf.WriteString("EnableNat=1\n")
+		fmt.Fprintf(buf, "CiscoUDPEncapsPort=%d\n", getSettingVpnVpncKeyCiscoUdpEncapsPort(data))
+	default:
+		buf.WriteString("EnableNat=1\n")
+	}
+	switch getSettingVpnVpncKeyDhgroup(data) {
+	case nm.NM_VPNC_DHGROUP_DH1:
+		buf.WriteString("DHGroup=1\n")
+	case nm.NM_VPNC_DHGROUP_DH5:
+		buf.WriteString("DHGroup=5\n")
+	default:
+		buf.WriteString("DHGroup=2\n")
+	}
+}
+
+func exportVpnStrongswanProfile(data connectionData, buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "conn %s\n", strings.Replace(getSettingConnectionId(data), " ", "_", -1))
+	buf.WriteString("\tkeyexchange=ikev2\n")
+	fmt.Fprintf(buf, "\tright=%s\n", getSettingVpnStrongswanKeyAddress(data))
+	if v := getSettingVpnStrongswanKeyCertificate(data); len(v) > 0 {
+		fmt.Fprintf(buf, "\trightcert=%s\n", toLocalPath(v))
+	}
+	switch getSettingVpnStrongswanKeyMethod(data) {
+	case nm.NM_STRONGSWAN_METHOD_EAP:
+		buf.WriteString("\tleftauth=eap\n")
+		fmt.Fprintf(buf, "\teap_identity=%s\n", getSettingVpnStrongswanKeyUser(data))
+	case nm.NM_STRONGSWAN_METHOD_PSK:
+		buf.WriteString("\tleftauth=psk\n")
+		fmt.Fprintf(buf, "\tleftid=%s\n", getSettingVpnStrongswanKeyUser(data))
+	default:
+		buf.WriteString("\tleftauth=pubkey\n")
+		if v := getSettingVpnStrongswanKeyUsercert(data); len(v) > 0 {
+			fmt.Fprintf(buf, "\tleftcert=%s\n", toLocalPath(v))
+		}
+	}
+	if getSettingVpnStrongswanKeyVirtual(data) {
+		buf.WriteString("\tleftsourceip=%config\n")
+	}
+	if getSettingVpnStrongswanKeyEncap(data) {
+		buf.WriteString("\tforceencaps=yes\n")
+	}
+	if getSettingVpnStrongswanKeyIpcomp(data) {
+		buf.WriteString("\tcompress=yes\n")
+	}
+	buf.WriteString("\tauto=add\n")
+}
@@ -0,0 +1,572 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"pkg.deepin.io/dde/daemon/network/nm"
+	"pkg.deepin.io/lib/utils"
+	"pkg.deepin.io/lib/xdg/basedir"
+	"strconv"
+	"strings"
+)
+
+// the inline certificates and keys in imported openvpn profiles will
+// be written to files under this directory
+var vpnCertsDir = filepath.Join(basedir.GetUserDataDir(), "deepin", "network", "vpn-certs")
+
+// relative certificate paths in strongswan profiles are relative to
+// this directory
+const vpnStrongswanCertsDir = "/etc/ipsec.d/certs"
+
+// vpnInlineFiles are the contents of the inline certificates and keys
+// by file name, they are written to the directory of the connection
+// under vpnCertsDir when the connection is saved.
+type vpnInlineFiles map[string][]byte
+
+// importVpnConnectionData parse the VPN profile and return the
+// connection data and the inline files it refers to, the profile
+// format is detected by the file extension and its content
+func importVpnConnectionData(file string) (data connectionData, inlineFiles vpnInlineFiles, err error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+
+	uuid := utils.GenUuid()
+	id := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	switch detectVpnProfileType(file, content) {
+	case connectionVpnOpenvpn:
+		data = newVpnOpenvpnConnectionData(id, uuid)
+		inlineFiles, err = importVpnOpenvpnProfile(data, content, filepath.Dir(file))
+	case connectionVpnVpnc:
+		data = newVpnVpncConnectionData(id, uuid)
+		err = importVpnVpncProfile(data, content)
+	case connectionVpnStrongswan:
+		data = newVpnStrongswanConnectionData(id, uuid)
+		err = importVpnStrongswanProfile(data, content)
+	default:
+		err = fmt.Errorf("unsupported vpn profile: %s", file)
+	}
+	return
+}
+
+func detectVpnProfileType(file string, content []byte) (vpnType string) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".ovpn":
+		return connectionVpnOpenvpn
+	case ".pcf":
+		return connectionVpnVpnc
+	}
+
+	// strongswan and openvpn both use ".conf" as the profile suffix,
+	// so check the content instead
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		args := splitVpnProfileLine(scanner.Text())
+		if len(args) == 0 {
+			continue
+		}
+		switch args[0] {
+		case "conn":
+			return connectionVpnStrongswan
+		case "client", "remote", "dev", "proto":
+			return connectionVpnOpenvpn
+		case "[main]":
+			return connectionVpnVpnc
+		}
+	}
+	return
+}
+
+// splitVpnProfileLine split a line into arguments, comments and empty
+// lines will be ignored, and the arguments could be quoted
+func splitVpnProfileLine(line string) (args []string) {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || line[0] == '#' || line[0] == ';' {
+		return
+	}
+	var arg []rune
+	var quote rune
+	inArg := false
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg = append(arg, r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, string(arg))
+				arg = arg[:0]
+				inArg = false
+			}
+		default:
+			arg = append(arg, r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, string(arg))
+	}
+	return
+}
+
+func getVpnInlineFilesDir(uuid string) string {
+	return filepath.Join(vpnCertsDir, uuid)
+}
+
+// writeVpnInlineFiles write the inline files of the connection, nothing
+// is left if failed.
+func writeVpnInlineFiles(uuid string, inlineFiles vpnInlineFiles) (err error) {
+	if len(inlineFiles) == 0 {
+		return
+	}
+	dir := getVpnInlineFilesDir(uuid)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return
+	}
+	for name, content := range inlineFiles {
+		err = ioutil.WriteFile(filepath.Join(dir, name), content, 0600)
+		if err != nil {
+			removeVpnInlineFiles(uuid)
+			return
+		}
+	}
+	return
+}
+
+// removeVpnInlineFiles remove the inline files of the connection if
+// exist.
+func removeVpnInlineFiles(uuid string) {
+	err := os.RemoveAll(getVpnInlineFilesDir(uuid))
+	if err != nil {
+		logger.Warning("remove vpn inline files failed:", err)
+	}
+}
+
+func toVpnProfilePath(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// openvpn inline blocks and the files they will be written to
+var vpnOpenvpnInlineFiles = map[string]string{
+	"ca":       "ca.pem",
+	"cert":     "cert.pem",
+	"key":      "key.pem",
+	"tls-auth": "ta.key",
+	"secret":   "static.key",
+}
+
+func importVpnOpenvpnProfile(data connectionData, content []byte, baseDir string) (inlineFiles vpnInlineFiles, err error) {
+	var (
+		remotes      []string
+		hasCert      bool
+		hasUserPass  bool
+		isStaticKey  bool
+		inlineName   string
+		inlineBuffer bytes.Buffer
+		keyDirection = -1
+	)
+	uuid := getSettingConnectionUuid(data)
+	inlineFiles = make(vpnInlineFiles)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// inline certificates and keys, e.g. <ca>...</ca>, the
+		// unsupported blocks are skipped
+		if len(inlineName) > 0 {
+			if strings.TrimSpace(line) == "</"+inlineName+">" {
+				name := vpnOpenvpnInlineFiles[inlineName]
+				file := filepath.Join(getVpnInlineFilesDir(uuid), name)
+				if len(name) > 0 {
+					inlineFiles[name] = append([]byte(nil), inlineBuffer.Bytes()...)
+				}
+				switch inlineName {
+				case "ca":
+					setSettingVpnOpenvpnKeyCa(data, file)
+				case "cert":
+					setSettingVpnOpenvpnKeyCert(data, file)
+					hasCert = true
+				case "key":
+					setSettingVpnOpenvpnKeyKey(data, file)
+				case "tls-auth":
+					setSettingVpnOpenvpnKeyTa(data, file)
+				case "secret":
+					setSettingVpnOpenvpnKeyStaticKey(data, file)
+					isStaticKey = true
+				}
+				inlineName = ""
+				inlineBuffer.Reset()
+			} else {
+				inlineBuffer.WriteString(line)
+				inlineBuffer.WriteString("\n")
+			}
+			continue
+		}
+		trimedLine := strings.TrimSpace(line)
+		if strings.HasPrefix(trimedLine, "<") && strings.HasSuffix(trimedLine, ">") {
+			name := strings.Trim(trimedLine, "<>")
+			if name == "tls-crypt" {
+				err = fmt.Errorf("openvpn tls-crypt is not supported")
+				return
+			}
+			if _, ok := vpnOpenvpnInlineFiles[name]; !ok {
+				logger.Warning("ignore unsupported openvpn inline block:", name)
+			}
+			inlineName = name
+			continue
+		}
+
+		args := splitVpnProfileLine(line)
+		if len(args) == 0 {
+			continue
+		}
+		switch args[0] {
+		case "remote":
+			if len(args) < 2 {
+				break
+			}
+			if len(args) >= 3 {
+				remotes = append(remotes, args[1]+":"+args[2])
+			} else {
+				remotes = append(remotes, args[1])
+			}
+			if len(args) >= 4 && strings.HasPrefix(args[3], "tcp") {
+				setSettingVpnOpenvpnKeyProtoTcp(data, true)
+			}
+		case "port":
+			if len(args) >= 2 {
+				if port, errParse := strconv.ParseUint(args[1], 10, 16); errParse == nil {
+					setSettingVpnOpenvpnKeyPort(data, uint32(port))
+				}
+			}
+		case "proto":
+			if len(args) >= 2 && strings.HasPrefix(args[1], "tcp") {
+				setSettingVpnOpenvpnKeyProtoTcp(data, true)
+			}
+		case "dev", "dev-type":
+			if len(args) >= 2 && strings.HasPrefix(args[1], "tap") {
+				setSettingVpnOpenvpnKeyTapDev(data, true)
+			}
+		case "ca":
+			if len(args) >= 2 && args[1] != "[inline]" {
+				setSettingVpnOpenvpnKeyCa(data, toVpnProfilePath(baseDir, args[1]))
+			}
+		case "cert":
+			hasCert = true
+			if len(args) >= 2 && args[1] != "[inline]" {
+				setSettingVpnOpenvpnKeyCert(data, toVpnProfilePath(baseDir, args[1]))
+			}
+		case "key":
+			if len(args) >= 2 && args[1] != "[inline]" {
+				setSettingVpnOpenvpnKeyKey(data, toVpnProfilePath(baseDir, args[1]))
+			}
+		case "secret":
+			isStaticKey = true
+			if len(args) >= 2 && args[1] != "[inline]" {
+				setSettingVpnOpenvpnKeyStaticKey(data, toVpnProfilePath(baseDir, args[1]))
+			}
+			if len(args) >= 3 {
+				if dir, errParse := strconv.Atoi(args[2]); errParse == nil {
+					setSettingVpnOpenvpnKeyStaticKeyDirection(data, uint32(dir))
+				}
+			}
+		case "tls-auth":
+			if len(args) >= 2 && args[1] != "[inline]" {
+				setSettingVpnOpenvpnKeyTa(data, toVpnProfilePath(baseDir, args[1]))
+			}
+			if len(args) >= 3 {
+				if dir, errParse := strconv.Atoi(args[2]); errParse == nil {
+					setSettingVpnOpenvpnKeyTaDir(data, uint32(dir))
+				}
+			}
+		case "tls-crypt":
+			err = fmt.Errorf("openvpn tls-crypt is not supported")
+			return
+		case "key-direction":
+			if len(args) >= 2 {
+				keyDirection, _ = strconv.Atoi(args[1])
+			}
+		case "auth-user-pass":
+			hasUserPass = true
+		case "cipher":
+			if len(args) >= 2 {
+				setSettingVpnOpenvpnKeyCipher(data, args[1])
+			}
+		case "auth":
+			if len(args) >= 2 {
+				setSettingVpnOpenvpnKeyAuth(data, args[1])
+			}
+		case "comp-lzo":
+			if len(args) < 2 || args[1] != "no" {
+				setSettingVpnOpenvpnKeyCompLzo(data, true)
+			}
+		case "tun-mtu":
+			if len(args) >= 2 {
+				if mtu, errParse := strconv.ParseUint(args[1], 10, 32); errParse == nil {
+					setSettingVpnOpenvpnKeyTunnelMtu(data, uint32(mtu))
+				}
+			}
+		case "fragment":
+			if len(args) >= 2 {
+				if size, errParse := strconv.ParseUint(args[1], 10, 32); errParse == nil {
+					setSettingVpnOpenvpnKeyFragmentSize(data, uint32(size))
+				}
+			}
+		case "mssfix":
+			setSettingVpnOpenvpnKeyMssfix(data, true)
+		case "remote-random":
+			setSettingVpnOpenvpnKeyRemoteRandom(data, true)
+		case "reneg-sec":
+			if len(args) >= 2 {
+				if sec, errParse := strconv.ParseUint(args[1], 10, 32); errParse == nil {
+					setSettingVpnOpenvpnKeyRenegSeconds(data, uint32(sec))
+				}
+			}
+		case "remote-cert-tls":
+			if len(args) >= 2 {
+				setSettingVpnOpenvpnKeyRemoteCertTls(data, args[1])
+			}
+		case "tls-remote":
+			if len(args) >= 2 {
+				setSettingVpnOpenvpnKeyTlsRemote(data, args[1])
+			}
+		case "ifconfig":
+			if len(args) >= 3 {
+				setSettingVpnOpenvpnKeyLocalIp(data, args[1])
+				setSettingVpnOpenvpnKeyRemoteIp(data, args[2])
+			}
+		case "http-proxy", "socks-proxy":
+			if len(args) < 3 {
+				break
+			}
+			proxyPort, errParse := strconv.ParseUint(args[2], 10, 16)
+			if errParse != nil {
+				break
+			}
+			if args[0] == "http-proxy" {
+				logicSetSettingVpnOpenvpnKeyProxyType(data, "httpect")
+			} else {
+				logicSetSettingVpnOpenvpnKeyProxyType(data, "socksct")
+			}
+			setSettingVpnOpenvpnKeyProxyServer(data, args[1])
+			setSettingVpnOpenvpnKeyProxyPort(data, uint32(proxyPort))
+		case "http-proxy-retry", "socks-proxy-retry":
+			setSettingVpnOpenvpnKeyProxyRetry(data, true)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if len(inlineName) > 0 {
+		err = fmt.Errorf("openvpn inline block <%s> is not closed", inlineName)
+		return
+	}
+	if len(remotes) == 0 {
+		err = fmt.Errorf("no remote gateway found in openvpn profile")
+		return
+	}
+	setSettingVpnOpenvpnKeyRemote(data, strings.Join(remotes, ","))
+
+	// the direction of inline tls-auth and secret is defined by
+	// key-direction
+	if keyDirection >= 0 {
+		if isSettingVpnOpenvpnKeyTaExists(data) && !isSettingVpnOpenvpnKeyTaDirExists(data) {
+			setSettingVpnOpenvpnKeyTaDir(data, uint32(keyDirection))
+		}
+		if isStaticKey && !isSettingVpnOpenvpnKeyStaticKeyDirectionExists(data) {
+			setSettingVpnOpenvpnKeyStaticKeyDirection(data, uint32(keyDirection))
+		}
+	}
+
+	// keep the imported keys, just remove the keys not used by the
+	// connection type
+	var connType string
+	switch {
+	case isStaticKey:
+		connType = nm.NM_OPENVPN_CONTYPE_STATIC_KEY
+	case hasUserPass && hasCert:
+		connType = nm.NM_OPENVPN_CONTYPE_PASSWORD_TLS
+	case hasUserPass:
+		connType = nm.NM_OPENVPN_CONTYPE_PASSWORD
+	default:
+		connType = nm.NM_OPENVPN_CONTYPE_TLS
+	}
+	logicSetSettingVpnOpenvpnKeyConnectionType(data, connType)
+	if hasUserPass {
+		setSettingVpnOpenvpnKeyPasswordFlags(data, nm.NM_OPENVPN_SECRET_FLAG_SAVE)
+	}
+	return
+}
+
+// parse the Cisco VPN client profile(.pcf), which is an ini file with
+// only one [main] group
+func importVpnVpncProfile(data connectionData, content []byte) (err error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == ';' || line[0] == '#' || line[0] == '[' {
+			continue
+		}
+		a := strings.SplitN(line, "=", 2)
+		if len(a) != 2 {
+			continue
+		}
+		// key prefixed with "!" means the value is read-only in
+		// Cisco VPN client, just ignore the mark
+		key := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(a[0]), "!"))
+		values[key] = strings.TrimSpace(a[1])
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+
+	gateway := values["host"]
+	if len(gateway) == 0 {
+		return fmt.Errorf("no host found in pcf profile")
+	}
+	setSettingVpnVpncKeyGateway(data, gateway)
+	if v := values["description"]; len(v) > 0 {
+		setSettingConnectionId(data, v)
+	}
+	if v := values["groupname"]; len(v) > 0 {
+		setSettingVpnVpncKeyId(data, v)
+	}
+	if v := values["grouppwd"]; len(v) > 0 {
+		logicSetSettingVpnVpncKeySecretFlags(data, nm.NM_VPNC_SECRET_FLAG_SAVE)
+		setSettingVpnVpncKeySecret(data, v)
+	} else if len(values["enc_grouppwd"]) > 0 {
+		// the encrypted group password could not be decrypted
+		// here, so ask user for it
+		logicSetSettingVpnVpncKeySecretFlags(data, nm.NM_VPNC_SECRET_FLAG_ASK)
+	}
+	if v := values["username"]; len(v) > 0 {
+		setSettingVpnVpncKeyXauthUser(data, v)
+	}
+	if v := values["userpassword"]; len(v) > 0 {
+		logicSetSettingVpnVpncKeyXauthPasswordFlags(data, nm.NM_VPNC_SECRET_FLAG_SAVE)
+		setSettingVpnVpncKeyXauthPassword(data, v)
+	}
+	if v := values["ntdomain"]; len(v) > 0 {
+		setSettingVpnVpncKeyDomain(data, v)
+	}
+	if v, ok := values["enablenat"]; ok {
+		if v == "1" {
+			setSettingVpnVpncKeyNatTraversalMode(data, nm.NM_VPNC_NATT_MODE_NATT)
+		} else {
+			setSettingVpnVpncKeyNatTraversalMode(data, nm.NM_VPNC_NATT_MODE_NONE)
+		}
+	}
+	switch values["dhgroup"] {
+	case "1":
+		setSettingVpnVpncKeyDhgroup(data, nm.NM_VPNC_DHGROUP_DH1)
+	case "2":
+		setSettingVpnVpncKeyDhgroup(data, nm.NM_VPNC_DHGROUP_DH2)
+	case "5":
+		setSettingVpnVpncKeyDhgroup(data, nm.NM_VPNC_DHGROUP_DH5)
+	}
+	if v := values["ciscoudpencapsport"]; len(v) > 0 {
+		if port, errParse := strconv.ParseUint(v, 10, 16); errParse == nil && port > 0 {
+			setSettingVpnVpncKeyNatTraversalMode(data, nm.NM_VPNC_NATT_MODE_CISCO)
+			setSettingVpnVpncKeyCiscoUdpEncapsPort(data, uint32(port))
+		}
+	}
+	return
+}
+
+// parse the first "conn" section of strongswan ipsec.conf profile
+func importVpnStrongswanProfile(data connectionData, content []byte) (err error) {
+	var connName string
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		args := splitVpnProfileLine(line)
+		if len(args) == 0 {
+			continue
+		}
+		// section header is not indented
+		if line[0] != ' ' && line[0] != '\t' {
+			if len(connName) > 0 {
+				break
+			}
+			if args[0] == "conn" && len(args) >= 2 && args[1] != "%default" {
+				connName = args[1]
+			}
+			continue
+		}
+		if len(connName) == 0 {
+			continue
+		}
+		a := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(a) != 2 {
+			continue
+		}
+		values[strings.TrimSpace(a[0])] = strings.Trim(strings.TrimSpace(a[1]), `"`)
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if len(connName) == 0 {
+		return fmt.Errorf("no conn section found in strongswan profile")
+	}
+
+	address := values["right"]
+	if len(address) == 0 {
+		return fmt.Errorf("no right gateway found in strongswan profile")
+	}
+	setSettingConnectionId(data, connName)
+	setSettingVpnStrongswanKeyAddress(data, address)
+	if v := values["rightcert"]; len(v) > 0 {
+		setSettingVpnStrongswanKeyCertificate(data, toVpnProfilePath(vpnStrongswanCertsDir, v))
+	}
+
+	user := values["eap_identity"]
+	if len(user) == 0 {
+		user = values["leftid"]
+	}
+	leftAuth := values["leftauth"]
+	switch {
+	case strings.HasPrefix(leftAuth, "eap") || strings.HasPrefix(leftAuth, "xauth"):
+		logicSetSettingVpnStrongswanKeyMethod(data, nm.NM_STRONGSWAN_METHOD_EAP)
+		setSettingVpnStrongswanKeyUser(data, user)
+	case leftAuth == "psk" || values["authby"] == "secret" || values["authby"] == "psk":
+		logicSetSettingVpnStrongswanKeyMethod(data, nm.NM_STRONGSWAN_METHOD_PSK)
+		setSettingVpnStrongswanKeyUser(data, user)
+	default:
+		logicSetSettingVpnStrongswanKeyMethod(data, nm.NM_STRONGSWAN_METHOD_KEY)
+		if v := values["leftcert"]; len(v) > 0 {
+			setSettingVpnStrongswanKeyUsercert(data, toVpnProfilePath(vpnStrongswanCertsDir, v))
+		}
+	}
+
+	setSettingVpnStrongswanKeyVirtual(data, values["leftsourceip"] == "%config")
+	setSettingVpnStrongswanKeyEncap(data, values["forceencaps"] == "yes")
+	setSettingVpnStrongswanKeyIpcomp(data, values["compress"] == "yes")
+	return
+}
@@ -0,0 +1,131 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"io/ioutil"
+	C "launchpad.net/gocheck"
+	"os"
+	"path/filepath"
+	"pkg.deepin.io/dde/daemon/network/nm"
+)
+
+func (*testWrapper) TestSplitVpnProfileLine(c *C.C) {
+	c.Check(splitVpnProfileLine("  # comment"), C.IsNil)
+	c.Check(splitVpnProfileLine("; comment"), C.IsNil)
+	c.Check(splitVpnProfileLine("remote vpn.example.com  1194"), C.DeepEquals, []string{"remote", "vpn.example.com", "1194"})
+	c.Check(splitVpnProfileLine(`ca "/path/with space/ca.crt"`), C.DeepEquals, []string{"ca", "/path/with space/ca.crt"})
+}
+
+func (*testWrapper) TestImportExportVpnOpenvpn(c *C.C) {
+	tmpDir, err := ioutil.TempDir("", "vpn-import")
+	c.Assert(err, C.IsNil)
+	defer os.RemoveAll(tmpDir)
+	oldCertsDir := vpnCertsDir
+	vpnCertsDir = tmpDir
+	defer func() { vpnCertsDir = oldCertsDir }()
+
+	data, inlineFiles, err := importVpnConnectionData("testdata/vpn/client.ovpn")
+	c.Assert(err, C.IsNil)
+	c.Check(getCustomConnectionType(data), C.Equals, connectionVpnOpenvpn)
+	c.Check(getSettingConnectionId(data), C.Equals, "client")
+	c.Check(getSettingVpnOpenvpnKeyRemote(data), C.Equals, "vpn1.example.com:1194,vpn2.example.com:443")
+	c.Check(getSettingVpnOpenvpnKeyConnectionType(data), C.Equals, nm.NM_OPENVPN_CONTYPE_PASSWORD_TLS)
+	c.Check(getSettingVpnOpenvpnKeyProtoTcp(data), C.Equals, true)
+	c.Check(getSettingVpnOpenvpnKeyRemoteRandom(data), C.Equals, true)
+	c.Check(getSettingVpnOpenvpnKeyCompLzo(data), C.Equals, true)
+	c.Check(getSettingVpnOpenvpnKeyCipher(data), C.Equals, "AES-256-CBC")
+	c.Check(getSettingVpnOpenvpnKeyTaDir(data), C.Equals, uint32(1))
+
+	uuid := getSettingConnectionUuid(data)
+	c.Check(getSettingVpnOpenvpnKeyCa(data), C.Equals, filepath.Join(tmpDir, uuid, "ca.pem"))
+	// the inline files are written only when saved
+	_, err = os.Stat(filepath.Join(tmpDir, uuid))
+	c.Check(os.IsNotExist(err), C.Equals, true)
+	c.Assert(writeVpnInlineFiles(uuid, inlineFiles), C.IsNil)
+	ca, err := ioutil.ReadFile(getSettingVpnOpenvpnKeyCa(data))
+	c.Assert(err, C.IsNil)
+	c.Check(string(ca), C.Equals, "-----BEGIN CERTIFICATE-----\nMIIBtest\n-----END CERTIFICATE-----\n")
+
+	// export and import again
+	content, err := exportVpnConnectionData(data)
+	c.Assert(err, C.IsNil)
+	exportFile := filepath.Join(tmpDir, "exported.ovpn")
+	c.Assert(ioutil.WriteFile(exportFile, content, 0600), C.IsNil)
+	data2, inlineFiles2, err := importVpnConnectionData(exportFile)
+	c.Assert(err, C.IsNil)
+	c.Assert(writeVpnInlineFiles(getSettingConnectionUuid(data2), inlineFiles2), C.IsNil)
+	c.Check(getSettingVpnOpenvpnKeyRemote(data2), C.Equals, getSettingVpnOpenvpnKeyRemote(data))
+	c.Check(getSettingVpnOpenvpnKeyConnectionType(data2), C.Equals, nm.NM_OPENVPN_CONTYPE_PASSWORD_TLS)
+	c.Check(getSettingVpnOpenvpnKeyTaDir(data2), C.Equals, uint32(1))
+	ca2, err := ioutil.ReadFile(getSettingVpnOpenvpnKeyCa(data2))
+	c.Assert(err, C.IsNil)
+	c.Check(string(ca2), C.Equals, string(ca))
+
+	removeVpnInlineFiles(uuid)
+	_, err = os.Stat(filepath.Join(tmpDir, uuid))
+	c.Check(os.IsNotExist(err), C.Equals, true)
+}
+
+func (*testWrapper) TestImportVpnOpenvpnInlineBlocks(c *C.C) {
+	profile := `client
+remote vpn.example.com 1194
+<connection>
+remote ignored.example.com 443
+<ca>
+</ca>
+</connection>
+<ca>
+CA
+</ca>
+`
+	data := newVpnOpenvpnConnectionData("test", "test-uuid")
+	inlineFiles, err := importVpnOpenvpnProfile(data, []byte(profile), "/tmp")
+	c.Assert(err, C.IsNil)
+	c.Check(getSettingVpnOpenvpnKeyRemote(data), C.Equals, "vpn.example.com:1194")
+	c.Check(inlineFiles, C.DeepEquals, vpnInlineFiles{"ca.pem": []byte("CA\n")})
+
+	profile = `client
+remote vpn.example.com 1194
+<tls-crypt>
+KEY
+</tls-crypt>
+`
+	data = newVpnOpenvpnConnectionData("test", "test-uuid")
+	_, err = importVpnOpenvpnProfile(data, []byte(profile), "/tmp")
+	c.Check(err, C.NotNil)
+}
+
+func (*testWrapper) TestImportVpnVpnc(c *C.C) {
+	data, _, err := importVpnConnectionData("testdata/vpn/office.pcf")
+	c.Assert(err, C.IsNil)
+	c.Check(getCustomConnectionType(data), C.Equals, connectionVpnVpnc)
+	c.Check(getSettingConnectionId(data), C.Equals, "Office VPN")
+	c.Check(getSettingVpnVpncKeyGateway(data), C.Equals, "vpn.example.com")
+	c.Check(getSettingVpnVpncKeyId(data), C.Equals, "staff")
+	c.Check(getSettingVpnVpncKeySecret(data), C.Equals, "secret")
+	c.Check(getSettingVpnVpncKeyXauthUser(data), C.Equals, "alice")
+	c.Check(getSettingVpnVpncKeyDomain(data), C.Equals, "EXAMPLE")
+	c.Check(getSettingVpnVpncKeyDhgroup(data), C.Equals, nm.NM_VPNC_DHGROUP_DH5)
+}
+
+func (*testWrapper) TestImportVpnStrongswan(c *C.C) {
+	data, _, err := importVpnConnectionData("testdata/vpn/ipsec.conf")
+	c.Assert(err, C.IsNil)
+	c.Check(getCustomConnectionType(data), C.Equals, connectionVpnStrongswan)
+	c.Check(getSettingConnectionId(data), C.Equals, "office")
+	c.Check(getSettingVpnStrongswanKeyAddress(data), C.Equals, "gw.example.com")
+	c.Check(getSettingVpnStrongswanKeyCertificate(data), C.Equals, "/etc/ipsec.d/certs/gw.pem")
+	c.Check(getSettingVpnStrongswanKeyMethod(data), C.Equals, nm.NM_STRONGSWAN_METHOD_EAP)
+	c.Check(getSettingVpnStrongswanKeyUser(data), C.Equals, "alice")
+	c.Check(getSettingVpnStrongswanKeyVirtual(data), C.Equals, true)
+	c.Check(getSettingVpnStrongswanKeyEncap(data), C.Equals, true)
+	c.Check(getSettingVpnStrongswanKeyIpcomp(data), C.Equals, false)
+}
@@ -0,0 +1,390 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	"fmt"
+	"pkg.deepin.io/dde/daemon/network/nm"
+	"pkg.deepin.io/lib/utils"
+	"time"
+)
+
+// conditions of vpn rule
+const (
+	// any wireless network whose ssid is not trusted
+	vpnRuleConditionUntrustedWireless = "untrusted-wireless"
+	// wireless network with one of the ssids
+	vpnRuleConditionSsid = "ssid"
+	// wired network with 802.1X authentication
+	vpnRuleConditionWired8021x = "wired-8021x"
+)
+
+// actions of vpn rule
+const (
+	vpnRuleActionActivate   = "activate"
+	vpnRuleActionDeactivate = "deactivate"
+)
+
+// vpnRule activate or deactivate vpn connection automatically
+// according to current network location, the rules are evaluated in
+// order and only the first matched one takes effect.
+type vpnRule struct {
+	Id        string
+	Name      string
+	Enabled   bool
+	Condition string
+	Ssids     []string // for condition "ssid"
+	Action    string
+	// the vpn connection to activate or deactivate, if empty for
+	// action "deactivate", all vpn connections will be deactivated
+	VpnUuid string
+
+	// don't need to save matched state, so the variable name is
+	// lowercase
+	matched bool
+}
+
+// vpnRuleInfo is the rule with its state which exported through
+// property "VpnRules".
+type vpnRuleInfo struct {
+	vpnRule
+	Matched bool
+}
+
+// vpnRuleDecision is the action taken by vpn rules which exported
+// through property "LastVpnRuleDecision".
+type vpnRuleDecision struct {
+	RuleId   string
+	RuleName string
+	Action   string
+	VpnUuid  string
+	// the active connection makes the rule matched
+	ConnectionUuid string
+	ConnectionId   string
+	Time           int64 // unix time when the action taken
+	Error          string
+}
+
+// networkLocation describe an active non-vpn connection.
+type networkLocation struct {
+	Uuid      string
+	Id        string
+	Type      string
+	Ssid      string
+	Ieee8021x bool
+}
+
+func checkVpnRule(r *vpnRule) (err error) {
+	if len(r.Name) == 0 {
+		err = fmt.Errorf("vpn rule name is empty")
+		return
+	}
+	switch r.Condition {
+	case vpnRuleConditionUntrustedWireless, vpnRuleConditionWired8021x:
+	case vpnRuleConditionSsid:
+		if len(r.Ssids) == 0 {
+			err = fmt.Errorf("ssids of vpn rule %s is empty", r.Name)
+			return
+		}
+	default:
+		err = fmt.Errorf("invalid condition of vpn rule %s: %s", r.Name, r.Condition)
+		return
+	}
+	switch r.Action {
+	case vpnRuleActionActivate:
+		if len(r.VpnUuid) == 0 {
+			err = fmt.Errorf("vpn connection of rule %s is not specified", r.Name)
+			return
+		}
+	case vpnRuleActionDeactivate:
+	default:
+		err = fmt.Errorf("invalid action of vpn rule %s: %s", r.Name, r.Action)
+	}
+	return
+}
+
+func isVpnRuleMatched(r *vpnRule, trustedSsids []string, loc *networkLocation) bool {
+	switch r.Condition {
+	case vpnRuleConditionUntrustedWireless:
+		return loc.Type == nm.NM_SETTING_WIRELESS_SETTING_NAME && !isStringInArray(loc.Ssid, trustedSsids)
+	case vpnRuleConditionSsid:
+		return loc.Type == nm.NM_SETTING_WIRELESS_SETTING_NAME && isStringInArray(loc.Ssid, r.Ssids)
+	case vpnRuleConditionWired8021x:
+		return loc.Type == nm.NM_SETTING_WIRED_SETTING_NAME && loc.Ieee8021x
+	}
+	return false
+}
+
+// matchVpnRules update the matched state of each rule, and return the
+// first enabled rule that matches with the location makes it matched.
+func matchVpnRules(rules []*vpnRule, trustedSsids []string, locations []*networkLocation) (rule *vpnRule, location *networkLocation) {
+	for _, r := range rules {
+		r.matched = false
+		if !r.Enabled {
+			continue
+		}
+		for _, loc := range locations {
+			if isVpnRuleMatched(r, trustedSsids, loc) {
+				r.matched = true
+				if rule == nil {
+					rule, location = r, loc
+				}
+				break
+			}
+		}
+	}
+	return
+}
+
+func findVpnRule(rules []*vpnRule, id string) int {
+	for i, r := range rules {
+		if r.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// getNetworkLocations collect all activated non-vpn connections.
+func (m *Manager) getNetworkLocations() (locations []*networkLocation) {
+	m.activeConnectionsLock.Lock()
+	var aconns []*activeConnection
+	for _, aconn := range m.activeConnections {
+		if !aconn.Vpn && isConnectionStateActivated(aconn.State) {
+			aconns = append(aconns, aconn)
+		}
+	}
+	m.activeConnectionsLock.Unlock()
+
+	for _, aconn := range aconns {
+		loc := &networkLocation{Uuid: aconn.Uuid, Id: aconn.Id, Type: aconn.typ}
+		if cpath, err := nmGetConnectionByUuid(aconn.Uuid); err == nil {
+			if data, err := nmGetConnectionData(cpath); err == nil {
+				switch loc.Type {
+				case nm.NM_SETTING_WIRELESS_SETTING_NAME:
+					loc.Ssid = string(getSettingWirelessSsid(data))
+				case nm.NM_SETTING_WIRED_SETTING_NAME:
+					loc.Ieee8021x = isSettingExists(data, nm.NM_SETTING_802_1X_SETTING_NAME)
+				}
+			}
+		}
+		locations = append(locations, loc)
+	}
+	return
+}
+
+// startVpnRulesWorker start the goroutine evaluating vpn rules, so the
+// evaluations never run concurrently.
+func (m *Manager) startVpnRulesWorker() {
+	m.stopVpnRulesWorker()
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	m.vpnRulesWorkerQuit = quit
+	m.vpnRulesWorkerDone = done
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-m.vpnRulesUpdate:
+				m.updateVpnRules()
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
+
+// stopVpnRulesWorker stop the worker and wait for the running
+// evaluation to finish.
+func (m *Manager) stopVpnRulesWorker() {
+	if m.vpnRulesWorkerQuit == nil {
+		return
+	}
+	close(m.vpnRulesWorkerQuit)
+	<-m.vpnRulesWorkerDone
+	m.vpnRulesWorkerQuit = nil
+	m.vpnRulesWorkerDone = nil
+}
+
+// requestUpdateVpnRules ask the worker to evaluate vpn rules, the
+// requests will be merged if an evaluation is pending.
+func (m *Manager) requestUpdateVpnRules() {
+	select {
+	case m.vpnRulesUpdate <- struct{}{}:
+	default:
+	}
+}
+
+// updateVpnRules evaluate vpn rules, the action will be taken only
+// when the matched rule changed, so a vpn connection toggled by user
+// manually will be kept until the network location changed. It is only
+// called by the worker, use requestUpdateVpnRules() instead.
+func (m *Manager) updateVpnRules() {
+	locations := m.getNetworkLocations()
+
+	m.vpnRulesLock.Lock()
+	defer m.vpnRulesLock.Unlock()
+	rule, loc := matchVpnRules(m.config.VpnRules, m.config.TrustedSsids, locations)
+	m.setPropVpnRules()
+
+	var ruleId string
+	if rule != nil {
+		ruleId = rule.Id
+	}
+	if ruleId == m.vpnRuleMatchedId {
+		return
+	}
+	m.vpnRuleMatchedId = ruleId
+	if rule == nil {
+		return
+	}
+
+	logger.Infof("vpn rule %s matched by connection %s, %s vpn %s", rule.Name, loc.Id, rule.Action, rule.VpnUuid)
+	decision := &vpnRuleDecision{
+		RuleId:         rule.Id,
+		RuleName:       rule.Name,
+		Action:         rule.Action,
+		VpnUuid:        rule.VpnUuid,
+		ConnectionUuid: loc.Uuid,
+		ConnectionId:   loc.Id,
+		Time:           time.Now().Unix(),
+	}
+	if err := m.doVpnRuleAction(rule); err != nil {
+		decision.Error = err.Error()
+	}
+	m.setPropLastVpnRuleDecision(decision)
+}
+
+func (m *Manager) doVpnRuleAction(rule *vpnRule) (err error) {
+	switch rule.Action {
+	case vpnRuleActionActivate:
+		if _, err = nmGetConnectionByUuid(rule.VpnUuid); err != nil {
+			return
+		}
+		m.switchHandler.activateVpnConnection(rule.VpnUuid)
+	case vpnRuleActionDeactivate:
+		if len(rule.VpnUuid) > 0 {
+			err = m.DeactivateConnection(rule.VpnUuid)
+			return
+		}
+		for _, apath := range nmGetVpnActiveConnections() {
+			if tmpErr := nmDeactivateConnection(apath); tmpErr != nil {
+				err = tmpErr
+			}
+		}
+	}
+	return
+}
+
+// resetVpnRuleMatched make the matched rule to take action again in
+// next evaluation if it is the target rule.
+func (m *Manager) resetVpnRuleMatched(id string) {
+	if m.vpnRuleMatchedId == id {
+		m.vpnRuleMatchedId = ""
+	}
+}
+
+// AddVpnRule add a new vpn rule to the end of rules and return its
+// id, the field "Id" in ruleJSON will be ignored.
+func (m *Manager) AddVpnRule(ruleJSON string) (id string, err error) {
+	r := &vpnRule{}
+	if err = unmarshalJSON(ruleJSON, r); err != nil {
+		return
+	}
+	if err = checkVpnRule(r); err != nil {
+		return
+	}
+	r.Id = utils.GenUuid()
+
+	m.vpnRulesLock.Lock()
+	m.config.mu.Lock()
+	m.config.VpnRules = append(m.config.VpnRules, r)
+	m.config.saveLocked()
+	m.config.mu.Unlock()
+	m.vpnRulesLock.Unlock()
+
+	id = r.Id
+	m.requestUpdateVpnRules()
+	return
+}
+
+// UpdateVpnRule replace the vpn rule which has the same id with
+// ruleJSON.
+func (m *Manager) UpdateVpnRule(ruleJSON string) (err error) {
+	r := &vpnRule{}
+	if err = unmarshalJSON(ruleJSON, r); err != nil {
+		return
+	}
+	if err = checkVpnRule(r); err != nil {
+		return
+	}
+
+	m.vpnRulesLock.Lock()
+	i := findVpnRule(m.config.VpnRules, r.Id)
+	if i < 0 {
+		m.vpnRulesLock.Unlock()
+		err = fmt.Errorf("vpn rule %s not exists", r.Id)
+		return
+	}
+	m.config.mu.Lock()
+	m.config.VpnRules[i] = r
+	m.config.saveLocked()
+	m.config.mu.Unlock()
+	m.resetVpnRuleMatched(r.Id)
+	m.vpnRulesLock.Unlock()
+
+	m.requestUpdateVpnRules()
+	return
+}
+
+// RemoveVpnRule remove the vpn rule, the vpn connections will be kept
+// as they are.
+func (m *Manager) RemoveVpnRule(id string) (err error) {
+	m.vpnRulesLock.Lock()
+	i := findVpnRule(m.config.VpnRules, id)
+	if i < 0 {
+		m.vpnRulesLock.Unlock()
+		err = fmt.Errorf("vpn rule %s not exists", id)
+		return
+	}
+	m.config.mu.Lock()
+	m.config.VpnRules = append(m.config.VpnRules[:i], m.config.VpnRules[i+1:]...)
+	m.config.saveLocked()
+	m.config.mu.Unlock()
+	m.resetVpnRuleMatched(id)
+	m.vpnRulesLock.Unlock()
+
+	m.requestUpdateVpnRules()
+	return
+}
+
+// GetTrustedSsids get the ssids of trusted wireless networks, other
+// wireless networks will match the condition "untrusted-wireless".
+func (m *Manager) GetTrustedSsids() (ssids []string, err error) {
+	m.vpnRulesLock.Lock()
+	defer m.vpnRulesLock.Unlock()
+	ssids = make([]string, len(m.config.TrustedSsids))
+	copy(ssids, m.config.TrustedSsids)
+	return
+}
+
+// SetTrustedSsids set the ssids of trusted wireless networks.
+func (m *Manager) SetTrustedSsids(ssids []string) (err error) {
+	m.vpnRulesLock.Lock()
+	m.config.mu.Lock()
+	m.config.TrustedSsids = make([]string, len(ssids))
+	copy(m.config.TrustedSsids, ssids)
+	m.config.saveLocked()
+	m.config.mu.Unlock()
+	m.vpnRulesLock.Unlock()
+
+	m.requestUpdateVpnRules()
+	return
+}
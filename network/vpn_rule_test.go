@@ -0,0 +1,88 @@
+/**
+ * Copyright (C) 2017 Deepin Technology Co., Ltd.
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ **/
+
+package network
+
+import (
+	C "launchpad.net/gocheck"
+	"pkg.deepin.io/dde/daemon/network/nm"
+)
+
+func (*testWrapper) TestCheckVpnRule(c *C.C) {
+	c.Check(checkVpnRule(&vpnRule{Name: "untrusted", Condition: vpnRuleConditionUntrustedWireless,
+		Action: vpnRuleActionActivate, VpnUuid: "uuid-vpn"}), C.IsNil)
+	c.Check(checkVpnRule(&vpnRule{Name: "corp", Condition: vpnRuleConditionSsid, Ssids: []string{"corp"},
+		Action: vpnRuleActionDeactivate}), C.IsNil)
+	c.Check(checkVpnRule(&vpnRule{Name: "wired", Condition: vpnRuleConditionWired8021x,
+		Action: vpnRuleActionDeactivate}), C.IsNil)
+
+	c.Check(checkVpnRule(&vpnRule{Condition: vpnRuleConditionWired8021x, Action: vpnRuleActionDeactivate}), C.NotNil)
+	c.Check(checkVpnRule(&vpnRule{Name: "corp", Condition: vpnRuleConditionSsid, Action: vpnRuleActionDeactivate}), C.NotNil)
+	c.Check(checkVpnRule(&vpnRule{Name: "invalid", Condition: "invalid", Action: vpnRuleActionDeactivate}), C.NotNil)
+	c.Check(checkVpnRule(&vpnRule{Name: "invalid", Condition: vpnRuleConditionWired8021x, Action: "invalid"}), C.NotNil)
+	c.Check(checkVpnRule(&vpnRule{Name: "untrusted", Condition: vpnRuleConditionUntrustedWireless,
+		Action: vpnRuleActionActivate}), C.NotNil)
+}
+
+func (*testWrapper) TestMatchVpnRules(c *C.C) {
+	corp := &vpnRule{Id: "corp", Enabled: true, Condition: vpnRuleConditionSsid, Ssids: []string{"corp"},
+		Action: vpnRuleActionDeactivate}
+	wired := &vpnRule{Id: "wired", Enabled: true, Condition: vpnRuleConditionWired8021x,
+		Action: vpnRuleActionDeactivate}
+	untrusted := &vpnRule{Id: "untrusted", Enabled: true, Condition: vpnRuleConditionUntrustedWireless,
+		Action: vpnRuleActionActivate, VpnUuid: "uuid-vpn"}
+	rules := []*vpnRule{corp, wired, untrusted}
+	trustedSsids := []string{"corp", "home"}
+
+	wifiCorp := &networkLocation{Uuid: "uuid-corp", Type: nm.NM_SETTING_WIRELESS_SETTING_NAME, Ssid: "corp"}
+	wifiHome := &networkLocation{Uuid: "uuid-home", Type: nm.NM_SETTING_WIRELESS_SETTING_NAME, Ssid: "home"}
+	wifiCafe := &networkLocation{Uuid: "uuid-cafe", Type: nm.NM_SETTING_WIRELESS_SETTING_NAME, Ssid: "cafe"}
+	wiredPlain := &networkLocation{Uuid: "uuid-wired", Type: nm.NM_SETTING_WIRED_SETTING_NAME}
+	wired8021x := &networkLocation{Uuid: "uuid-wired-8021x", Type: nm.NM_SETTING_WIRED_SETTING_NAME, Ieee8021x: true}
+
+	rule, loc := matchVpnRules(rules, trustedSsids, []*networkLocation{wifiCafe})
+	c.Check(rule, C.Equals, untrusted)
+	c.Check(loc, C.Equals, wifiCafe)
+	c.Check(corp.matched, C.Equals, false)
+	c.Check(untrusted.matched, C.Equals, true)
+
+	rule, loc = matchVpnRules(rules, trustedSsids, []*networkLocation{wifiCorp})
+	c.Check(rule, C.Equals, corp)
+	c.Check(loc, C.Equals, wifiCorp)
+	c.Check(untrusted.matched, C.Equals, false)
+
+	// the rules are evaluated in order
+	rule, loc = matchVpnRules(rules, trustedSsids, []*networkLocation{wifiCafe, wired8021x})
+	c.Check(rule, C.Equals, wired)
+	c.Check(loc, C.Equals, wired8021x)
+	c.Check(wired.matched, C.Equals, true)
+	c.Check(untrusted.matched, C.Equals, true)
+
+	rule, _ = matchVpnRules(rules, trustedSsids, []*networkLocation{wifiHome, wiredPlain})
+	c.Check(rule, C.IsNil)
+	rule, _ = matchVpnRules(rules, trustedSsids, nil)
+	c.Check(rule, C.IsNil)
+
+	// disabled rule never matches
+	wired.Enabled = false
+	rule, _ = matchVpnRules(rules, trustedSsids, []*networkLocation{wifiCafe, wired8021x})
+	c.Check(rule, C.Equals, untrusted)
+	c.Check(wired.matched, C.Equals, false)
+}
+
+func (*testWrapper) TestGetTrustedSsids(c *C.C) {
+	m := &Manager{config: &config{TrustedSsids: []string{"Home", "Office"}}}
+	ssids, err := m.GetTrustedSsids()
+	c.Check(err, C.IsNil)
+	c.Check(ssids, C.DeepEquals, []string{"Home", "Office"})
+
+	// the config is not changed through the returned slice
+	ssids[0] = "Cafe"
+	c.Check(m.config.TrustedSsids, C.DeepEquals, []string{"Home", "Office"})
+}